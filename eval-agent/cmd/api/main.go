@@ -38,13 +38,24 @@ func main() {
 		logger.Error().Err(err).Msg("Unable to load dependencies")
 		os.Exit(1)
 	}
+	// Judges configuration hot reload
+	deps.Reloader.WatchSignals(ctx)
+	deps.Reloader.WatchFiles(ctx, cfg.ConfigWatchInterval)
+
 	// API
-	handler := api.NewHandler(deps.Executor, deps.JudgeExecutor, &logger)
+	handler := api.NewHandler(deps.Executor, deps.JudgeExecutor, &logger).WithCatalog(deps.Reloader.Judges())
 	container := restful.NewContainer()
 	container.Filter(middleware.Logger)
 	container.Filter(middleware.RecoverPanic)
 	api.RegisterRoutes(container, handler)
 
+	// The admin routes are only served with a token, as the API is public
+	if token := os.Getenv("ADMIN_API_TOKEN"); token != "" {
+		api.RegisterAdminRoutes(container, api.NewAdminHandler(deps.Reloader, &logger), token)
+	} else {
+		logger.Info().Msg("Admin routes disabled, set ADMIN_API_TOKEN to enable them")
+	}
+
 	// CORS
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
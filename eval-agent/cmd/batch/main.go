@@ -8,15 +8,19 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/batch"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/setup"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
 )
 
 func main() {
@@ -34,6 +38,14 @@ func main() {
 	dryRun := flag.Bool("dry-run", false, "Validate input without evaluating")
 	validate := flag.Bool("validate", false, "Validation mode: compute correlation with human annotations")
 	corrThreshold := flag.Float64("correlation-threshold", 0.3, "Kendall's tau threshold for validation")
+	optimize := flag.Bool("optimize", false, "Optimization mode: rank judge prompt variants by agreement with human annotations")
+	judgeName := flag.String("judge", "", "Judge to optimize (defaults to the judge declared in -variants)")
+	variantsFile := flag.String("variants", "", "YAML file with candidate prompt variants for optimization mode")
+	generate := flag.Int("generate", 0, "Number of prompt variants to generate with the LLM from disagreement cases")
+	budget := flag.Float64("budget", 0, "Maximum cost in USD of the run; remaining records are skipped once exceeded (0 = unlimited)")
+	diffBaseline := flag.String("diff", "", "Previous results file (jsonl) to compare this run against")
+	optimizeOutput := flag.String("optimize-output", "", "Prompt file written with the best prompt variant, next to a judges config patch (default: <judge>.optimized.tmpl)")
+	report := flag.String("report", "", "Report file of the diff and optimization modes (default: <output>.diff.json for -diff, optimization-report.json for -optimize)")
 
 	flag.Parse()
 
@@ -56,6 +68,13 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to wire dependencies")
 	}
 
+	// Without a price table every cost is 0 and the budget would never stop the run
+	if *budget > 0 && deps.Pricing == nil {
+		log.Fatal().
+			Str("pricing_file", cfg.PricingPath).
+			Msg("-budget requires a price table, set PRICING_CONFIG_PATH to an existing file")
+	}
+
 	// Open input file
 	var inputFile io.Reader
 	if *input == "-" {
@@ -93,6 +112,20 @@ func main() {
 		return
 	}
 
+	// Optimization mode
+	if *optimize {
+		runOptimizationMode(ctx, records, cfg, deps, optimizationOptions{
+			judgeName:    *judgeName,
+			variantsFile: *variantsFile,
+			generate:     *generate,
+			output:       *optimizeOutput,
+			report:       reportFile(*report, "optimization-report.json"),
+			workers:      *workers,
+			threshold:    *corrThreshold,
+		})
+		return
+	}
+
 	// Open output file
 	var outputFile io.Writer
 	if *output == "" {
@@ -116,7 +149,7 @@ func main() {
 	defer writer.Close()
 
 	// Process with worker pool
-	processor := batch.NewProcessor(deps.Executor, *workers, deps.Logger)
+	processor := batch.NewProcessor(deps.Executor, *workers, deps.Logger).WithBudget(*budget)
 	results := processor.Process(ctx, records)
 
 	// Write results
@@ -139,16 +172,43 @@ func main() {
 		}
 	}
 
+	// Records the pipeline could not evaluate, e.g. for invalid options
+	failures := processor.Failures()
+	for _, failure := range failures {
+		log.Error().
+			Int("line", failure.LineNumber).
+			Str("event_id", failure.EventID).
+			Err(failure.Err).
+			Msg("Failed to evaluate record")
+		errorCount++
+	}
+	if len(failures) > 0 && !*continueOnError {
+		log.Fatal().Int("failed", len(failures)).Msg("Stopping due to evaluation errors")
+	}
+
 	log.Info().
 		Int("success", successCount).
 		Int("errors", errorCount).
+		Float64("cost_usd", processor.Spent()).
 		Dur("duration", time.Since(startTime)).
 		Msg("Processing complete")
 
+	if processor.BudgetExceeded() {
+		log.Error().
+			Float64("budget_usd", *budget).
+			Int("evaluated", len(allResults)).
+			Int("total", len(records)).
+			Msg("Run stopped by budget, results are partial")
+	}
+
 	if *summary != "" {
 		writeSummary(summary, allResults)
 	}
 
+	if *diffBaseline != "" {
+		writeDiff(*diffBaseline, reportFile(*report, diffReportFile(*output)), allResults)
+	}
+
 	log.Info().Msg("Batch processing complete")
 }
 
@@ -199,6 +259,59 @@ func writeSummary(summary *string, results []models.EvaluationResult) {
 	log.Info().Str("file", *summary).Msg("Summary written")
 }
 
+// reportFile returns the -report flag value, or defaultFile when it is not set.
+func reportFile(flagValue string, defaultFile string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return defaultFile
+}
+
+// diffReportFile derives the diff report path from the output file
+// (results.jsonl -> results.diff.json), so that runs written to different
+// outputs do not overwrite each other's report. Runs written to stdout fall
+// back to diff-report.json in the working directory.
+func diffReportFile(output string) string {
+	if output == "" {
+		return "diff-report.json"
+	}
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".diff.json"
+}
+
+func writeDiff(baselineFile string, reportFile string, results []models.EvaluationResult) {
+	f, err := os.Open(baselineFile)
+	if err != nil {
+		log.Fatal().Err(err).Str("file", baselineFile).Msg("Failed to open diff baseline file")
+	}
+	defer f.Close()
+
+	baseline, err := batch.ReadResults(f)
+	if err != nil {
+		log.Fatal().Err(err).Str("file", baselineFile).Msg("Failed to read diff baseline file")
+	}
+
+	report := batch.DiffResults(baseline, results)
+	for _, warning := range report.Warnings {
+		log.Warn().Msg(warning)
+	}
+
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to marshal diff report")
+	}
+
+	if err := os.WriteFile(reportFile, reportJSON, 0644); err != nil {
+		log.Fatal().Err(err).Str("file", reportFile).Msg("Failed to write diff report")
+	}
+
+	log.Info().
+		Int("compared", report.Compared).
+		Int("verdict_changes", len(report.VerdictChanges)).
+		Float64("avg_confidence_delta", report.AvgConfidenceDelta).
+		Str("file", reportFile).
+		Msg("Diff report written")
+}
+
 func dryRunAndExit(records []batch.InputRecord) {
 	errorCount := 0
 	for _, record := range records {
@@ -223,26 +336,7 @@ func runValidationMode(ctx context.Context, records []batch.InputRecord, deps *s
 	log.Info().Msg("Validation mode enabled")
 
 	// Build map of event_id -> human_annotation for O(1) lookup
-	annotationMap := make(map[string]string)
-	missingAnnotations := 0
-
-	for _, record := range records {
-		if record.Request.HumanAnnotation == nil || *record.Request.HumanAnnotation == "" {
-			log.Error().
-				Int("line", record.LineNumber).
-				Str("event_id", record.Request.EventID).
-				Msg("Record missing human_annotation")
-			missingAnnotations++
-		} else {
-			annotationMap[record.Request.EventID] = *record.Request.HumanAnnotation
-		}
-	}
-
-	if missingAnnotations > 0 {
-		log.Fatal().
-			Int("missing", missingAnnotations).
-			Msg("Validation mode requires all records to have 'human_annotation' field")
-	}
+	annotationMap := requireAnnotations(records)
 
 	log.Info().Int("total", len(records)).Msg("Evaluating records with human annotations...")
 
@@ -267,6 +361,14 @@ func runValidationMode(ctx context.Context, records []batch.InputRecord, deps *s
 		})
 	}
 
+	for _, failure := range processor.Failures() {
+		log.Warn().
+			Int("line", failure.LineNumber).
+			Str("event_id", failure.EventID).
+			Err(failure.Err).
+			Msg("Record excluded from validation, it could not be evaluated")
+	}
+
 	log.Info().Msg("Computing Kendall's correlation...")
 
 	// Validate
@@ -297,7 +399,7 @@ func runValidationMode(ctx context.Context, records []batch.InputRecord, deps *s
 			Float64("tau", validationResult.KendallTau).
 			Float64("threshold", threshold).
 			Msg("Validation failed: Kendall's tau below threshold")
-		log.Error().Msg("Review configs/judges.yaml prompts and re-run validation")
+		log.Error().Msg("Review configs/judges.yaml prompts or run -optimize -judge <name> to search for better prompts")
 		os.Exit(1)
 	}
 
@@ -305,6 +407,32 @@ func runValidationMode(ctx context.Context, records []batch.InputRecord, deps *s
 	log.Info().Msg("Safe to evaluate full dataset with these judge prompts")
 }
 
+// requireAnnotations maps event_id -> human_annotation and exits if any record is not annotated
+func requireAnnotations(records []batch.InputRecord) map[string]string {
+	annotationMap := make(map[string]string)
+	missingAnnotations := 0
+
+	for _, record := range records {
+		if record.Request.HumanAnnotation == nil || *record.Request.HumanAnnotation == "" {
+			log.Error().
+				Int("line", record.LineNumber).
+				Str("event_id", record.Request.EventID).
+				Msg("Record missing human_annotation")
+			missingAnnotations++
+		} else {
+			annotationMap[record.Request.EventID] = *record.Request.HumanAnnotation
+		}
+	}
+
+	if missingAnnotations > 0 {
+		log.Fatal().
+			Int("missing", missingAnnotations).
+			Msg("Validation mode requires all records to have 'human_annotation' field")
+	}
+
+	return annotationMap
+}
+
 func printValidationSummary(result *batch.ValidationResult) {
 	status := "PASSED"
 	if !result.Passed {
@@ -321,3 +449,171 @@ func printValidationSummary(result *batch.ValidationResult) {
 		Str("interpretation", result.Interpretation).
 		Msg("Validation complete")
 }
+
+type optimizationOptions struct {
+	judgeName    string
+	variantsFile string
+	generate     int
+	output       string
+	report       string
+	workers      int
+	threshold    float64
+}
+
+func runOptimizationMode(ctx context.Context, records []batch.InputRecord, cfg *setup.Config, deps *setup.Dependencies, opts optimizationOptions) {
+	log.Info().Msg("Optimization mode enabled")
+
+	requireAnnotations(records)
+
+	// Candidate variants listed in YAML
+	var candidates []batch.PromptVariant
+	judgeName := opts.judgeName
+	if opts.variantsFile != "" {
+		variantsConfig, err := config.LoadPromptVariants(opts.variantsFile)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", opts.variantsFile).Msg("Failed to load prompt variants")
+		}
+		if judgeName == "" {
+			judgeName = variantsConfig.Judge
+		}
+		if variantsConfig.Judge != "" && variantsConfig.Judge != judgeName {
+			log.Fatal().
+				Str("judge", judgeName).
+				Str("variants_judge", variantsConfig.Judge).
+				Msg("Prompt variants were written for a different judge")
+		}
+		for _, variant := range variantsConfig.Variants {
+			candidates = append(candidates, batch.PromptVariant{
+				Name:   variant.Name,
+				Prompt: variant.Prompt,
+				Source: batch.VariantSourceConfig,
+			})
+		}
+	}
+
+	if judgeName == "" {
+		log.Fatal().Msg("Optimization mode requires -judge or a variants file declaring 'judge'")
+	}
+	if len(candidates) == 0 && opts.generate <= 0 {
+		log.Fatal().Msg("Optimization mode requires -variants and/or -generate")
+	}
+
+	buildExecutor := func(judgesConfig *config.JudgesConfig) (batch.Executor, error) {
+		return setup.NewExecutor(cfg, deps.JudgePool, judgesConfig, deps.Logger)
+	}
+
+	optimizer, err := batch.NewPromptOptimizer(judgeName, deps.JudgesConfig, buildExecutor, opts.workers, opts.threshold, deps.Logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create prompt optimizer")
+	}
+
+	baseline, err := optimizer.Evaluate(ctx, records, optimizer.Baseline())
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to evaluate baseline prompt")
+	}
+
+	disagreements := batch.Disagreements(records, baseline)
+	log.Info().
+		Float64("agreement_rate", baseline.Validation.AgreementRate).
+		Int("disagreements", len(disagreements)).
+		Msg("Baseline evaluated")
+
+	// Candidate variants generated by the LLM from the disagreement cases
+	if opts.generate > 0 {
+		if len(disagreements) == 0 {
+			log.Info().Msg("Baseline agrees with every annotation, skipping prompt generation")
+		} else {
+			generator := batch.NewVariantGenerator(deps.LLMClient, deps.Logger)
+			generated, err := generator.Generate(ctx, deps.JudgesConfig, judgeName, disagreements, opts.generate)
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to generate prompt variants")
+			}
+			log.Info().Int("generated", len(generated)).Msg("Prompt variants generated")
+			candidates = append(candidates, generated...)
+		}
+	}
+
+	report, err := optimizer.Optimize(ctx, records, baseline, candidates)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Optimization failed")
+	}
+
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to marshal optimization report")
+	}
+	fmt.Println(string(reportJSON))
+
+	if err := os.WriteFile(opts.report, reportJSON, 0644); err != nil {
+		log.Fatal().Err(err).Str("file", opts.report).Msg("Failed to write optimization report")
+	}
+	log.Info().Str("file", opts.report).Msg("Optimization report written")
+
+	for i, score := range report.Ranking {
+		log.Info().
+			Int("rank", i+1).
+			Str("variant", score.Name).
+			Str("source", score.Source).
+			Float64("agreement_rate", score.AgreementRate).
+			Float64("kendall_tau", score.KendallTau).
+			Int("fixed", len(score.Fixed)).
+			Int("broken", len(score.Broken)).
+			Msg("Prompt variant")
+	}
+
+	if !report.Improved {
+		log.Info().Msg("No variant agrees with human annotations better than the current prompt")
+		return
+	}
+
+	// The variant must validate in the configuration it is written for
+	if _, err := deps.JudgesConfig.WithJudgePrompt(judgeName, report.BestPrompt); err != nil {
+		log.Fatal().Err(err).Msg("Failed to apply best prompt variant")
+	}
+
+	promptFile, patchFile, err := writeOptimizedPrompt(judgeName, report.BestPrompt, opts.output)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to write best prompt variant")
+	}
+
+	log.Info().
+		Str("variant", report.Best.Name).
+		Str("prompt_file", promptFile).
+		Str("patch_file", patchFile).
+		Msg("Best prompt variant written. Apply the patch to the judges config and re-run -validate")
+}
+
+// writeOptimizedPrompt writes the prompt to promptFile (default:
+// <judge>.optimized.tmpl) and, next to it, the judges config patch pointing the
+// judge at it. The rest of the judges configuration is left to the user, so that
+// prompt files, partials and relative paths stay as they were written.
+func writeOptimizedPrompt(judgeName string, prompt string, promptFile string) (string, string, error) {
+	if promptFile == "" {
+		promptFile = judgeName + ".optimized.tmpl"
+	}
+	if err := os.WriteFile(promptFile, []byte(prompt), 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write prompt file %s: %w", promptFile, err)
+	}
+
+	// prompt_file is absolute, as the judges config resolves it from its own directory
+	absPromptFile, err := filepath.Abs(promptFile)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve prompt file %s: %w", promptFile, err)
+	}
+	patch := map[string]any{
+		"judges": map[string]any{
+			"evaluators": []map[string]string{{"name": judgeName, "prompt_file": absPromptFile}},
+		},
+	}
+	data, err := yaml.Marshal(patch)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal judges config patch: %w", err)
+	}
+
+	patchFile := strings.TrimSuffix(promptFile, filepath.Ext(promptFile)) + ".patch.yaml"
+	header := fmt.Sprintf("# Set the prompt_file of the %s judge in the judges config (replacing its prompt or prompt_file)\n", judgeName)
+	if err := os.WriteFile(patchFile, append([]byte(header), data...), 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write judges config patch %s: %w", patchFile, err)
+	}
+	return promptFile, patchFile, nil
+}
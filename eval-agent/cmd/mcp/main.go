@@ -3,9 +3,12 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -41,9 +44,27 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Judges configuration hot reload
+	deps.Reloader.WatchSignals(ctx)
+	deps.Reloader.WatchFiles(ctx, cfg.ConfigWatchInterval)
+
 	// Create MCP Server
 	server := createMCPServer(deps)
 
+	// Serve over streamable HTTP for a shared network service, stdio otherwise
+	switch transport := os.Getenv("MCP_TRANSPORT"); transport {
+	case "", "stdio":
+	case "http":
+		if err := runHTTP(ctx, server, &logger); err != nil {
+			logger.Error().Err(err).Msg("Failed to run mcp server")
+			os.Exit(1)
+		}
+		return
+	default:
+		logger.Error().Str("transport", transport).Msg("Unknown MCP_TRANSPORT, expected stdio or http")
+		os.Exit(1)
+	}
+
 	// Run over stdio
 	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil {
 		// EOF / "server is closing" is expected when stdin closes (e.g. echo | ./bin/eval-mcp)
@@ -56,6 +77,59 @@ func main() {
 	}
 }
 
+// runHTTP serves the MCP server over streamable HTTP until ctx is done. Clients
+// authenticate with the bearer tokens of MCP_AUTH_TOKENS and are rate limited
+// per client to MCP_RATE_LIMIT_RPS requests per second.
+func runHTTP(ctx context.Context, server *mcp.Server, logger *zerolog.Logger) error {
+	tokens, err := mcpadapter.ParseTokens(os.Getenv("MCP_AUTH_TOKENS"))
+	if err != nil {
+		return fmt.Errorf("invalid MCP_AUTH_TOKENS: %w", err)
+	}
+
+	requestsPerSecond := 5.0
+	if value := os.Getenv("MCP_RATE_LIMIT_RPS"); value != "" {
+		if requestsPerSecond, err = strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("invalid MCP_RATE_LIMIT_RPS: %w", err)
+		}
+	}
+
+	handler, err := mcpadapter.NewHTTPHandler(server, mcpadapter.HTTPConfig{
+		Tokens:            tokens,
+		RequestsPerSecond: requestsPerSecond,
+	}, logger)
+	if err != nil {
+		return fmt.Errorf("%w (set MCP_AUTH_TOKENS)", err)
+	}
+
+	port := os.Getenv("MCP_HTTP_PORT")
+	if port == "" {
+		port = "18083"
+	}
+	httpServer := &http.Server{
+		Addr:              fmt.Sprintf(":%s", port),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info().
+		Str("address", httpServer.Addr).
+		Str("path", mcpadapter.HTTPPath).
+		Int("clients", len(tokens)).
+		Float64("rate_limit_rps", requestsPerSecond).
+		Msg("Starting Eval Agent MCP server over HTTP")
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
 func createMCPServer(deps *setup.Dependencies) *mcp.Server {
 	server := mcp.NewServer(
 		&mcp.Implementation{
@@ -67,12 +141,41 @@ func createMCPServer(deps *setup.Dependencies) *mcp.Server {
 	// Add Tools
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "evaluate_response",
-		Description: "Evaluate an AI agent response for relevance, faithfulness, coherence, completeness, and instruction-following",
+		Description: "Evaluate an AI agent response with the full pipeline: prechecks and the enabled judges of the active configuration, listed by the " + mcpadapter.JudgesResourceURI + " resource.",
 	}, mcpadapter.NewEvaluateHandler(deps.Executor))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "evaluate_single_judge",
-		Description: "Evaluate with a single judge (relevance, faithfulness, coherence, completeness, or instruction). Faster than full pipeline.",
+		Description: "Evaluate with a single judge, named as in the " + mcpadapter.JudgesResourceURI + " resource. Faster than full pipeline.",
 	}, mcpadapter.NewEvaluateSingleJudgeHandler(deps.JudgeExecutor))
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "evaluate_batch",
+		Description: "Evaluate a list of interactions with the full pipeline. Returns the per-item results in input order and summary stats (verdict counts, average confidence, cost).",
+	}, mcpadapter.NewEvaluateBatchHandler(deps.Executor, deps.Pricing, deps.Logger))
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "validate_annotations",
+		Description: "Evaluate interactions annotated with a human verdict and measure the agreement: Kendall's tau, agreement rate, confusion matrix and the disagreeing items.",
+	}, mcpadapter.NewValidateAnnotationsHandler(deps.Executor, deps.Logger))
+
+	// Add Prompts
+	server.AddPrompt(mcpadapter.DebugJudgePrompt, mcpadapter.NewDebugJudgePromptHandler())
+	server.AddPrompt(mcpadapter.ExplainVerdictPrompt, mcpadapter.NewExplainVerdictPromptHandler())
+
+	// Add Resources
+	server.AddResource(&mcp.Resource{
+		URI:         mcpadapter.JudgesResourceURI,
+		Name:        "judges",
+		Description: "Judges of the active configuration: name, description, enabled state, context requirement, model settings and prompt hash",
+		MIMEType:    "application/json",
+	}, mcpadapter.NewJudgesResourceHandler(deps.Reloader.Judges()))
+
+	server.AddResource(&mcp.Resource{
+		URI:         mcpadapter.ConfigResourceURI,
+		Name:        "config",
+		Description: "Aggregation weights, verdict thresholds, prechecks and early exit settings of the evaluation pipeline",
+		MIMEType:    "application/json",
+	}, mcpadapter.NewConfigResourceHandler(deps.Executor))
 	return server
 }
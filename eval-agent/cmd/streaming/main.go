@@ -5,17 +5,11 @@ import (
 	"errors"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/aggregator"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm/bedrock"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/prechecks"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/setup"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/stream"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/stream/redis"
 	"github.com/rs/zerolog"
@@ -36,14 +30,17 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	// Initialize Bedrock client for embeddings
-	region := os.Getenv("AWS_REGION")
-	modelID := os.Getenv("CLAUDE_MODEL_ID")
-	bedrockClient, err := bedrock.NewClient(ctx, region, modelID)
+	// Wire dependencies
+	cfg := setup.LoadConfig()
+	deps, err := setup.Wire(ctx, cfg, &logger)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create Bedrock client")
+		log.Fatal().Err(err).Msg("Unable to load dependencies")
 	}
 
+	// Judges configuration hot reload
+	deps.Reloader.WatchSignals(ctx)
+	deps.Reloader.WatchFiles(ctx, cfg.ConfigWatchInterval)
+
 	// Redis client
 	streamCfg := &stream.StreamConfig{
 		Provider: os.Getenv("STREAM_PROVIDER"),
@@ -56,52 +53,7 @@ func main() {
 		),
 	}
 
-	// Aggregator weights
-	precheckWeight, err := strconv.ParseFloat(os.Getenv("PRECHECK_WEIGHT"), 64)
-	if err != nil {
-		precheckWeight = 0.3
-	}
-	llmJudgeWeight, err := strconv.ParseFloat(os.Getenv("LLM_JUDGE_WEIGHT"), 64)
-	if err != nil {
-		llmJudgeWeight = 0.7
-	}
-
-	// Wire Components
-	// Stage 1 — PreChecks
-	stageRunner := prechecks.NewStageRunner([]prechecks.Checker{
-		&prechecks.LengthChecker{},
-		&prechecks.OverlapChecker{MinOverlapThreshold: 0.3},
-		&prechecks.FormatChecker{},
-	})
-
-	// Stage 2 — LLM Judges (from YAML config)
-	judgesConfig, err := config.LoadJudgesConfig()
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to load judges config")
-	}
-
-	judgePool := judge.NewJudgePool(bedrockClient, &logger)
-	judges, err := judgePool.BuildFromConfig(judgesConfig)
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to build judges from config")
-	}
-
-	judgeRunner := judge.NewJudgeRunner(judges, &logger)
-
-	// Aggregator
-	agg := aggregator.NewAggregator(aggregator.Weights{
-		PreChecks: precheckWeight,
-		LLMJudge:  llmJudgeWeight,
-	}, &logger)
-
-	// Executor
-	earlyExit, _ := strconv.ParseFloat(os.Getenv("EARLY_EXIT_THRESHOLD"), 64)
-	if earlyExit == 0 {
-		earlyExit = 0.2
-	}
-	exec := executor.NewExecutor(stageRunner, judgeRunner, agg, earlyExit, &logger)
-
-	consumer, err := stream.NewStreamConsumer(ctx, streamCfg, exec, &logger)
+	consumer, err := stream.NewStreamConsumer(ctx, streamCfg, deps.Executor, &logger)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to create stream consumer")
 	}
@@ -8,11 +8,16 @@ import (
 type Weights struct {
 	PreChecks float64
 	LLMJudge  float64
+
+	// CheckerWeights weights the prechecks by name within the precheck average
+	// (missing checkers count with weight 1)
+	CheckerWeights map[string]float64
 }
 
 type Aggregator struct {
-	Weights Weights
-	logger  *zerolog.Logger
+	Weights          Weights
+	categoryVerdicts map[string]models.Verdict
+	logger           *zerolog.Logger
 }
 
 func NewAggregator(weights Weights, logger *zerolog.Logger) *Aggregator {
@@ -22,34 +27,66 @@ func NewAggregator(weights Weights, logger *zerolog.Logger) *Aggregator {
 	}
 }
 
+// WithCategoryVerdicts caps the verdict of evaluations with a stage of the given
+// category, e.g. a refusal is at most sent to review
+func (a *Aggregator) WithCategoryVerdicts(verdicts map[string]models.Verdict) *Aggregator {
+	a.categoryVerdicts = verdicts
+	return a
+}
+
 func (a *Aggregator) Aggregate(id string, stage1 []models.StageResult, stage2 []models.StageResult) models.EvaluationResult {
+	return a.AggregateWithOptions(id, stage1, stage2, models.EvaluationOptions{})
+}
+
+// AggregateWithOptions aggregates with the weights and verdict thresholds of the
+// options in place of the configured ones. With SkipPrechecks the confidence is
+// the judge average, and with ExpectedRefusal the category verdicts are not applied.
+func (a *Aggregator) AggregateWithOptions(id string, stage1 []models.StageResult, stage2 []models.StageResult, options models.EvaluationOptions) models.EvaluationResult {
 	result := models.EvaluationResult{
 		ID:     id,
 		Stages: append(stage1, stage2...),
 	}
 
-	stage1Score, stage2Score := 0.0, 0.0
-
-	for _, stage := range stage1 {
-		stage1Score += stage.Score
+	if (len(stage1) == 0 && !options.SkipPrechecks) || len(stage2) == 0 {
+		result.Verdict = models.VerdictFail
+		return result
 	}
 
-	for _, stage := range stage2 {
-		stage2Score += stage.Score
+	weights := models.AggregationWeights{PreChecks: a.Weights.PreChecks, LLMJudge: a.Weights.LLMJudge}
+	if options.SkipPrechecks {
+		weights = models.AggregationWeights{PreChecks: 0, LLMJudge: 1}
+	} else if options.Weights != nil {
+		weights = *options.Weights
 	}
-
-	if len(stage1) == 0 || len(stage2) == 0 {
-		result.Verdict = models.VerdictFail
-		return result
+	thresholds := models.DefaultVerdictThresholds
+	if options.Thresholds != nil {
+		thresholds = *options.Thresholds
 	}
 
-	stage1Avg := stage1Score / float64(len(stage1))
-	stage2Avg := stage2Score / float64(len(stage2))
+	stage1Avg := models.AverageScore(stage1, a.Weights.CheckerWeights)
+	stage2Avg := models.AverageScore(stage2, nil)
 
-	confidence := (stage1Avg * a.Weights.PreChecks) + (stage2Avg * a.Weights.LLMJudge)
+	confidence := (stage1Avg * weights.PreChecks) + (stage2Avg * weights.LLMJudge)
 
 	result.Confidence = confidence
-	result.Verdict = a.calculateVerdict(confidence)
+	result.Verdict = calculateVerdict(confidence, thresholds)
+
+	// A refusal is the expected answer to some queries, and is not capped then
+	for _, stage := range result.Stages {
+		if options.ExpectedRefusal {
+			break
+		}
+		if limit, ok := a.categoryVerdicts[stage.Category]; ok && stage.Category != "" && verdictRank(limit) < verdictRank(result.Verdict) {
+			result.Verdict = limit
+			a.logger.Info().Str("stage", stage.Name).Str("category", stage.Category).Msg("verdict capped by category")
+		}
+	}
+
+	// A vetoing stage fails the evaluation whatever the confidence
+	if vetoed := models.Vetoes(result.Stages); len(vetoed) > 0 {
+		result.Verdict = models.VerdictFail
+		a.logger.Info().Strs("vetoedBy", vetoed).Msg("verdict vetoed")
+	}
 
 	a.logger.
 		Info().
@@ -59,12 +96,24 @@ func (a *Aggregator) Aggregate(id string, stage1 []models.StageResult, stage2 []
 	return result
 }
 
-func (a *Aggregator) calculateVerdict(confidence float64) models.Verdict {
-	if confidence > 0.8 {
+func calculateVerdict(confidence float64, thresholds models.VerdictThresholds) models.Verdict {
+	if confidence > thresholds.Pass {
 		return models.VerdictPass
 	}
-	if confidence > 0.5 {
+	if confidence > thresholds.Review {
 		return models.VerdictReview
 	}
 	return models.VerdictFail
 }
+
+// verdictRank orders the verdicts from fail to pass
+func verdictRank(verdict models.Verdict) int {
+	switch verdict {
+	case models.VerdictPass:
+		return 2
+	case models.VerdictReview:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -1,6 +1,7 @@
 package aggregator
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -74,3 +75,118 @@ func TestAggregate_EmptyStages_Fail(t *testing.T) {
 		t.Error("expected Fail for empty stage2")
 	}
 }
+
+func TestAggregate_CheckerWeights(t *testing.T) {
+	weights := Weights{PreChecks: 0.5, LLMJudge: 0.5, CheckerWeights: map[string]float64{"format-checker": 3}}
+	agg := NewAggregator(weights, newTestLogger())
+
+	stage1 := []models.StageResult{
+		{Name: "format-checker", Score: 1.0},
+		{Name: "length-checker", Score: 0.0},
+	}
+	stage2 := []models.StageResult{{Name: "judge", Score: 1.0}}
+
+	result := agg.Aggregate("test", stage1, stage2)
+	// Precheck average (1.0*3 + 0.0*1) / 4 = 0.75; 0.75*0.5 + 1.0*0.5 = 0.875
+	if math.Abs(result.Confidence-0.875) > 1e-9 {
+		t.Errorf("expected confidence 0.875, got %f", result.Confidence)
+	}
+}
+
+func TestAggregate_Veto_Fail(t *testing.T) {
+	weights := Weights{PreChecks: 0.3, LLMJudge: 0.7}
+	agg := NewAggregator(weights, newTestLogger())
+
+	stage1 := []models.StageResult{{Name: "pii-leakage", Score: 0.0, Veto: true}, {Name: "format-checker", Score: 1.0}}
+	stage2 := []models.StageResult{{Name: "judge", Score: 1.0}}
+
+	result := agg.Aggregate("test", stage1, stage2)
+	if result.Verdict != models.VerdictFail {
+		t.Errorf("expected vetoed Fail, got %s (confidence %f)", result.Verdict, result.Confidence)
+	}
+}
+
+func TestAggregate_CategoryVerdicts(t *testing.T) {
+	weights := Weights{PreChecks: 0.3, LLMJudge: 0.7}
+	agg := NewAggregator(weights, newTestLogger()).WithCategoryVerdicts(map[string]models.Verdict{
+		"refusal":    models.VerdictReview,
+		"deflection": models.VerdictFail,
+	})
+	stage2 := []models.StageResult{{Name: "judge", Score: 1.0}}
+
+	tests := []struct {
+		category string
+		want     models.Verdict
+	}{
+		{"", models.VerdictPass},
+		{"refusal", models.VerdictReview},
+		{"deflection", models.VerdictFail},
+		{"hedging", models.VerdictPass},
+	}
+
+	for _, tt := range tests {
+		stage1 := []models.StageResult{{Name: "refusal-checker", Score: 1.0, Category: tt.category}}
+		result := agg.Aggregate("test", stage1, stage2)
+		if result.Verdict != tt.want {
+			t.Errorf("category %q: expected %s, got %s", tt.category, tt.want, result.Verdict)
+		}
+	}
+
+	// A cap never raises the verdict
+	stage1 := []models.StageResult{{Name: "refusal-checker", Score: 0.0, Category: "refusal"}}
+	result := agg.Aggregate("test", stage1, []models.StageResult{{Name: "judge", Score: 0.2}})
+	if result.Verdict != models.VerdictFail {
+		t.Errorf("expected Fail, got %s", result.Verdict)
+	}
+
+	// Queries that should be refused are not capped
+	stage1 = []models.StageResult{{Name: "refusal-checker", Score: 1.0, Category: "refusal"}}
+	result = agg.AggregateWithOptions("test", stage1, stage2, models.EvaluationOptions{ExpectedRefusal: true})
+	if result.Verdict != models.VerdictPass {
+		t.Errorf("expected an expected refusal to pass, got %s", result.Verdict)
+	}
+}
+
+func TestAggregateWithOptions_Overrides(t *testing.T) {
+	weights := Weights{PreChecks: 0.3, LLMJudge: 0.7}
+	agg := NewAggregator(weights, newTestLogger())
+
+	stage1 := []models.StageResult{{Name: "precheck", Score: 0.2}}
+	stage2 := []models.StageResult{{Name: "judge", Score: 0.9}}
+
+	// Configured: 0.2*0.3 + 0.9*0.7 = 0.69 → Review
+	if result := agg.Aggregate("test", stage1, stage2); result.Verdict != models.VerdictReview {
+		t.Fatalf("expected Review, got %s", result.Verdict)
+	}
+
+	// Weights 0.1/0.9: 0.2*0.1 + 0.9*0.9 = 0.83 → Pass
+	result := agg.AggregateWithOptions("test", stage1, stage2, models.EvaluationOptions{
+		Weights: &models.AggregationWeights{PreChecks: 0.1, LLMJudge: 0.9},
+	})
+	if math.Abs(result.Confidence-0.83) > 1e-9 || result.Verdict != models.VerdictPass {
+		t.Errorf("expected Pass with confidence 0.83, got %s (%f)", result.Verdict, result.Confidence)
+	}
+
+	// Thresholds 0.9/0.7: 0.69 → Fail
+	result = agg.AggregateWithOptions("test", stage1, stage2, models.EvaluationOptions{
+		Thresholds: &models.VerdictThresholds{Pass: 0.9, Review: 0.7},
+	})
+	if result.Verdict != models.VerdictFail {
+		t.Errorf("expected Fail, got %s (%f)", result.Verdict, result.Confidence)
+	}
+}
+
+func TestAggregateWithOptions_SkipPrechecks(t *testing.T) {
+	weights := Weights{PreChecks: 0.3, LLMJudge: 0.7}
+	agg := NewAggregator(weights, newTestLogger())
+
+	stage2 := []models.StageResult{{Name: "judge", Score: 0.9}, {Name: "judge2", Score: 0.7}}
+
+	result := agg.AggregateWithOptions("test", nil, stage2, models.EvaluationOptions{SkipPrechecks: true})
+	if math.Abs(result.Confidence-0.8) > 1e-9 || result.Verdict != models.VerdictReview {
+		t.Errorf("expected the judge average 0.8 and Review, got %s (%f)", result.Verdict, result.Confidence)
+	}
+	if len(result.Stages) != 2 {
+		t.Errorf("expected 2 stages, got %d", len(result.Stages))
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+	"github.com/rs/zerolog"
+)
+
+// ConfigReloader reloads the judges configuration of the running service
+type ConfigReloader interface {
+	Reload() (judge.ConfigVersion, error)
+	Active() judge.ConfigVersion
+}
+
+type AdminHandler struct {
+	reloader ConfigReloader
+	logger   *zerolog.Logger
+}
+
+func NewAdminHandler(reloader ConfigReloader, logger *zerolog.Logger) *AdminHandler {
+	return &AdminHandler{
+		reloader: reloader,
+		logger:   logger,
+	}
+}
+
+// POST /api/v1/admin/reload
+// Returns: ReloadResponse. A rejected configuration returns 422 with the
+// configuration that stays active.
+func (h *AdminHandler) Reload(req *restful.Request, resp *restful.Response) {
+	previous := h.reloader.Active()
+
+	active, err := h.reloader.Reload()
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Judges configuration reload rejected")
+		resp.WriteHeaderAndEntity(http.StatusUnprocessableEntity, ReloadResponse{
+			Active:   active,
+			Reloaded: false,
+			Error:    err.Error(),
+		})
+		return
+	}
+
+	resp.WriteHeaderAndEntity(http.StatusOK, ReloadResponse{
+		Active:   active,
+		Reloaded: active.Hash != previous.Hash,
+	})
+}
+
+// GET /api/v1/admin/config/version
+func (h *AdminHandler) ConfigVersion(req *restful.Request, resp *restful.Response) {
+	resp.WriteHeaderAndEntity(http.StatusOK, h.reloader.Active())
+}
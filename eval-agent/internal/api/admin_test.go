@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+	"github.com/rs/zerolog"
+)
+
+type reloaderStub struct {
+	reloads int
+}
+
+func (r *reloaderStub) Reload() (judge.ConfigVersion, error) {
+	r.reloads++
+	return judge.ConfigVersion{Hash: "abc123"}, nil
+}
+
+func (r *reloaderStub) Active() judge.ConfigVersion {
+	return judge.ConfigVersion{Hash: "abc123"}
+}
+
+func TestAdminRoutes_RequireToken(t *testing.T) {
+	logger := zerolog.Nop()
+	reloader := &reloaderStub{}
+	container := restful.NewContainer()
+	RegisterAdminRoutes(container, NewAdminHandler(reloader, &logger), "s3cret")
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer guess", http.StatusUnauthorized},
+		{"not a bearer token", "s3cret", http.StatusUnauthorized},
+		{"token", "Bearer s3cret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/reload", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			container.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, recorder.Code)
+			}
+		})
+	}
+
+	if reloader.reloads != 1 {
+		t.Errorf("Expected only the authenticated request to reload, got %d reloads", reloader.reloads)
+	}
+}
+
+func TestAdminRoutes_EmptyTokenRejectsAll(t *testing.T) {
+	logger := zerolog.Nop()
+	container := restful.NewContainer()
+	RegisterAdminRoutes(container, NewAdminHandler(&reloaderStub{}, &logger), "")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/config/version", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", recorder.Code)
+	}
+}
@@ -1,6 +1,14 @@
 package api
 
+import "github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+
 type HealthResponse struct {
 	Status  string `json:"status" description:"Service status"`
 	Version string `json:"version" description:"API version"`
 }
+
+type ReloadResponse struct {
+	Active   judge.ConfigVersion `json:"active" description:"Configuration serving evaluations after the reload"`
+	Reloaded bool                `json:"reloaded" description:"Whether a new configuration was activated"`
+	Error    string              `json:"error,omitempty" description:"Reason the new configuration was rejected"`
+}
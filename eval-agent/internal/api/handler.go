@@ -2,20 +2,29 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/emicklei/go-restful/v3"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/api/middleware"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 )
 
+// JudgeCatalog describes the judges of the active configuration
+type JudgeCatalog interface {
+	Catalog() judge.Catalog
+}
+
 type Handler struct {
 	executor      *executor.Executor
 	judgeExecutor *executor.JudgeExecutor
+	catalog       JudgeCatalog
 	logger        *zerolog.Logger
 }
 
@@ -27,6 +36,12 @@ func NewHandler(executor *executor.Executor, judgeExecutor *executor.JudgeExecut
 	}
 }
 
+// WithCatalog sets the judge catalog served by ListJudges
+func (h *Handler) WithCatalog(catalog JudgeCatalog) *Handler {
+	h.catalog = catalog
+	return h
+}
+
 // POST /api/v1/evaluate
 // Body: EvaluateRequest
 // Returns: EvaluationResult
@@ -57,7 +72,22 @@ func (h *Handler) Evaluate(req *restful.Request, resp *restful.Response) {
 	ctx := req.Request.Context()
 	evaluationContext := normalize(evalRequest)
 
-	evalResult := h.executor.Execute(ctx, evaluationContext)
+	evalResult, err := h.executor.ExecuteWithOptions(ctx, evaluationContext, evalRequest.Options)
+	if err != nil {
+		if errors.Is(err, executor.ErrInvalidOptions) {
+			h.logger.Warn().Err(err).Msg("Request options rejected")
+			resp.WriteHeaderAndEntity(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Evaluation failed")
+		resp.WriteHeaderAndEntity(http.StatusInternalServerError, map[string]string{
+			"error": "internal server error",
+		})
+		return
+	}
 
 	h.logger.Info().
 		Str("event_id", evalResult.ID).
@@ -101,6 +131,16 @@ func (h *Handler) EvaluateSingleJudge(req *restful.Request, resp *restful.Respon
 		return
 	}
 
+	// The options override the full pipeline, which a single judge run does not
+	// have. The pass/fail threshold is the threshold query param.
+	if evalRequest.Options != nil {
+		h.logger.Warn().Str("judge_name", judgeName).Msg("Options set on a single judge evaluation")
+		resp.WriteHeaderAndEntity(http.StatusBadRequest, map[string]string{
+			"error": "options are not supported by single judge evaluations, use the threshold query param",
+		})
+		return
+	}
+
 	h.logger.Info().
 		Str("event_id", evalRequest.EventID).
 		Str("judge_name", judgeName).
@@ -142,6 +182,23 @@ func (h *Handler) EvaluateSingleJudge(req *restful.Request, resp *restful.Respon
 
 }
 
+// GET /api/v1/judges
+// Returns: judge.Catalog of the active judges configuration
+func (h *Handler) ListJudges(req *restful.Request, resp *restful.Response) {
+	if h.catalog == nil {
+		middleware.HandleError(resp, errors.New("judge catalog not available"), http.StatusNotFound)
+		return
+	}
+
+	resp.WriteHeaderAndEntity(http.StatusOK, h.catalog.Catalog())
+}
+
+// GET /api/v1/config
+// Returns: models.PipelineConfig with the aggregation and precheck settings
+func (h *Handler) Config(req *restful.Request, resp *restful.Response) {
+	resp.WriteHeaderAndEntity(http.StatusOK, h.executor.Config())
+}
+
 // Health handler GET API /api/v1/health
 func (h *Handler) Health(req *restful.Request, resp *restful.Response) {
 	healthResponse := HealthResponse{
@@ -156,8 +213,10 @@ func normalize(req models.EvaluationRequest) models.EvaluationContext {
 	return models.EvaluationContext{
 		RequestID: req.EventID,
 		Query:     req.Interaction.UserQuery,
-		Context:   req.Interaction.Context,
+		Context:   models.ContextText(req.Interaction.Context, req.Interaction.Contexts),
+		Contexts:  req.Interaction.Contexts,
 		Answer:    req.Interaction.Answer,
+		Reference: req.Interaction.Reference,
 		CreatedAt: time.Now(),
 	}
 }
@@ -172,5 +231,10 @@ func validateEvaluationRequest(evalRequest models.EvaluationRequest) error {
 	if evalRequest.Interaction.Answer == "" {
 		return errors.New("answer is required")
 	}
-	return nil
+	for i, chunk := range evalRequest.Interaction.Contexts {
+		if strings.TrimSpace(chunk.Content) == "" {
+			return fmt.Errorf("contexts[%d].content is required", i)
+		}
+	}
+	return evalRequest.Options.Validate()
 }
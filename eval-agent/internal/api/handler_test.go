@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/rs/zerolog"
+)
+
+func TestEvaluateSingleJudge_RejectsOptions(t *testing.T) {
+	logger := zerolog.Nop()
+	container := restful.NewContainer()
+	// Rejected before the judge runs, so no executor is needed
+	RegisterRoutes(container, NewHandler(nil, nil, &logger))
+
+	body := `{"event_id": "e1", "interaction": {"user_query": "What is AI?", "answer": "Artificial Intelligence."}, "options": {"skip_prechecks": true}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/evaluate/judge/relevance", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "options are not supported") {
+		t.Errorf("Expected status 400 for options, got %d. Body: %s", recorder.Code, recorder.Body.String())
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	restful "github.com/emicklei/go-restful/v3"
+)
+
+var (
+	ErrUnauthorized = errors.New("missing or invalid bearer token")
+)
+
+// BearerToken rejects requests without an "Authorization: Bearer <token>" header
+// matching the token with 401 Unauthorized. An empty token rejects every request.
+func BearerToken(token string) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		given, ok := strings.CutPrefix(req.HeaderParameter("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			resp.AddHeader("WWW-Authenticate", "Bearer")
+			HandleError(resp, ErrUnauthorized, http.StatusUnauthorized)
+			return
+		}
+
+		chain.ProcessFilter(req, resp)
+	}
+}
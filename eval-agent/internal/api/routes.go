@@ -4,6 +4,7 @@ import (
 	restfulspec "github.com/emicklei/go-restful-openapi/v2"
 	"github.com/emicklei/go-restful/v3"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/api/middleware"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 )
 
@@ -40,7 +41,7 @@ func RegisterRoutes(container *restful.Container, handler *Handler) {
 			To(handler.EvaluateSingleJudge).
 			Doc("Evaluate with a single judge").
 			Metadata(restfulspec.KeyOpenAPITags, []string{"evaluate"}).
-			Param(ws.PathParameter("judge_name", "Judge name (relevance, faithfulness, coherence, completeness, instruction)").DataType("string")).
+			Param(ws.PathParameter("judge_name", "Judge name, as listed by GET /judges").DataType("string")).
 			Param(ws.QueryParameter("threshold", "Pass/fail threshold (0.0-1.0, default: 0.7)").DataType("number").Required(false)).
 			Reads(models.EvaluationRequest{}).
 			Writes(models.EvaluationResult{}).
@@ -49,5 +50,55 @@ func RegisterRoutes(container *restful.Container, handler *Handler) {
 			Returns(404, "Judge Not Found", middleware.ErrorResponse{}).
 			Returns(500, "Internal Server Error", middleware.ErrorResponse{}))
 
+	ws.
+		Route(ws.GET("/judges").
+			To(handler.ListJudges).
+			Doc("List the judges of the active configuration").
+			Metadata(restfulspec.KeyOpenAPITags, []string{"config"}).
+			Writes(judge.Catalog{}).
+			Returns(200, "OK", judge.Catalog{}).
+			Returns(404, "Catalog Not Available", middleware.ErrorResponse{}))
+
+	ws.
+		Route(ws.GET("/config").
+			To(handler.Config).
+			Doc("Aggregation and precheck settings").
+			Metadata(restfulspec.KeyOpenAPITags, []string{"config"}).
+			Writes(models.PipelineConfig{}).
+			Returns(200, "OK", models.PipelineConfig{}))
+
+	container.Add(ws)
+}
+
+// RegisterAdminRoutes adds the admin routes, which require the bearer token
+func RegisterAdminRoutes(container *restful.Container, handler *AdminHandler, token string) {
+	ws := new(restful.WebService)
+
+	ws.
+		Path("/api/v1/admin").
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON).
+		Filter(middleware.BearerToken(token))
+
+	ws.
+		Route(ws.POST("/reload").
+			To(handler.Reload).
+			Consumes("*/*"). // no request body
+			Doc("Reload the judges configuration").
+			Metadata(restfulspec.KeyOpenAPITags, []string{"admin"}).
+			Writes(ReloadResponse{}).
+			Returns(200, "OK", ReloadResponse{}).
+			Returns(401, "Unauthorized", middleware.ErrorResponse{}).
+			Returns(422, "Configuration Rejected", ReloadResponse{}))
+
+	ws.
+		Route(ws.GET("/config/version").
+			To(handler.ConfigVersion).
+			Doc("Active judges configuration version and hash").
+			Metadata(restfulspec.KeyOpenAPITags, []string{"admin"}).
+			Writes(judge.ConfigVersion{}).
+			Returns(200, "OK", judge.ConfigVersion{}).
+			Returns(401, "Unauthorized", middleware.ErrorResponse{}))
+
 	container.Add(ws)
 }
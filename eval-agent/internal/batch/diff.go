@@ -0,0 +1,164 @@
+package batch
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// unknownPipelineVersion groups results written before pipeline versioning
+const unknownPipelineVersion = "unknown"
+
+// ResultChange is a result whose verdict changed between two runs
+type ResultChange struct {
+	ID                 string         `json:"id"`
+	BaselineVerdict    models.Verdict `json:"baseline_verdict"`
+	CurrentVerdict     models.Verdict `json:"current_verdict"`
+	BaselineConfidence float64        `json:"baseline_confidence"`
+	CurrentConfidence  float64        `json:"current_confidence"`
+}
+
+// DiffReport compares the results of two batch runs over the same records
+type DiffReport struct {
+	Compared           int            `json:"compared"`
+	OnlyInBaseline     int            `json:"only_in_baseline"`
+	OnlyInCurrent      int            `json:"only_in_current"`
+	VerdictChanges     []ResultChange `json:"verdict_changes"`
+	AvgConfidenceDelta float64        `json:"avg_confidence_delta"`
+	BaselineVersions   map[string]int `json:"baseline_pipeline_versions,omitempty"`
+	CurrentVersions    map[string]int `json:"current_pipeline_versions,omitempty"`
+	Warnings           []string       `json:"warnings,omitempty"`
+}
+
+// ReadResults parses a JSONL file of evaluation results (the jsonl output format)
+func ReadResults(r io.Reader) ([]models.EvaluationResult, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	var results []models.EvaluationResult
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var result models.EvaluationResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			return nil, fmt.Errorf("line %d: parse error: %w", lineNum, err)
+		}
+		results = append(results, result)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read results: %w", err)
+	}
+
+	return results, nil
+}
+
+// DiffResults matches results by ID and reports verdict changes. Results coming
+// from different pipeline versions are still compared, with a warning, since score
+// changes may be caused by the configuration rather than by the evaluated answers.
+func DiffResults(baseline []models.EvaluationResult, current []models.EvaluationResult) DiffReport {
+	report := DiffReport{
+		VerdictChanges:   []ResultChange{},
+		BaselineVersions: pipelineVersions(baseline),
+		CurrentVersions:  pipelineVersions(current),
+	}
+
+	baselineByID := make(map[string]models.EvaluationResult, len(baseline))
+	for _, result := range baseline {
+		baselineByID[result.ID] = result
+	}
+
+	var totalDelta float64
+	matched := make(map[string]bool, len(current))
+	for _, result := range current {
+		previous, ok := baselineByID[result.ID]
+		if !ok {
+			report.OnlyInCurrent++
+			continue
+		}
+		matched[result.ID] = true
+		report.Compared++
+		totalDelta += result.Confidence - previous.Confidence
+
+		if result.Verdict != previous.Verdict {
+			report.VerdictChanges = append(report.VerdictChanges, ResultChange{
+				ID:                 result.ID,
+				BaselineVerdict:    previous.Verdict,
+				CurrentVerdict:     result.Verdict,
+				BaselineConfidence: previous.Confidence,
+				CurrentConfidence:  result.Confidence,
+			})
+		}
+	}
+	report.OnlyInBaseline = len(baselineByID) - len(matched)
+
+	if report.Compared > 0 {
+		report.AvgConfidenceDelta = totalDelta / float64(report.Compared)
+	}
+
+	for _, warning := range []string{
+		mixedVersionsWarning("baseline results", report.BaselineVersions),
+		mixedVersionsWarning("current results", report.CurrentVersions),
+	} {
+		if warning != "" {
+			report.Warnings = append(report.Warnings, warning)
+		}
+	}
+
+	if len(report.BaselineVersions) == 1 && len(report.CurrentVersions) == 1 {
+		baselineVersion, currentVersion := onlyKey(report.BaselineVersions), onlyKey(report.CurrentVersions)
+		if baselineVersion != currentVersion {
+			report.Warnings = append(report.Warnings, fmt.Sprintf(
+				"baseline and current results come from different pipeline versions (%s vs %s); differences may be caused by configuration changes",
+				baselineVersion, currentVersion))
+		}
+	}
+
+	return report
+}
+
+// pipelineVersions counts results per pipeline version
+func pipelineVersions(results []models.EvaluationResult) map[string]int {
+	versions := make(map[string]int)
+	for _, result := range results {
+		version := unknownPipelineVersion
+		if result.Pipeline != nil && result.Pipeline.Version != "" {
+			version = result.Pipeline.Version
+		}
+		versions[version]++
+	}
+	return versions
+}
+
+// mixedVersionsWarning returns a warning when results span several pipeline versions
+func mixedVersionsWarning(label string, versions map[string]int) string {
+	if len(versions) <= 1 {
+		return ""
+	}
+
+	names := make([]string, 0, len(versions))
+	for version := range versions {
+		names = append(names, version)
+	}
+	sort.Strings(names)
+
+	return fmt.Sprintf("%s come from %d pipeline versions (%s); scores are not directly comparable",
+		label, len(versions), strings.Join(names, ", "))
+}
+
+func onlyKey(versions map[string]int) string {
+	for version := range versions {
+		return version
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package batch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+func versioned(id string, verdict models.Verdict, confidence float64, version string) models.EvaluationResult {
+	return models.EvaluationResult{
+		ID:         id,
+		Verdict:    verdict,
+		Confidence: confidence,
+		Pipeline:   &models.PipelineInfo{Version: version},
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	baseline := []models.EvaluationResult{
+		versioned("1", models.VerdictPass, 0.9, "v1"),
+		versioned("2", models.VerdictFail, 0.3, "v1"),
+		versioned("3", models.VerdictReview, 0.6, "v1"),
+	}
+	current := []models.EvaluationResult{
+		versioned("1", models.VerdictPass, 0.8, "v2"),
+		versioned("2", models.VerdictReview, 0.6, "v2"),
+		versioned("4", models.VerdictPass, 0.9, "v2"),
+	}
+
+	report := DiffResults(baseline, current)
+
+	if report.Compared != 2 || report.OnlyInBaseline != 1 || report.OnlyInCurrent != 1 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+	if len(report.VerdictChanges) != 1 || report.VerdictChanges[0].ID != "2" {
+		t.Fatalf("expected verdict change for record 2, got %+v", report.VerdictChanges)
+	}
+	if delta := report.AvgConfidenceDelta; delta < 0.099 || delta > 0.101 {
+		t.Errorf("expected avg confidence delta 0.1, got %v", delta)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "v1 vs v2") {
+		t.Errorf("expected pipeline version warning, got %v", report.Warnings)
+	}
+}
+
+func TestDiffResults_SameVersion(t *testing.T) {
+	results := []models.EvaluationResult{versioned("1", models.VerdictPass, 0.9, "v1")}
+
+	report := DiffResults(results, results)
+	if len(report.Warnings) != 0 || len(report.VerdictChanges) != 0 {
+		t.Errorf("expected no warnings or changes, got %+v", report)
+	}
+}
+
+func TestReadResults(t *testing.T) {
+	input := `{"id":"1","stages":[],"confidence":0.9,"verdict":"pass","pipeline":{"version":"v1","weights":{"prechecks":0.3,"llm_judge":0.7},"prechecks":[],"early_exit_threshold":0.2}}
+
+{"id":"2","stages":[],"confidence":0.3,"verdict":"fail"}
+`
+	results, err := ReadResults(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadResults failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Pipeline.Version != "v1" || results[1].Pipeline != nil {
+		t.Errorf("unexpected results: %+v", results)
+	}
+
+	if _, err := ReadResults(strings.NewReader("not json\n")); err == nil {
+		t.Error("expected parse error")
+	}
+}
@@ -0,0 +1,289 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/rs/zerolog"
+)
+
+const (
+	VariantSourceBaseline  = "baseline"
+	VariantSourceConfig    = "config"
+	VariantSourceGenerated = "generated"
+)
+
+// ExecutorBuilder builds an evaluation pipeline for a judges configuration
+type ExecutorBuilder func(judgesConfig *config.JudgesConfig) (Executor, error)
+
+// PromptVariant is a candidate prompt for the judge being optimized
+type PromptVariant struct {
+	Name   string
+	Prompt string
+	Source string
+}
+
+// VariantEvaluation holds the outcome of evaluating the annotated set with one prompt variant
+type VariantEvaluation struct {
+	Variant    PromptVariant
+	Pairs      []AnnotationPair
+	Validation *ValidationResult
+}
+
+// Disagreement is an annotated record where the LLM verdict differs from the human annotation
+type Disagreement struct {
+	EventID         string  `json:"event_id"`
+	Query           string  `json:"user_query"`
+	Context         string  `json:"context,omitempty"`
+	Answer          string  `json:"answer"`
+	HumanAnnotation string  `json:"human_annotation"`
+	LLMVerdict      string  `json:"llm_verdict"`
+	Confidence      float64 `json:"confidence"`
+}
+
+// VariantScore summarizes how a prompt variant agrees with human annotations
+// compared to the baseline prompt
+type VariantScore struct {
+	Name           string   `json:"name"`
+	Source         string   `json:"source"`
+	AgreementCount int      `json:"agreement_count"`
+	AgreementRate  float64  `json:"agreement_rate"`
+	KendallTau     float64  `json:"kendall_tau"`
+	Fixed          []string `json:"fixed"`
+	Broken         []string `json:"broken"`
+}
+
+// OptimizationReport ranks prompt variants by agreement with human annotations
+type OptimizationReport struct {
+	Judge        string         `json:"judge"`
+	TotalRecords int            `json:"total_records"`
+	Baseline     VariantScore   `json:"baseline"`
+	Best         VariantScore   `json:"best"`
+	BestPrompt   string         `json:"best_prompt"`
+	Improved     bool           `json:"improved"`
+	Ranking      []VariantScore `json:"ranking"`
+}
+
+// PromptOptimizer evaluates prompt variants of a single judge against an annotated set
+type PromptOptimizer struct {
+	judgeName     string
+	judgesConfig  *config.JudgesConfig
+	buildExecutor ExecutorBuilder
+	workers       int
+	threshold     float64
+	logger        *zerolog.Logger
+}
+
+func NewPromptOptimizer(
+	judgeName string,
+	judgesConfig *config.JudgesConfig,
+	buildExecutor ExecutorBuilder,
+	workers int,
+	threshold float64,
+	logger *zerolog.Logger,
+) (*PromptOptimizer, error) {
+	if _, ok := judgesConfig.Judge(judgeName); !ok {
+		return nil, fmt.Errorf("judge %s not found in config", judgeName)
+	}
+
+	return &PromptOptimizer{
+		judgeName:     judgeName,
+		judgesConfig:  judgesConfig,
+		buildExecutor: buildExecutor,
+		workers:       workers,
+		threshold:     threshold,
+		logger:        logger,
+	}, nil
+}
+
+// Baseline returns the currently configured prompt of the judge as a variant
+func (o *PromptOptimizer) Baseline() PromptVariant {
+	judgeCfg, _ := o.judgesConfig.Judge(o.judgeName)
+	return PromptVariant{
+		Name:   "baseline",
+		Prompt: judgeCfg.Prompt,
+		Source: VariantSourceBaseline,
+	}
+}
+
+// Evaluate runs the full pipeline over the annotated records with the judge prompt
+// replaced by the variant and computes agreement with human annotations
+func (o *PromptOptimizer) Evaluate(ctx context.Context, records []InputRecord, variant PromptVariant) (*VariantEvaluation, error) {
+	judgesConfig, err := o.judgesConfig.WithJudgePrompt(o.judgeName, variant.Prompt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid variant %s: %w", variant.Name, err)
+	}
+
+	exec, err := o.buildExecutor(judgesConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build executor for variant %s: %w", variant.Name, err)
+	}
+
+	o.logger.Info().
+		Str("judge", o.judgeName).
+		Str("variant", variant.Name).
+		Str("source", variant.Source).
+		Msg("Evaluating prompt variant")
+
+	annotations := annotationsByEventID(records)
+	processor := NewProcessor(exec, o.workers, o.logger)
+
+	var pairs []AnnotationPair
+	for result := range processor.Process(ctx, records) {
+		humanAnnotation, ok := annotations[result.ID]
+		if !ok {
+			continue
+		}
+		pairs = append(pairs, AnnotationPair{
+			EventID:         result.ID,
+			HumanAnnotation: humanAnnotation,
+			LLMVerdict:      result.Verdict,
+			Confidence:      result.Confidence,
+		})
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Variants are compared on the same records, a record missing from one of
+	// them would skew the comparison
+	if failures := processor.Failures(); len(failures) > 0 {
+		return nil, fmt.Errorf("failed to evaluate variant %s: %w", variant.Name, failures[0])
+	}
+
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].EventID < pairs[j].EventID })
+
+	validation, err := ValidateAnnotations(pairs, o.threshold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate variant %s: %w", variant.Name, err)
+	}
+
+	return &VariantEvaluation{
+		Variant:    variant,
+		Pairs:      pairs,
+		Validation: validation,
+	}, nil
+}
+
+// Optimize evaluates the baseline and every candidate variant and ranks them.
+// Variants that fail to evaluate are logged and skipped.
+func (o *PromptOptimizer) Optimize(ctx context.Context, records []InputRecord, baseline *VariantEvaluation, candidates []PromptVariant) (*OptimizationReport, error) {
+	var evaluations []*VariantEvaluation
+	for _, candidate := range candidates {
+		evaluation, err := o.Evaluate(ctx, records, candidate)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			o.logger.Warn().Err(err).Str("variant", candidate.Name).Msg("Skipping prompt variant")
+			continue
+		}
+		evaluations = append(evaluations, evaluation)
+	}
+
+	return BuildOptimizationReport(o.judgeName, baseline, evaluations), nil
+}
+
+// BuildOptimizationReport ranks the variants by agreement rate, then by Kendall's tau.
+// The baseline wins exact ties, so a variant is only reported as an improvement when
+// it does measurably better.
+func BuildOptimizationReport(judgeName string, baseline *VariantEvaluation, candidates []*VariantEvaluation) *OptimizationReport {
+	baselineAgreed := agreedEventIDs(baseline.Pairs)
+
+	all := append([]*VariantEvaluation{baseline}, candidates...)
+	sort.SliceStable(all, func(i, j int) bool {
+		a, b := all[i].Validation, all[j].Validation
+		if a.AgreementRate != b.AgreementRate {
+			return a.AgreementRate > b.AgreementRate
+		}
+		return a.KendallTau > b.KendallTau
+	})
+
+	scores := make([]VariantScore, len(all))
+	for i, evaluation := range all {
+		scores[i] = scoreVariant(evaluation, baselineAgreed)
+	}
+
+	return &OptimizationReport{
+		Judge:        judgeName,
+		TotalRecords: baseline.Validation.TotalRecords,
+		Baseline:     scoreVariant(baseline, baselineAgreed),
+		Best:         scores[0],
+		BestPrompt:   all[0].Variant.Prompt,
+		Improved:     all[0] != baseline,
+		Ranking:      scores,
+	}
+}
+
+// Disagreements returns the annotated records the evaluation got wrong
+func Disagreements(records []InputRecord, evaluation *VariantEvaluation) []Disagreement {
+	byID := make(map[string]InputRecord, len(records))
+	for _, record := range records {
+		byID[record.Request.EventID] = record
+	}
+
+	var disagreements []Disagreement
+	for _, pair := range evaluation.Pairs {
+		if pair.HumanAnnotation == string(pair.LLMVerdict) {
+			continue
+		}
+		interaction := byID[pair.EventID].Request.Interaction
+		disagreements = append(disagreements, Disagreement{
+			EventID:         pair.EventID,
+			Query:           interaction.UserQuery,
+			Context:         interaction.Context,
+			Answer:          interaction.Answer,
+			HumanAnnotation: pair.HumanAnnotation,
+			LLMVerdict:      string(pair.LLMVerdict),
+			Confidence:      pair.Confidence,
+		})
+	}
+
+	return disagreements
+}
+
+func scoreVariant(evaluation *VariantEvaluation, baselineAgreed map[string]bool) VariantScore {
+	score := VariantScore{
+		Name:           evaluation.Variant.Name,
+		Source:         evaluation.Variant.Source,
+		AgreementCount: evaluation.Validation.AgreementCount,
+		AgreementRate:  evaluation.Validation.AgreementRate,
+		KendallTau:     evaluation.Validation.KendallTau,
+		Fixed:          []string{},
+		Broken:         []string{},
+	}
+
+	for _, pair := range evaluation.Pairs {
+		agreed := pair.HumanAnnotation == string(pair.LLMVerdict)
+		wasAgreed := baselineAgreed[pair.EventID]
+
+		if agreed && !wasAgreed {
+			score.Fixed = append(score.Fixed, pair.EventID)
+		} else if !agreed && wasAgreed {
+			score.Broken = append(score.Broken, pair.EventID)
+		}
+	}
+
+	return score
+}
+
+func agreedEventIDs(pairs []AnnotationPair) map[string]bool {
+	agreed := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		agreed[pair.EventID] = pair.HumanAnnotation == string(pair.LLMVerdict)
+	}
+	return agreed
+}
+
+func annotationsByEventID(records []InputRecord) map[string]string {
+	annotations := make(map[string]string, len(records))
+	for _, record := range records {
+		if record.Error != nil || record.Request.HumanAnnotation == nil || *record.Request.HumanAnnotation == "" {
+			continue
+		}
+		annotations[record.Request.EventID] = *record.Request.HumanAnnotation
+	}
+	return annotations
+}
@@ -0,0 +1,148 @@
+package batch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// verdictExecutor returns fixed verdicts per event ID
+type verdictExecutor struct {
+	verdicts map[string]models.Verdict
+}
+
+func (e *verdictExecutor) ExecuteWithOptions(ctx context.Context, evalCtx models.EvaluationContext, options *models.EvaluationOptions) (models.EvaluationResult, error) {
+	return models.EvaluationResult{
+		ID:      evalCtx.RequestID,
+		Verdict: e.verdicts[evalCtx.RequestID],
+	}, nil
+}
+
+func annotatedRecords(annotations ...string) []InputRecord {
+	records := make([]InputRecord, len(annotations))
+	for i, annotation := range annotations {
+		a := annotation
+		records[i] = InputRecord{
+			LineNumber: i + 1,
+			Request: models.EvaluationRequest{
+				EventID: string(rune('a' + i)),
+				Interaction: models.Interaction{
+					UserQuery: "query",
+					Answer:    "answer",
+				},
+				HumanAnnotation: &a,
+			},
+		}
+	}
+	return records
+}
+
+func optimizerJudgesConfig() *config.JudgesConfig {
+	return &config.JudgesConfig{
+		Judges: config.Judges{
+			Evaluators: []config.JudgeConfiguration{
+				{Name: "relevance", Enabled: true, Prompt: "baseline {{.Answer}}", Model: &config.ModelConfig{MaxTokens: 256}},
+				{Name: "coherence", Enabled: true, Prompt: "coherence {{.Answer}}", Model: &config.ModelConfig{MaxTokens: 256}},
+			},
+		},
+	}
+}
+
+func TestPromptOptimizer_RanksVariantsAndReportsFixedAndBroken(t *testing.T) {
+	logger := zerolog.Nop()
+	records := annotatedRecords("pass", "fail", "review", "fail")
+
+	// Verdicts produced by each prompt of the relevance judge
+	byPrompt := map[string]map[string]models.Verdict{
+		"baseline {{.Answer}}": {"a": "pass", "b": "pass", "c": "review", "d": "pass"},
+		"better {{.Answer}}":   {"a": "pass", "b": "fail", "c": "fail", "d": "fail"},
+		"worse {{.Answer}}":    {"a": "fail", "b": "pass", "c": "pass", "d": "pass"},
+	}
+
+	build := func(judgesConfig *config.JudgesConfig) (Executor, error) {
+		judgeCfg, _ := judgesConfig.Judge("relevance")
+		return &verdictExecutor{verdicts: byPrompt[judgeCfg.Prompt]}, nil
+	}
+
+	optimizer, err := NewPromptOptimizer("relevance", optimizerJudgesConfig(), build, 2, 0.3, &logger)
+	if err != nil {
+		t.Fatalf("NewPromptOptimizer failed: %v", err)
+	}
+
+	ctx := context.Background()
+	baseline, err := optimizer.Evaluate(ctx, records, optimizer.Baseline())
+	if err != nil {
+		t.Fatalf("Evaluate baseline failed: %v", err)
+	}
+
+	disagreements := Disagreements(records, baseline)
+	if len(disagreements) != 2 {
+		t.Fatalf("Expected 2 disagreements, got %d", len(disagreements))
+	}
+	if disagreements[0].EventID != "b" || disagreements[0].HumanAnnotation != "fail" || disagreements[0].LLMVerdict != "pass" {
+		t.Errorf("Unexpected disagreement: %+v", disagreements[0])
+	}
+
+	report, err := optimizer.Optimize(ctx, records, baseline, []PromptVariant{
+		{Name: "worse", Prompt: "worse {{.Answer}}", Source: VariantSourceConfig},
+		{Name: "better", Prompt: "better {{.Answer}}", Source: VariantSourceConfig},
+	})
+	if err != nil {
+		t.Fatalf("Optimize failed: %v", err)
+	}
+
+	if !report.Improved {
+		t.Error("Expected report to be improved")
+	}
+	if report.Best.Name != "better" {
+		t.Errorf("Expected best variant 'better', got '%s'", report.Best.Name)
+	}
+	if report.BestPrompt != "better {{.Answer}}" {
+		t.Errorf("Unexpected best prompt: %s", report.BestPrompt)
+	}
+	if len(report.Ranking) != 3 || report.Ranking[1].Name != "baseline" || report.Ranking[2].Name != "worse" {
+		t.Errorf("Unexpected ranking: %+v", report.Ranking)
+	}
+
+	// better fixes b and d, breaks c
+	if len(report.Best.Fixed) != 2 || report.Best.Fixed[0] != "b" || report.Best.Fixed[1] != "d" {
+		t.Errorf("Expected fixed [b d], got %v", report.Best.Fixed)
+	}
+	if len(report.Best.Broken) != 1 || report.Best.Broken[0] != "c" {
+		t.Errorf("Expected broken [c], got %v", report.Best.Broken)
+	}
+}
+
+func TestBuildOptimizationReport_BaselineWinsTies(t *testing.T) {
+	baseline := &VariantEvaluation{
+		Variant:    PromptVariant{Name: "baseline", Prompt: "a", Source: VariantSourceBaseline},
+		Pairs:      []AnnotationPair{{"1", "pass", models.VerdictPass, 0.9}, {"2", "fail", models.VerdictPass, 0.9}},
+		Validation: &ValidationResult{TotalRecords: 2, AgreementCount: 1, AgreementRate: 0.5},
+	}
+	tied := &VariantEvaluation{
+		Variant:    PromptVariant{Name: "tied", Prompt: "b", Source: VariantSourceConfig},
+		Pairs:      []AnnotationPair{{"1", "pass", models.VerdictFail, 0.1}, {"2", "fail", models.VerdictFail, 0.1}},
+		Validation: &ValidationResult{TotalRecords: 2, AgreementCount: 1, AgreementRate: 0.5},
+	}
+
+	report := BuildOptimizationReport("relevance", baseline, []*VariantEvaluation{tied})
+
+	if report.Improved {
+		t.Error("Expected no improvement on a tie")
+	}
+	if report.Best.Name != "baseline" || report.BestPrompt != "a" {
+		t.Errorf("Expected baseline to win, got %s", report.Best.Name)
+	}
+}
+
+func TestNewPromptOptimizer_UnknownJudge(t *testing.T) {
+	logger := zerolog.Nop()
+
+	_, err := NewPromptOptimizer("missing", optimizerJudgesConfig(), nil, 1, 0.3, &logger)
+	if err == nil {
+		t.Error("Expected error for unknown judge")
+	}
+}
@@ -2,21 +2,47 @@ package batch
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 )
 
+// Executor runs the pipeline for one record with the record's evaluation options
 type Executor interface {
-	Execute(ctx context.Context, evalCtx models.EvaluationContext) models.EvaluationResult
+	ExecuteWithOptions(ctx context.Context, evalCtx models.EvaluationContext, options *models.EvaluationOptions) (models.EvaluationResult, error)
+}
+
+// RecordError is a record that could not be evaluated, e.g. for options the
+// pipeline cannot honor. The record has no result.
+type RecordError struct {
+	LineNumber int
+	EventID    string
+	Err        error
+}
+
+func (e RecordError) Error() string {
+	return fmt.Sprintf("line %d (event %s): %v", e.LineNumber, e.EventID, e.Err)
+}
+
+func (e RecordError) Unwrap() error {
+	return e.Err
 }
 
 type Processor struct {
 	executor Executor
 	workers  int
+	budget   float64 // Maximum cost in USD, 0 = unlimited
 	logger   *zerolog.Logger
+
+	mu       sync.Mutex
+	spent    float64
+	exceeded bool
+	unpriced bool
+	failures []RecordError
 }
 
 func NewProcessor(exec Executor, workers int, logger *zerolog.Logger) *Processor {
@@ -27,6 +53,34 @@ func NewProcessor(exec Executor, workers int, logger *zerolog.Logger) *Processor
 	}
 }
 
+// WithBudget stops the run once the evaluations have cost more than maxCostUSD.
+// Evaluations already in flight complete; the remaining records are skipped.
+func (p *Processor) WithBudget(maxCostUSD float64) *Processor {
+	p.budget = maxCostUSD
+	return p
+}
+
+// Spent returns the cost in USD of the evaluations processed so far
+func (p *Processor) Spent() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.spent
+}
+
+// BudgetExceeded reports whether the run was stopped by the budget
+func (p *Processor) BudgetExceeded() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.exceeded
+}
+
+// Failures returns the records that could not be evaluated, in completion order
+func (p *Processor) Failures() []RecordError {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]RecordError(nil), p.failures...)
+}
+
 // Process takes input records and returns evaluation results via channel
 func (p *Processor) Process(ctx context.Context, records []InputRecord) <-chan models.EvaluationResult {
 	results := make(chan models.EvaluationResult, len(records))
@@ -73,17 +127,78 @@ func (p *Processor) worker(ctx context.Context, workerID int, jobs <-chan InputR
 			continue
 		}
 
+		if p.BudgetExceeded() {
+			p.logger.Debug().
+				Int("worker", workerID).
+				Str("event_id", record.Request.EventID).
+				Msg("Skipping record, budget exceeded")
+			continue
+		}
+
 		evalCtx := models.EvaluationContext{
 			RequestID: record.Request.EventID,
 			Query:     record.Request.Interaction.UserQuery,
-			Context:   record.Request.Interaction.Context,
+			Context:   models.ContextText(record.Request.Interaction.Context, record.Request.Interaction.Contexts),
+			Contexts:  record.Request.Interaction.Contexts,
 			Answer:    record.Request.Interaction.Answer,
+			Reference: record.Request.Interaction.Reference,
 			CreatedAt: time.Now(),
 		}
 
-		result := p.executor.Execute(ctx, evalCtx)
+		options := record.Request.Options
+		if err := options.Validate(); err != nil {
+			p.fail(workerID, record, fmt.Errorf("%w: %v", executor.ErrInvalidOptions, err))
+			continue
+		}
+
+		result, err := p.executor.ExecuteWithOptions(ctx, evalCtx, options)
+		p.charge(result)
+		if err != nil {
+			p.fail(workerID, record, err)
+			continue
+		}
 		results <- result
 	}
 
 	p.logger.Debug().Int("worker", workerID).Msg("Worker finished")
 }
+
+// fail records a record that could not be evaluated
+func (p *Processor) fail(workerID int, record InputRecord, err error) {
+	p.logger.Warn().
+		Int("worker", workerID).
+		Int("line", record.LineNumber).
+		Str("event_id", record.Request.EventID).
+		Err(err).
+		Msg("Record could not be evaluated")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures = append(p.failures, RecordError{LineNumber: record.LineNumber, EventID: record.Request.EventID, Err: err})
+}
+
+// charge adds the cost of a result to the amount spent and stops the run when it
+// exceeds the budget
+func (p *Processor) charge(result models.EvaluationResult) {
+	if result.Usage == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.spent += result.Usage.CostUSD
+	if p.budget > 0 && result.Usage.Unpriced && !p.unpriced {
+		p.unpriced = true
+		p.logger.Warn().
+			Float64("budget_usd", p.budget).
+			Msg("Evaluations use models missing from the price table, the budget does not account for them")
+	}
+	if p.budget > 0 && p.spent > p.budget && !p.exceeded {
+		p.exceeded = true
+		p.logger.Error().
+			Float64("budget_usd", p.budget).
+			Float64("spent_usd", p.spent).
+			Msg("Budget exceeded, skipping remaining records")
+	}
+}
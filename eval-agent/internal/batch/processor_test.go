@@ -2,9 +2,13 @@ package batch
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"sync"
 	"testing"
 
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 )
@@ -14,12 +18,12 @@ type mockExecutor struct {
 	called int
 }
 
-func (m *mockExecutor) Execute(ctx context.Context, evalCtx models.EvaluationContext) models.EvaluationResult {
+func (m *mockExecutor) ExecuteWithOptions(ctx context.Context, evalCtx models.EvaluationContext, options *models.EvaluationOptions) (models.EvaluationResult, error) {
 	m.called++
 	return models.EvaluationResult{
 		ID:      evalCtx.RequestID,
 		Verdict: models.VerdictPass,
-	}
+	}, nil
 }
 
 func TestProcessor_Process(t *testing.T) {
@@ -78,3 +82,120 @@ func TestProcessor_SkipsErrorRecords(t *testing.T) {
 		t.Errorf("expected executor called 2 times, got %d", executor.called)
 	}
 }
+
+// costExecutor charges a fixed cost per evaluation
+type costExecutor struct {
+	mu     sync.Mutex
+	called int
+	cost   float64
+}
+
+func (m *costExecutor) ExecuteWithOptions(ctx context.Context, evalCtx models.EvaluationContext, options *models.EvaluationOptions) (models.EvaluationResult, error) {
+	m.mu.Lock()
+	m.called++
+	m.mu.Unlock()
+	return models.EvaluationResult{
+		ID:      evalCtx.RequestID,
+		Verdict: models.VerdictPass,
+		Usage:   &models.TokenUsage{InputTokens: 1000, OutputTokens: 100, CostUSD: m.cost},
+	}, nil
+}
+
+func TestProcessor_Budget(t *testing.T) {
+	logger := zerolog.Nop()
+	executor := &costExecutor{cost: 0.01}
+	processor := NewProcessor(executor, 1, &logger).WithBudget(0.025)
+
+	var records []InputRecord
+	for i := 1; i <= 10; i++ {
+		records = append(records, InputRecord{LineNumber: i, Request: models.EvaluationRequest{EventID: fmt.Sprint(i)}})
+	}
+
+	count := 0
+	for range processor.Process(context.Background(), records) {
+		count++
+	}
+
+	// The third evaluation exceeds the budget, the remaining records are skipped
+	if count != 3 || executor.called != 3 {
+		t.Errorf("expected 3 evaluations before the budget stopped the run, got %d results, %d calls", count, executor.called)
+	}
+	if !processor.BudgetExceeded() {
+		t.Error("expected budget to be exceeded")
+	}
+	if math.Abs(processor.Spent()-0.03) > 1e-9 {
+		t.Errorf("expected 0.03 spent, got %v", processor.Spent())
+	}
+}
+
+func TestProcessor_NoBudget(t *testing.T) {
+	logger := zerolog.Nop()
+	executor := &costExecutor{cost: 0.01}
+	processor := NewProcessor(executor, 2, &logger)
+
+	records := []InputRecord{
+		{LineNumber: 1, Request: models.EvaluationRequest{EventID: "1"}},
+		{LineNumber: 2, Request: models.EvaluationRequest{EventID: "2"}},
+	}
+	for range processor.Process(context.Background(), records) {
+	}
+
+	if executor.called != 2 || processor.BudgetExceeded() {
+		t.Errorf("expected all records evaluated without a budget, got %d calls", executor.called)
+	}
+}
+
+// optionsExecutor records the options of each evaluation and rejects the judge "unknown"
+type optionsExecutor struct {
+	mu      sync.Mutex
+	options map[string]*models.EvaluationOptions
+}
+
+func (m *optionsExecutor) ExecuteWithOptions(ctx context.Context, evalCtx models.EvaluationContext, options *models.EvaluationOptions) (models.EvaluationResult, error) {
+	m.mu.Lock()
+	m.options[evalCtx.RequestID] = options
+	m.mu.Unlock()
+	if options != nil && len(options.Judges) > 0 && options.Judges[0] == "unknown" {
+		return models.EvaluationResult{ID: evalCtx.RequestID}, fmt.Errorf("%w: judge not found: unknown", executor.ErrInvalidOptions)
+	}
+	return models.EvaluationResult{ID: evalCtx.RequestID, Verdict: models.VerdictPass}, nil
+}
+
+func TestProcessor_RecordOptions(t *testing.T) {
+	logger := zerolog.Nop()
+	exec := &optionsExecutor{options: make(map[string]*models.EvaluationOptions)}
+	processor := NewProcessor(exec, 2, &logger)
+
+	relevance := &models.EvaluationOptions{Judges: []string{"relevance"}}
+	records := []InputRecord{
+		{LineNumber: 1, Request: models.EvaluationRequest{EventID: "1", Options: relevance}},
+		{LineNumber: 2, Request: models.EvaluationRequest{EventID: "2"}},
+		{LineNumber: 3, Request: models.EvaluationRequest{EventID: "3", Options: &models.EvaluationOptions{Judges: []string{"unknown"}}}},
+		{LineNumber: 4, Request: models.EvaluationRequest{EventID: "4", Options: &models.EvaluationOptions{Thresholds: &models.VerdictThresholds{Pass: 0.5, Review: 0.8}}}},
+	}
+
+	count := 0
+	for range processor.Process(context.Background(), records) {
+		count++
+	}
+
+	if count != 2 {
+		t.Errorf("expected 2 results, got %d", count)
+	}
+	if exec.options["1"] != relevance || exec.options["2"] != nil {
+		t.Errorf("expected the record options to reach the executor, got %v", exec.options)
+	}
+	if _, ok := exec.options["4"]; ok {
+		t.Error("expected invalid options to be rejected before the executor")
+	}
+
+	failures := processor.Failures()
+	if len(failures) != 2 {
+		t.Fatalf("expected 2 failures, got %v", failures)
+	}
+	for _, failure := range failures {
+		if !errors.Is(failure, executor.ErrInvalidOptions) || (failure.LineNumber != 3 && failure.LineNumber != 4) {
+			t.Errorf("expected lines 3 and 4 to fail with invalid options, got %v", failure)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package batch
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
@@ -14,6 +15,11 @@ type SummaryStats struct {
 	FailCount     int     `json:"fail_count"`
 	ReviewCount   int     `json:"review_count"`
 	AvgConfidence float64 `json:"avg_confidence"`
+
+	Usage models.TokenUsage `json:"usage"` // Total token usage and cost of the run
+
+	PipelineVersions map[string]int `json:"pipeline_versions,omitempty"` // Result count per pipeline version
+	Warnings         []string       `json:"warnings,omitempty"`
 }
 
 type SummaryWriter struct {
@@ -37,7 +43,10 @@ func (w *SummaryWriter) Write(result models.EvaluationResult) error {
 }
 
 func (w *SummaryWriter) Close() error {
-	stats := w.computeStats()
+	stats := ComputeStats(w.results)
+	for _, warning := range stats.Warnings {
+		w.logger.Warn().Msg(warning)
+	}
 
 	data, err := json.MarshalIndent(stats, "", "  ")
 	if err != nil {
@@ -48,15 +57,24 @@ func (w *SummaryWriter) Close() error {
 	return err
 }
 
-func (w *SummaryWriter) computeStats() SummaryStats {
+// ComputeStats returns the verdict counts, average confidence and total usage of
+// the results
+func ComputeStats(results []models.EvaluationResult) SummaryStats {
 	stats := SummaryStats{
-		Total: len(w.results),
+		Total: len(results),
 	}
 
 	var totalConfidence float64
+	unpriced := 0
 
-	for _, result := range w.results {
+	for _, result := range results {
 		totalConfidence += result.Confidence
+		if result.Usage != nil {
+			stats.Usage.Add(*result.Usage)
+			if result.Usage.Unpriced {
+				unpriced++
+			}
+		}
 
 		switch result.Verdict {
 		case models.VerdictPass:
@@ -72,5 +90,15 @@ func (w *SummaryWriter) computeStats() SummaryStats {
 		stats.AvgConfidence = totalConfidence / float64(stats.Total)
 	}
 
+	if unpriced > 0 {
+		stats.Warnings = append(stats.Warnings, fmt.Sprintf(
+			"%d results used models missing from the price table: usage.cost_usd and any budget undercount them", unpriced))
+	}
+
+	stats.PipelineVersions = pipelineVersions(results)
+	if warning := mixedVersionsWarning("results", stats.PipelineVersions); warning != "" {
+		stats.Warnings = append(stats.Warnings, warning)
+	}
+
 	return stats
 }
@@ -3,6 +3,8 @@ package batch
 import (
 	"bytes"
 	"encoding/json"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
@@ -15,8 +17,10 @@ func TestSummaryWriter(t *testing.T) {
 	writer := NewSummaryWriter(&buf, &logger)
 
 	// Write mix of pass, fail, review
-	writer.Write(models.EvaluationResult{ID: "1", Verdict: models.VerdictPass, Confidence: 0.9})
-	writer.Write(models.EvaluationResult{ID: "2", Verdict: models.VerdictFail, Confidence: 0.3})
+	writer.Write(models.EvaluationResult{ID: "1", Verdict: models.VerdictPass, Confidence: 0.9,
+		Usage: &models.TokenUsage{InputTokens: 1000, OutputTokens: 100, CostUSD: 0.0045}})
+	writer.Write(models.EvaluationResult{ID: "2", Verdict: models.VerdictFail, Confidence: 0.3,
+		Usage: &models.TokenUsage{InputTokens: 500, OutputTokens: 50, CostUSD: 0.002}})
 	writer.Write(models.EvaluationResult{ID: "3", Verdict: models.VerdictReview, Confidence: 0.6})
 
 	err := writer.Close()
@@ -45,4 +49,47 @@ func TestSummaryWriter(t *testing.T) {
 	if stats.AvgConfidence != wantAvg {
 		t.Errorf("AvgConfidence: got %v, want %v", stats.AvgConfidence, wantAvg)
 	}
+	if stats.Usage.InputTokens != 1500 || stats.Usage.OutputTokens != 150 || math.Abs(stats.Usage.CostUSD-0.0065) > 1e-12 {
+		t.Errorf("Usage: got %+v, want 1500/150 tokens and 0.0065 USD", stats.Usage)
+	}
+}
+
+func TestSummaryWriter_MixedPipelineVersions(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.Nop()
+	writer := NewSummaryWriter(&buf, &logger)
+
+	writer.Write(models.EvaluationResult{ID: "1", Verdict: models.VerdictPass, Pipeline: &models.PipelineInfo{Version: "v1"}})
+	writer.Write(models.EvaluationResult{ID: "2", Verdict: models.VerdictPass, Pipeline: &models.PipelineInfo{Version: "v2"}})
+	writer.Write(models.EvaluationResult{ID: "3", Verdict: models.VerdictPass, Pipeline: &models.PipelineInfo{Version: "v2"}})
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var stats SummaryStats
+	if err := json.Unmarshal(buf.Bytes(), &stats); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+
+	if stats.PipelineVersions["v1"] != 1 || stats.PipelineVersions["v2"] != 2 {
+		t.Errorf("PipelineVersions: got %v", stats.PipelineVersions)
+	}
+	if len(stats.Warnings) != 1 {
+		t.Errorf("expected one mixed versions warning, got %v", stats.Warnings)
+	}
+}
+
+func TestComputeStats_UnpricedUsage(t *testing.T) {
+	stats := ComputeStats([]models.EvaluationResult{
+		{ID: "1", Verdict: models.VerdictPass, Usage: &models.TokenUsage{InputTokens: 1000, OutputTokens: 100, CostUSD: 0.0045}},
+		{ID: "2", Verdict: models.VerdictPass, Usage: &models.TokenUsage{InputTokens: 1000, OutputTokens: 100, Unpriced: true}},
+	})
+
+	if !stats.Usage.Unpriced {
+		t.Error("expected the total usage to be flagged as unpriced")
+	}
+	if len(stats.Warnings) != 1 || !strings.Contains(stats.Warnings[0], "1 results used models missing from the price table") {
+		t.Errorf("expected an unpriced usage warning, got %v", stats.Warnings)
+	}
 }
@@ -0,0 +1,154 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/rs/zerolog"
+)
+
+// maxDisagreementExamples caps how many disagreement cases are shown to the LLM
+// when generating prompt variants, to keep the meta prompt within token limits
+const maxDisagreementExamples = 10
+
+var variantPromptTemplate = template.Must(template.New("variant").Parse(`You are an expert in writing prompts for LLM-as-a-judge evaluators.
+
+The judge "{{.JudgeName}}" ({{.Description}}) disagrees with human annotators on some records.
+Human annotations are verdicts: pass, review or fail.
+
+Current judge prompt (Go text/template syntax):
+<current_prompt>
+{{.Prompt}}
+</current_prompt>
+
+Records where the evaluation verdict disagreed with the human annotation:
+{{range .Disagreements}}
+- Query: {{.Query}}
+{{- if .Context}}
+  Context: {{.Context}}
+{{- end}}
+  Answer: {{.Answer}}
+  Human verdict: {{.HumanAnnotation}}
+  LLM verdict: {{.LLMVerdict}} (confidence {{printf "%.2f" .Confidence}})
+{{end}}
+Write variant {{.Index}} of {{.Count}} of an improved judge prompt that would agree with the human annotators more often.
+Each variant should try a different approach (e.g. stricter rubric, scoring examples, clearer criteria).
+
+Rules:
+- Keep the template placeholders used by the current prompt ({{"{{.Query}}"}}, {{"{{.Context}}"}}, {{"{{.Answer}}"}}, {{"{{.Examples}}"}}, {{"{{template ...}}"}} partials); do not invent new ones
+- Keep the output contract: the judge must respond ONLY in raw JSON {"score": <float>, "reason": "<string>"} with a score from 0.0 to 1.0
+
+Return only the new prompt wrapped in <prompt></prompt> tags.`))
+
+type variantPromptData struct {
+	JudgeName     string
+	Description   string
+	Prompt        string
+	Disagreements []Disagreement
+	Index         int
+	Count         int
+}
+
+// VariantGenerator asks an LLM to rewrite a judge prompt based on the cases
+// where it disagreed with human annotators
+type VariantGenerator struct {
+	llmClient   llm.LLMClient
+	maxTokens   int
+	temperature float64
+	logger      *zerolog.Logger
+}
+
+func NewVariantGenerator(llmClient llm.LLMClient, logger *zerolog.Logger) *VariantGenerator {
+	return &VariantGenerator{
+		llmClient:   llmClient,
+		maxTokens:   2048,
+		temperature: 0.7,
+		logger:      logger,
+	}
+}
+
+// Generate returns up to count prompt variants for the judge. Variants that do not
+// pass config validation (template syntax, dry-render) are discarded.
+func (g *VariantGenerator) Generate(
+	ctx context.Context,
+	judgesConfig *config.JudgesConfig,
+	judgeName string,
+	disagreements []Disagreement,
+	count int,
+) ([]PromptVariant, error) {
+	judgeCfg, ok := judgesConfig.Judge(judgeName)
+	if !ok {
+		return nil, fmt.Errorf("judge %s not found in config", judgeName)
+	}
+
+	if len(disagreements) > maxDisagreementExamples {
+		disagreements = disagreements[:maxDisagreementExamples]
+	}
+
+	var variants []PromptVariant
+	for i := 1; i <= count; i++ {
+		var buf bytes.Buffer
+		err := variantPromptTemplate.Execute(&buf, variantPromptData{
+			JudgeName:     judgeCfg.Name,
+			Description:   judgeCfg.Description,
+			Prompt:        judgeCfg.Prompt,
+			Disagreements: disagreements,
+			Index:         i,
+			Count:         count,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build variant prompt: %w", err)
+		}
+
+		resp, err := g.llmClient.InvokeModelWithRetry(ctx, llm.LLMRequest{
+			Prompt:      buf.String(),
+			MaxTokens:   g.maxTokens,
+			Temperature: g.temperature,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			g.logger.Warn().Err(err).Int("variant", i).Msg("Failed to generate prompt variant")
+			continue
+		}
+
+		prompt, err := extractPrompt(resp.Content)
+		if err == nil {
+			_, err = judgesConfig.WithJudgePrompt(judgeName, prompt)
+		}
+		if err != nil {
+			g.logger.Warn().Err(err).Int("variant", i).Msg("Discarding generated prompt variant")
+			continue
+		}
+
+		variants = append(variants, PromptVariant{
+			Name:   fmt.Sprintf("generated-%d", i),
+			Prompt: prompt,
+			Source: VariantSourceGenerated,
+		})
+	}
+
+	return variants, nil
+}
+
+// extractPrompt pulls the prompt out of <prompt> tags
+func extractPrompt(content string) (string, error) {
+	start := strings.Index(content, "<prompt>")
+	end := strings.LastIndex(content, "</prompt>")
+	if start == -1 || end == -1 || end < start {
+		return "", fmt.Errorf("response does not contain <prompt> tags")
+	}
+
+	prompt := strings.TrimSpace(content[start+len("<prompt>") : end])
+	if prompt == "" {
+		return "", fmt.Errorf("generated prompt is empty")
+	}
+
+	return prompt + "\n", nil
+}
@@ -0,0 +1,73 @@
+package batch
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/rs/zerolog"
+)
+
+type mockLLMClient struct {
+	responses []string
+	requests  []llm.LLMRequest
+}
+
+func (m *mockLLMClient) InvokeModel(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	m.requests = append(m.requests, request)
+	content := m.responses[0]
+	m.responses = m.responses[1:]
+	return &llm.LLMResponse{Content: content}, nil
+}
+
+func (m *mockLLMClient) InvokeModelWithRetry(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	return m.InvokeModel(ctx, request)
+}
+
+func TestVariantGenerator_Generate(t *testing.T) {
+	logger := zerolog.Nop()
+	client := &mockLLMClient{responses: []string{
+		"Here you go:\n<prompt>\nBe strict. Answer: {{.Answer}}\n</prompt>",
+		"<prompt>Broken {{.Answer</prompt>",
+		"<prompt>Unknown field {{.Missing}}</prompt>",
+		"no tags at all",
+	}}
+
+	generator := NewVariantGenerator(client, &logger)
+	judgesConfig := &config.JudgesConfig{
+		Judges: config.Judges{
+			Evaluators: []config.JudgeConfiguration{
+				{Name: "relevance", Prompt: "Answer: {{.Answer}}"},
+			},
+		},
+	}
+	disagreements := []Disagreement{{EventID: "1", Query: "What is Go?", Answer: "A game", HumanAnnotation: "fail", LLMVerdict: "pass"}}
+
+	variants, err := generator.Generate(context.Background(), judgesConfig, "relevance", disagreements, 4)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if len(variants) != 1 {
+		t.Fatalf("Expected 1 valid variant, got %d", len(variants))
+	}
+	if variants[0].Name != "generated-1" || variants[0].Source != VariantSourceGenerated {
+		t.Errorf("Unexpected variant: %+v", variants[0])
+	}
+	if variants[0].Prompt != "Be strict. Answer: {{.Answer}}\n" {
+		t.Errorf("Unexpected prompt: %q", variants[0].Prompt)
+	}
+
+	// Meta prompt contains the current prompt and the disagreement cases
+	if len(client.requests) != 4 {
+		t.Fatalf("Expected 4 LLM calls, got %d", len(client.requests))
+	}
+	metaPrompt := client.requests[0].Prompt
+	for _, expected := range []string{"Answer: {{.Answer}}", "What is Go?", "Human verdict: fail", "variant 1 of 4"} {
+		if !strings.Contains(metaPrompt, expected) {
+			t.Errorf("Expected meta prompt to contain %q", expected)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EmbeddingProviderEnv names the environment variable selecting the embedding
+// provider. Without one, embedding similarity cannot be used.
+const EmbeddingProviderEnv = "EMBEDDING_PROVIDER"
+
+// Few-shot example selection strategies
+const (
+	FewShotStatic  = "static"
+	FewShotRandom  = "random"
+	FewShotSimilar = "similar"
+)
+
+// Similarity measures used by the "similar" strategy
+const (
+	SimilarityLexical   = "lexical"
+	SimilarityEmbedding = "embedding"
+)
+
+// FewShotConfig defines the labeled examples available to a judge prompt as .Examples
+type FewShotConfig struct {
+	File       string           `yaml:"file,omitempty"`       // Examples file, relative to the judges config file
+	Strategy   string           `yaml:"strategy,omitempty"`   // static (default), random or similar
+	K          int              `yaml:"k,omitempty"`          // Number of examples to select (0 = all, static only)
+	Similarity string           `yaml:"similarity,omitempty"` // lexical (default) or embedding, for the similar strategy
+	Examples   []FewShotExample `yaml:"examples,omitempty"`   // Inline examples, merged with the file examples
+}
+
+// FewShotExample is a labeled evaluation used to calibrate a judge
+type FewShotExample struct {
+	Query   string  `yaml:"query" json:"query"`
+	Answer  string  `yaml:"answer" json:"answer"`
+	Context string  `yaml:"context,omitempty" json:"context,omitempty"`
+	Score   float64 `yaml:"score" json:"score"`
+	Reason  string  `yaml:"reason" json:"reason"`
+}
+
+type fewShotFile struct {
+	Examples []FewShotExample `yaml:"examples"`
+}
+
+// loadFewShotExamples reads the examples files of all judges and appends them to
+// the inline examples. Relative paths are resolved against baseDir.
+func loadFewShotExamples(cfg *JudgesConfig, baseDir string) error {
+	for i := range cfg.Judges.Evaluators {
+		judge := &cfg.Judges.Evaluators[i]
+		if judge.FewShot == nil || judge.FewShot.File == "" {
+			continue
+		}
+
+		path := resolvePath(baseDir, judge.FewShot.File)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("judge %s: failed to read few-shot file %s: %w", judge.Name, path, err)
+		}
+
+		var file fewShotFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("judge %s: failed to parse few-shot file %s: %w", judge.Name, path, err)
+		}
+
+		judge.FewShot.Examples = append(judge.FewShot.Examples, file.Examples...)
+	}
+
+	return nil
+}
+
+func applyFewShotDefaults(fewShot *FewShotConfig) {
+	if fewShot.Strategy == "" {
+		fewShot.Strategy = FewShotStatic
+	}
+	if fewShot.Strategy == FewShotSimilar && fewShot.Similarity == "" {
+		fewShot.Similarity = SimilarityLexical
+	}
+}
+
+func (fewShot *FewShotConfig) validate(judgeName string) error {
+	switch fewShot.Strategy {
+	case FewShotStatic, "":
+	case FewShotRandom, FewShotSimilar:
+		if fewShot.K <= 0 {
+			return fmt.Errorf("judge %s few_shot strategy %s requires k > 0", judgeName, fewShot.Strategy)
+		}
+	default:
+		return fmt.Errorf("judge %s has invalid few_shot strategy: %s (must be static, random or similar)", judgeName, fewShot.Strategy)
+	}
+
+	if fewShot.K < 0 {
+		return fmt.Errorf("judge %s has negative few_shot k: %d", judgeName, fewShot.K)
+	}
+
+	if fewShot.Strategy == FewShotSimilar && fewShot.Similarity != "" &&
+		fewShot.Similarity != SimilarityLexical && fewShot.Similarity != SimilarityEmbedding {
+		return fmt.Errorf("judge %s has invalid few_shot similarity: %s (must be lexical or embedding)", judgeName, fewShot.Similarity)
+	}
+
+	if fewShot.Strategy == FewShotSimilar && fewShot.Similarity == SimilarityEmbedding && os.Getenv(EmbeddingProviderEnv) == "" {
+		return fmt.Errorf("judge %s few_shot similarity embedding requires an embedding provider (%s)", judgeName, EmbeddingProviderEnv)
+	}
+
+	if len(fewShot.Examples) == 0 {
+		return fmt.Errorf("judge %s has few_shot configured without examples", judgeName)
+	}
+
+	for i, example := range fewShot.Examples {
+		if example.Query == "" || example.Answer == "" {
+			return fmt.Errorf("judge %s few-shot example %d is missing query or answer", judgeName, i)
+		}
+		if example.Score < 0.0 || example.Score > 1.0 {
+			return fmt.Errorf("judge %s few-shot example %d has invalid score: %f (must be 0.0-1.0)", judgeName, i, example.Score)
+		}
+	}
+
+	return nil
+}
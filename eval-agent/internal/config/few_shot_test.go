@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadJudgesConfig_FewShotFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "judges.yaml")
+	examplesPath := filepath.Join(tmpDir, "examples", "relevance.yaml")
+
+	configContent := `judges:
+  evaluators:
+    - name: relevance
+      enabled: true
+      prompt: |
+        {{range .Examples}}{{.Query}}{{end}} {{.Answer}}
+      few_shot:
+        file: examples/relevance.yaml
+        strategy: similar
+        k: 1
+        examples:
+          - query: "inline"
+            answer: "inline answer"
+            score: 0.5
+            reason: "inline"
+`
+	examplesContent := `examples:
+  - query: "What is Go?"
+    answer: "A programming language"
+    score: 1.0
+    reason: "Correct"
+`
+
+	if err := os.MkdirAll(filepath.Dir(examplesPath), 0755); err != nil {
+		t.Fatalf("Failed to create examples dir: %v", err)
+	}
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	if err := os.WriteFile(examplesPath, []byte(examplesContent), 0644); err != nil {
+		t.Fatalf("Failed to write examples: %v", err)
+	}
+
+	os.Setenv("JUDGES_CONFIG_PATH", configPath)
+	defer os.Unsetenv("JUDGES_CONFIG_PATH")
+
+	cfg, err := LoadJudgesConfig()
+	if err != nil {
+		t.Fatalf("LoadJudgesConfig() failed: %v", err)
+	}
+
+	fewShot := cfg.Judges.Evaluators[0].FewShot
+	if len(fewShot.Examples) != 2 {
+		t.Fatalf("Expected 2 examples (inline + file), got %d", len(fewShot.Examples))
+	}
+	if fewShot.Examples[1].Query != "What is Go?" {
+		t.Errorf("Expected file example after inline example, got '%s'", fewShot.Examples[1].Query)
+	}
+	if fewShot.Similarity != SimilarityLexical {
+		t.Errorf("Expected default similarity 'lexical', got '%s'", fewShot.Similarity)
+	}
+}
+
+func TestLoadJudgesConfig_FewShotFileNotFound(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "judges.yaml")
+
+	configContent := `judges:
+  evaluators:
+    - name: relevance
+      prompt: "{{.Answer}}"
+      few_shot:
+        file: missing.yaml
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	os.Setenv("JUDGES_CONFIG_PATH", configPath)
+	defer os.Unsetenv("JUDGES_CONFIG_PATH")
+
+	if _, err := LoadJudgesConfig(); err == nil {
+		t.Error("Expected error for missing few-shot file")
+	}
+}
+
+func TestValidate_FewShot(t *testing.T) {
+	example := FewShotExample{Query: "q", Answer: "a", Score: 0.5, Reason: "r"}
+
+	tests := []struct {
+		name     string
+		fewShot  FewShotConfig
+		provider string
+		wantErr  bool
+	}{
+		{"static all", FewShotConfig{Strategy: FewShotStatic, Examples: []FewShotExample{example}}, "", false},
+		{"similar embedding", FewShotConfig{Strategy: FewShotSimilar, K: 1, Similarity: SimilarityEmbedding, Examples: []FewShotExample{example}}, "local", false},
+		{"similar embedding without provider", FewShotConfig{Strategy: FewShotSimilar, K: 1, Similarity: SimilarityEmbedding, Examples: []FewShotExample{example}}, "", true},
+		{"unknown strategy", FewShotConfig{Strategy: "best", K: 1, Examples: []FewShotExample{example}}, "", true},
+		{"random without k", FewShotConfig{Strategy: FewShotRandom, Examples: []FewShotExample{example}}, "", true},
+		{"unknown similarity", FewShotConfig{Strategy: FewShotSimilar, K: 1, Similarity: "bm25", Examples: []FewShotExample{example}}, "", true},
+		{"no examples", FewShotConfig{Strategy: FewShotStatic}, "", true},
+		{"example missing answer", FewShotConfig{Examples: []FewShotExample{{Query: "q", Score: 0.5}}}, "", true},
+		{"example score out of range", FewShotConfig{Examples: []FewShotExample{{Query: "q", Answer: "a", Score: 2}}}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EmbeddingProviderEnv, tt.provider)
+
+			fewShot := tt.fewShot
+			cfg := &JudgesConfig{
+				Judges: Judges{
+					Evaluators: []JudgeConfiguration{
+						{Name: "relevance", Prompt: "{{.Answer}}", FewShot: &fewShot},
+					},
+				},
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr && err == nil {
+				t.Error("Expected validation error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected validation error: %v", err)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
-	"text/template"
+	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,24 +19,93 @@ type JudgesConfig struct {
 // Judges contains default model config and list of evaluators
 type Judges struct {
 	DefaultModel ModelConfig          `yaml:"default_model"`
+	PartialsDir  string               `yaml:"partials_dir,omitempty"` // Shared prompt partials (*.tmpl), relative to the config file
 	Evaluators   []JudgeConfiguration `yaml:"evaluators"`
+
+	// Partials maps partial name to template text, loaded from PartialsDir
+	Partials map[string]string `yaml:"-"`
 }
 
 // JudgeConfiguration defines a single judge configuration
 type JudgeConfiguration struct {
-	Name            string       `yaml:"name"`
-	Enabled         bool         `yaml:"enabled"`
-	Description     string       `yaml:"description"`
-	RequiresContext bool         `yaml:"requires_context"`
-	Prompt          string       `yaml:"prompt"`
-	Model           *ModelConfig `yaml:"model,omitempty"` // Optional override
+	Name             string         `yaml:"name"`
+	Type             string         `yaml:"type,omitempty"`   // llm (default) or a registered code judge type, e.g. regex
+	Params           map[string]any `yaml:"params,omitempty"` // Parameters of code judges
+	Enabled          bool           `yaml:"enabled"`
+	Description      string         `yaml:"description"`
+	RequiresContext  bool           `yaml:"requires_context"`
+	Mode             string         `yaml:"mode,omitempty"`               // structured (default) or reasoning
+	PerChunk         bool           `yaml:"per_chunk,omitempty"`          // Judge each retrieved chunk (.Chunk) separately
+	ChunkAggregation string         `yaml:"chunk_aggregation,omitempty"`  // Per-chunk stage score: mean (default) or average_precision
+	PerStatement     bool           `yaml:"per_statement,omitempty"`      // Judge each reference statement (.Statement) separately
+	PerClaim         bool           `yaml:"per_claim,omitempty"`          // Decompose the answer into claims and verify each claim (.Claim)
+	MaxClaims        int            `yaml:"max_claims,omitempty"`         // Claims verified by per-claim judges, the others are skipped (default 20)
+	Concurrency      int            `yaml:"concurrency,omitempty"`        // Concurrent unit calls of per-chunk, per-statement and per-claim judges (default 4)
+	Timeout          time.Duration  `yaml:"timeout,omitempty"`            // Evaluation timeout (default 15s, per wave of concurrent unit calls of per-unit judges)
+	ClaimsPrompt     string         `yaml:"claims_prompt,omitempty"`      // Decomposition prompt of per-claim judges
+	ClaimsPromptFile string         `yaml:"claims_prompt_file,omitempty"` // Resolved into ClaimsPrompt by the loader
+	Prompt           string         `yaml:"prompt,omitempty"`
+	PromptFile       string         `yaml:"prompt_file,omitempty"` // Resolved into Prompt by the loader
+	Model            *ModelConfig   `yaml:"model,omitempty"`       // Optional override
+	FewShot          *FewShotConfig `yaml:"few_shot,omitempty"`    // Optional labeled examples exposed as .Examples
+}
+
+// JudgeTypeLLM is the judge type of prompt based LLM judges
+const JudgeTypeLLM = "llm"
+
+// IsLLM reports whether the judge is an LLM judge, as opposed to a code judge
+func (judge JudgeConfiguration) IsLLM() bool {
+	return judge.Type == "" || judge.Type == JudgeTypeLLM
 }
 
-// ModelConfig defines LLM model parameters
+// Unit returns the unit the judge prompt is rendered for: UnitChunk, UnitStatement
+// or UnitClaim, or "" for judges of the whole answer
+func (judge JudgeConfiguration) Unit() string {
+	switch {
+	case judge.PerChunk:
+		return UnitChunk
+	case judge.PerStatement:
+		return UnitStatement
+	case judge.PerClaim:
+		return UnitClaim
+	}
+	return ""
+}
+
+// Judge modes: structured judges answer with a JSON object only, reasoning judges
+// reason in free form and end with a delimited verdict block
+const (
+	JudgeModeStructured = "structured"
+	JudgeModeReasoning  = "reasoning"
+)
+
+// Chunk aggregations of per-chunk judges: the mean chunk score, or the rank-aware
+// average precision of the chunks judged relevant
+const (
+	ChunkAggregationMean             = "mean"
+	ChunkAggregationAveragePrecision = "average_precision"
+)
+
+// DefaultUnitConcurrency is the number of concurrent unit calls of per-unit judges
+const DefaultUnitConcurrency = 4
+
+// DefaultMaxClaims is the number of claims verified by per-claim judges
+const DefaultMaxClaims = 20
+
+// ModelConfig defines the LLM model and its parameters
 type ModelConfig struct {
-	MaxTokens   int     `yaml:"max_tokens,omitempty"`
-	Temperature float64 `yaml:"temperature,omitempty"`
-	Retry       bool    `yaml:"retry,omitempty"`
+	Provider    string     `yaml:"provider,omitempty"` // bedrock, openai or local (default: DEFAULT_LLM_PROVIDER)
+	ModelID     string     `yaml:"model_id,omitempty"` // Provider model ID (default: provider model from env)
+	MaxTokens   int        `yaml:"max_tokens,omitempty"`
+	Temperature float64    `yaml:"temperature,omitempty"`
+	Retry       bool       `yaml:"retry,omitempty"`
+	Fallbacks   []ModelRef `yaml:"fallbacks,omitempty"` // Tried in order when the model is throttled or unavailable
+}
+
+// ModelRef identifies a model of a provider
+type ModelRef struct {
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	ModelID  string `yaml:"model_id,omitempty" json:"model_id,omitempty"`
 }
 
 // LoadJudgesConfig loads and validates the judges configuration from YAML
@@ -53,6 +125,16 @@ func LoadJudgesConfig() (*JudgesConfig, error) {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
+	baseDir := filepath.Dir(path)
+
+	if err := loadPrompts(&cfg, baseDir); err != nil {
+		return nil, err
+	}
+
+	if err := loadFewShotExamples(&cfg, baseDir); err != nil {
+		return nil, err
+	}
+
 	applyDefaults(&cfg)
 
 	if err := cfg.Validate(); err != nil {
@@ -62,6 +144,65 @@ func LoadJudgesConfig() (*JudgesConfig, error) {
 	return &cfg, nil
 }
 
+// loadPrompts reads prompt_file references into Prompt and loads the shared partials.
+// Relative paths are resolved against baseDir.
+func loadPrompts(cfg *JudgesConfig, baseDir string) error {
+	if cfg.Judges.PartialsDir != "" {
+		dir, err := filepath.Abs(resolvePath(baseDir, cfg.Judges.PartialsDir))
+		if err != nil {
+			return fmt.Errorf("failed to resolve partials_dir: %w", err)
+		}
+
+		partials, err := loadPartials(dir)
+		if err != nil {
+			return err
+		}
+
+		cfg.Judges.PartialsDir = dir
+		cfg.Judges.Partials = partials
+	}
+
+	for i := range cfg.Judges.Evaluators {
+		judge := &cfg.Judges.Evaluators[i]
+		if err := loadPromptFile(judge.Name, "prompt", &judge.Prompt, &judge.PromptFile, baseDir); err != nil {
+			return err
+		}
+		if err := loadPromptFile(judge.Name, "claims_prompt", &judge.ClaimsPrompt, &judge.ClaimsPromptFile, baseDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadPromptFile reads the file of a <field>_file reference into the prompt
+func loadPromptFile(judgeName string, field string, prompt *string, file *string, baseDir string) error {
+	if *file == "" {
+		return nil
+	}
+
+	if *prompt != "" {
+		return fmt.Errorf("judge %s has both %s and %s_file", judgeName, field, field)
+	}
+
+	path := resolvePath(baseDir, *file)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("judge %s: failed to read %s file %s: %w", judgeName, field, path, err)
+	}
+
+	*prompt = string(data)
+	*file = ""
+	return nil
+}
+
+func resolvePath(baseDir string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
 func applyDefaults(cfg *JudgesConfig) {
 	if cfg.Judges.DefaultModel.MaxTokens == 0 {
 		cfg.Judges.DefaultModel.MaxTokens = 256
@@ -76,9 +217,12 @@ func applyDefaults(cfg *JudgesConfig) {
 
 		if judge.Model == nil {
 			judge.Model = &ModelConfig{
+				Provider:    cfg.Judges.DefaultModel.Provider,
+				ModelID:     cfg.Judges.DefaultModel.ModelID,
 				MaxTokens:   cfg.Judges.DefaultModel.MaxTokens,
 				Temperature: cfg.Judges.DefaultModel.Temperature,
 				Retry:       cfg.Judges.DefaultModel.Retry,
+				Fallbacks:   cfg.Judges.DefaultModel.Fallbacks,
 			}
 		} else {
 			if judge.Model.MaxTokens == 0 {
@@ -87,6 +231,18 @@ func applyDefaults(cfg *JudgesConfig) {
 			if judge.Model.Temperature == 0.0 {
 				judge.Model.Temperature = cfg.Judges.DefaultModel.Temperature
 			}
+			// A judge selecting neither provider nor model runs on the default model
+			if judge.Model.Provider == "" && judge.Model.ModelID == "" {
+				judge.Model.Provider = cfg.Judges.DefaultModel.Provider
+				judge.Model.ModelID = cfg.Judges.DefaultModel.ModelID
+			}
+			if judge.Model.Fallbacks == nil {
+				judge.Model.Fallbacks = cfg.Judges.DefaultModel.Fallbacks
+			}
+		}
+
+		if judge.FewShot != nil {
+			applyFewShotDefaults(judge.FewShot)
 		}
 	}
 }
@@ -108,11 +264,51 @@ func (cfg *JudgesConfig) Validate() error {
 		}
 		seen[judge.Name] = true
 
+		if !judge.IsLLM() {
+			if judge.Prompt != "" || judge.ClaimsPrompt != "" || judge.FewShot != nil || judge.Mode != "" || judge.PerChunk || judge.PerStatement || judge.PerClaim || judge.MaxClaims != 0 || judge.Concurrency != 0 || judge.Timeout != 0 {
+				return fmt.Errorf("judge %s of type %s only takes params, not prompts or LLM judge settings", judge.Name, judge.Type)
+			}
+			continue
+		}
+
+		if len(judge.Params) > 0 {
+			return fmt.Errorf("judge %s sets params, which only apply to code judges (type)", judge.Name)
+		}
+
 		if judge.Prompt == "" {
-			return fmt.Errorf("judge %s is missing prompt", judge.Name)
+			return fmt.Errorf("judge %s is missing prompt or prompt_file", judge.Name)
 		}
 
-		if _, err := template.New(judge.Name).Parse(judge.Prompt); err != nil {
+		switch judge.Mode {
+		case "", JudgeModeStructured, JudgeModeReasoning:
+		default:
+			return fmt.Errorf("judge %s has invalid mode: %s (must be %s or %s)", judge.Name, judge.Mode, JudgeModeStructured, JudgeModeReasoning)
+		}
+
+		if err := judge.validateUnits(cfg.Judges.Partials); err != nil {
+			return err
+		}
+
+		if judge.Timeout < 0 {
+			return fmt.Errorf("judge %s has negative timeout: %s", judge.Name, judge.Timeout)
+		}
+
+		switch judge.ChunkAggregation {
+		case "":
+		case ChunkAggregationMean, ChunkAggregationAveragePrecision:
+			if !judge.PerChunk {
+				return fmt.Errorf("judge %s sets chunk_aggregation without per_chunk", judge.Name)
+			}
+		default:
+			return fmt.Errorf("judge %s has invalid chunk_aggregation: %s (must be %s or %s)", judge.Name, judge.ChunkAggregation, ChunkAggregationMean, ChunkAggregationAveragePrecision)
+		}
+
+		tmpl, err := ParsePrompt(judge.Name, judge.Prompt, PartialsForMode(cfg.Judges.Partials, judge.Mode))
+		if err != nil {
+			return fmt.Errorf("judge %s has invalid prompt template: %w", judge.Name, err)
+		}
+
+		if err := DryRunPrompt(tmpl, judge.Unit()); err != nil {
 			return fmt.Errorf("judge %s has invalid prompt template: %w", judge.Name, err)
 		}
 
@@ -123,6 +319,17 @@ func (cfg *JudgesConfig) Validate() error {
 			if judge.Model.Temperature < 0.0 || judge.Model.Temperature > 1.0 {
 				return fmt.Errorf("judge %s has invalid temperature: %f (must be 0.0-1.0)", judge.Name, judge.Model.Temperature)
 			}
+			for j, fallback := range judge.Model.Fallbacks {
+				if fallback.Provider == "" && fallback.ModelID == "" {
+					return fmt.Errorf("judge %s fallback %d is missing provider and model_id", judge.Name, j)
+				}
+			}
+		}
+
+		if judge.FewShot != nil {
+			if err := judge.FewShot.validate(judge.Name); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -135,3 +342,123 @@ func (cfg *JudgesConfig) Validate() error {
 
 	return nil
 }
+
+// validateUnits checks the per-chunk, per-statement and per-claim settings
+func (judge JudgeConfiguration) validateUnits(partials map[string]string) error {
+	units := 0
+	for _, set := range []bool{judge.PerChunk, judge.PerStatement, judge.PerClaim} {
+		if set {
+			units++
+		}
+	}
+	if units > 1 {
+		return fmt.Errorf("judge %s can only set one of per_chunk, per_statement and per_claim", judge.Name)
+	}
+	if judge.Concurrency < 0 {
+		return fmt.Errorf("judge %s has negative concurrency: %d", judge.Name, judge.Concurrency)
+	}
+	if judge.Concurrency > 0 && units == 0 {
+		return fmt.Errorf("judge %s sets concurrency without per_chunk, per_statement or per_claim", judge.Name)
+	}
+
+	if !judge.PerClaim {
+		if judge.ClaimsPrompt != "" {
+			return fmt.Errorf("judge %s sets claims_prompt without per_claim", judge.Name)
+		}
+		if judge.MaxClaims != 0 {
+			return fmt.Errorf("judge %s sets max_claims without per_claim", judge.Name)
+		}
+		return nil
+	}
+
+	if judge.MaxClaims < 0 {
+		return fmt.Errorf("judge %s has negative max_claims: %d", judge.Name, judge.MaxClaims)
+	}
+
+	if judge.ClaimsPrompt == "" {
+		return fmt.Errorf("judge %s is missing claims_prompt or claims_prompt_file", judge.Name)
+	}
+	if judge.Mode == JudgeModeReasoning {
+		return fmt.Errorf("judge %s: per_claim judges do not support mode %s", judge.Name, JudgeModeReasoning)
+	}
+
+	tmpl, err := ParsePrompt(judge.Name+"-claims", judge.ClaimsPrompt, partials)
+	if err == nil {
+		err = DryRunPrompt(tmpl, "")
+	}
+	if err != nil {
+		return fmt.Errorf("judge %s has invalid claims prompt template: %w", judge.Name, err)
+	}
+	return nil
+}
+
+// WithJudgePrompt returns a copy of the configuration where the named judge uses
+// the given prompt. Other judges are left untouched.
+func (cfg *JudgesConfig) WithJudgePrompt(judgeName string, prompt string) (*JudgesConfig, error) {
+	clone := *cfg
+	clone.Judges.Evaluators = make([]JudgeConfiguration, len(cfg.Judges.Evaluators))
+	copy(clone.Judges.Evaluators, cfg.Judges.Evaluators)
+
+	for i := range clone.Judges.Evaluators {
+		if clone.Judges.Evaluators[i].Name == judgeName {
+			clone.Judges.Evaluators[i].Prompt = prompt
+			if err := clone.Validate(); err != nil {
+				return nil, err
+			}
+			return &clone, nil
+		}
+	}
+
+	return nil, fmt.Errorf("judge %s not found in config", judgeName)
+}
+
+// Judge returns the configuration of the named judge
+func (cfg *JudgesConfig) Judge(judgeName string) (JudgeConfiguration, bool) {
+	for _, judge := range cfg.Judges.Evaluators {
+		if judge.Name == judgeName {
+			return judge, true
+		}
+	}
+	return JudgeConfiguration{}, false
+}
+
+// Hash returns a fingerprint of the resolved configuration (prompts, partials,
+// few-shot examples and model settings). Two configurations with the same hash
+// evaluate identically, regardless of how they are split across files.
+func (cfg *JudgesConfig) Hash() (string, error) {
+	resolved := struct {
+		Judges   Judges            `yaml:"judges"`
+		Partials map[string]string `yaml:"partials,omitempty"`
+	}{
+		Judges:   cfg.Judges,
+		Partials: cfg.Judges.Partials,
+	}
+	resolved.Judges.PartialsDir = ""
+
+	data, err := yaml.Marshal(resolved)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal judges config: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// PromptHash returns a fingerprint of everything that shapes the judge prompt:
+// the templates, the partials they can reference and its few-shot examples
+func (judge JudgeConfiguration) PromptHash(partials map[string]string) string {
+	data, _ := yaml.Marshal(struct {
+		Prompt       string            `yaml:"prompt"`
+		ClaimsPrompt string            `yaml:"claims_prompt,omitempty"`
+		Partials     map[string]string `yaml:"partials,omitempty"`
+		FewShot      *FewShotConfig    `yaml:"few_shot,omitempty"`
+	}{
+		Prompt:       judge.Prompt,
+		ClaimsPrompt: judge.ClaimsPrompt,
+		Partials:     partials,
+		FewShot:      judge.FewShot,
+	})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
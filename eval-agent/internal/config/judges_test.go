@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadJudgesConfig_Success(t *testing.T) {
@@ -33,6 +34,7 @@ func TestLoadJudgesConfig_Success(t *testing.T) {
       enabled: true
       description: "Checks faithfulness"
       requires_context: true
+      timeout: 30s
       prompt: |
         Context: {{.Context}}
         Answer: {{.Answer}}
@@ -57,6 +59,9 @@ func TestLoadJudgesConfig_Success(t *testing.T) {
 	if len(cfg.Judges.Evaluators) != 2 {
 		t.Errorf("Expected 2 evaluators, got %d", len(cfg.Judges.Evaluators))
 	}
+	if timeout := cfg.Judges.Evaluators[1].Timeout; timeout != 30*time.Second {
+		t.Errorf("Expected faithfulness timeout 30s, got %s", timeout)
+	}
 
 	// Check default model
 	if cfg.Judges.DefaultModel.MaxTokens != 256 {
@@ -243,6 +248,160 @@ func TestValidate_MissingPrompt(t *testing.T) {
 	}
 }
 
+func TestValidate_InvalidMode(t *testing.T) {
+	cfg := &JudgesConfig{
+		Judges: Judges{
+			Evaluators: []JudgeConfiguration{
+				{
+					Name:   "test",
+					Prompt: "Score: {{.Answer}}",
+					Mode:   "freeform",
+				},
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error for invalid mode")
+	}
+
+	if !contains(err.Error(), "invalid mode: freeform") {
+		t.Errorf("Expected 'invalid mode' error, got: %v", err)
+	}
+}
+
+func TestValidate_JudgeType(t *testing.T) {
+	tests := []struct {
+		name    string
+		judge   JudgeConfiguration
+		wantErr string
+	}{
+		{
+			name:  "code judge without prompt",
+			judge: JudgeConfiguration{Name: "test", Type: "regex", Params: map[string]any{"pattern": "a"}},
+		},
+		{
+			name:  "explicit llm type",
+			judge: JudgeConfiguration{Name: "test", Type: JudgeTypeLLM, Prompt: "{{.Answer}}"},
+		},
+		{
+			name:    "code judge with prompt",
+			judge:   JudgeConfiguration{Name: "test", Type: "regex", Prompt: "{{.Answer}}"},
+			wantErr: "only takes params",
+		},
+		{
+			name:    "llm judge with params",
+			judge:   JudgeConfiguration{Name: "test", Prompt: "{{.Answer}}", Params: map[string]any{"pattern": "a"}},
+			wantErr: "only apply to code judges",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &JudgesConfig{Judges: Judges{Evaluators: []JudgeConfiguration{tt.judge}}}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %q error, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_JudgeUnits(t *testing.T) {
+	tests := []struct {
+		name    string
+		judge   JudgeConfiguration
+		wantErr string
+	}{
+		{
+			name:  "average precision",
+			judge: JudgeConfiguration{Name: "test", Prompt: "{{.Chunk.Content}}", PerChunk: true, ChunkAggregation: ChunkAggregationAveragePrecision},
+		},
+		{
+			name:    "invalid aggregation",
+			judge:   JudgeConfiguration{Name: "test", Prompt: "{{.Chunk.Content}}", PerChunk: true, ChunkAggregation: "max"},
+			wantErr: "invalid chunk_aggregation: max",
+		},
+		{
+			name:    "aggregation without per_chunk",
+			judge:   JudgeConfiguration{Name: "test", Prompt: "{{.Answer}}", ChunkAggregation: ChunkAggregationMean},
+			wantErr: "without per_chunk",
+		},
+		{
+			name:    "per_chunk and per_statement",
+			judge:   JudgeConfiguration{Name: "test", Prompt: "{{.Statement}}", PerChunk: true, PerStatement: true},
+			wantErr: "only set one of per_chunk, per_statement and per_claim",
+		},
+		{
+			name:  "per_claim",
+			judge: JudgeConfiguration{Name: "test", Prompt: "{{.Claim}}", PerClaim: true, ClaimsPrompt: "{{.Answer}}"},
+		},
+		{
+			name:    "per_claim without claims prompt",
+			judge:   JudgeConfiguration{Name: "test", Prompt: "{{.Claim}}", PerClaim: true},
+			wantErr: "missing claims_prompt",
+		},
+		{
+			name:    "invalid claims prompt",
+			judge:   JudgeConfiguration{Name: "test", Prompt: "{{.Claim}}", PerClaim: true, ClaimsPrompt: "{{.Missing}}"},
+			wantErr: "invalid claims prompt template",
+		},
+		{
+			name:    "per_claim reasoning",
+			judge:   JudgeConfiguration{Name: "test", Prompt: "{{.Claim}}", PerClaim: true, ClaimsPrompt: "{{.Answer}}", Mode: JudgeModeReasoning},
+			wantErr: "do not support mode reasoning",
+		},
+		{
+			name:  "concurrency and timeout",
+			judge: JudgeConfiguration{Name: "test", Prompt: "{{.Statement}}", PerStatement: true, Concurrency: 8, Timeout: time.Minute},
+		},
+		{
+			name:    "concurrency without units",
+			judge:   JudgeConfiguration{Name: "test", Prompt: "{{.Answer}}", Concurrency: 2},
+			wantErr: "sets concurrency without per_chunk",
+		},
+		{
+			name:  "max_claims",
+			judge: JudgeConfiguration{Name: "test", Prompt: "{{.Claim}}", PerClaim: true, ClaimsPrompt: "{{.Answer}}", MaxClaims: 5},
+		},
+		{
+			name:    "max_claims without per_claim",
+			judge:   JudgeConfiguration{Name: "test", Prompt: "{{.Answer}}", MaxClaims: 5},
+			wantErr: "sets max_claims without per_claim",
+		},
+		{
+			name:    "negative timeout",
+			judge:   JudgeConfiguration{Name: "test", Prompt: "{{.Answer}}", Timeout: -time.Second},
+			wantErr: "negative timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &JudgesConfig{Judges: Judges{Evaluators: []JudgeConfiguration{tt.judge}}}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %q error, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestValidate_InvalidPromptTemplate(t *testing.T) {
 	cfg := &JudgesConfig{
 		Judges: Judges{
@@ -451,3 +610,76 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestHash_ChangesWithResolvedContent(t *testing.T) {
+	cfg := &JudgesConfig{
+		Judges: Judges{
+			PartialsDir: "/tmp/partials",
+			Evaluators: []JudgeConfiguration{
+				{Name: "relevance", Enabled: true, Prompt: "Score: {{.Answer}} {{template \"out\"}}"},
+			},
+			Partials: map[string]string{"out": "json"},
+		},
+	}
+
+	hash, err := cfg.Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	moved := *cfg
+	moved.Judges.PartialsDir = "/srv/partials"
+	if movedHash, _ := moved.Hash(); movedHash != hash {
+		t.Error("Expected hash to ignore the partials directory location")
+	}
+
+	changedPartial := *cfg
+	changedPartial.Judges.Partials = map[string]string{"out": "yaml"}
+	if partialHash, _ := changedPartial.Hash(); partialHash == hash {
+		t.Error("Expected hash to change when a partial changes")
+	}
+
+	changedPrompt, err := cfg.WithJudgePrompt("relevance", "Rate: {{.Answer}} {{template \"out\"}}")
+	if err != nil {
+		t.Fatalf("WithJudgePrompt failed: %v", err)
+	}
+	if promptHash, _ := changedPrompt.Hash(); promptHash == hash {
+		t.Error("Expected hash to change when a prompt changes")
+	}
+}
+
+func TestApplyDefaults_ModelSelection(t *testing.T) {
+	fallbacks := []ModelRef{{Provider: "openai", ModelID: "gpt-4o"}}
+	cfg := &JudgesConfig{
+		Judges: Judges{
+			DefaultModel: ModelConfig{Provider: "bedrock", ModelID: "haiku", MaxTokens: 256, Fallbacks: fallbacks},
+			Evaluators: []JudgeConfiguration{
+				{Name: "inherits", Prompt: "p"},
+				{Name: "override", Prompt: "p", Model: &ModelConfig{Provider: "openai"}},
+				{Name: "no-fallbacks", Prompt: "p", Model: &ModelConfig{ModelID: "sonnet", Fallbacks: []ModelRef{}}},
+			},
+		},
+	}
+
+	applyDefaults(cfg)
+
+	inherits := cfg.Judges.Evaluators[0].Model
+	if inherits.Provider != "bedrock" || inherits.ModelID != "haiku" || len(inherits.Fallbacks) != 1 {
+		t.Errorf("Expected default model and fallbacks, got %+v", inherits)
+	}
+
+	override := cfg.Judges.Evaluators[1].Model
+	if override.Provider != "openai" || override.ModelID != "" || len(override.Fallbacks) != 1 {
+		t.Errorf("Expected provider override with its default model, got %+v", override)
+	}
+
+	noFallbacks := cfg.Judges.Evaluators[2].Model
+	if noFallbacks.Provider != "" || noFallbacks.ModelID != "sonnet" || len(noFallbacks.Fallbacks) != 0 {
+		t.Errorf("Expected explicit empty fallbacks to be kept, got %+v", noFallbacks)
+	}
+
+	cfg.Judges.Evaluators[0].Model.Fallbacks = []ModelRef{{}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for empty fallback")
+	}
+}
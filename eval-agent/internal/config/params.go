@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Params are the type specific parameters of a precheck or judge configuration
+type Params map[string]any
+
+// Float returns a numeric parameter, or def if it is not set
+func (p Params) Float(key string, def float64) (float64, error) {
+	value, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	default:
+		return 0, fmt.Errorf("param %s must be a number, got %T", key, value)
+	}
+}
+
+// Bool returns a boolean parameter, or def if it is not set
+func (p Params) Bool(key string, def bool) (bool, error) {
+	value, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("param %s must be a boolean, got %T", key, value)
+	}
+	return b, nil
+}
+
+// String returns a string parameter, or def if it is not set
+func (p Params) String(key string, def string) (string, error) {
+	value, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("param %s must be a string, got %T", key, value)
+	}
+	return s, nil
+}
+
+// Strings returns a list of strings parameter, or nil if it is not set
+func (p Params) Strings(key string) ([]string, error) {
+	value, ok := p[key]
+	if !ok {
+		return nil, nil
+	}
+
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("param %s must be a list, got %T", key, value)
+	}
+
+	values := make([]string, len(items))
+	for i, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("param %s[%d] must be a string, got %T", key, i, item)
+		}
+		values[i] = s
+	}
+	return values, nil
+}
+
+// Only returns an error for parameters other than the allowed ones, so that
+// misspelled parameters are not silently ignored
+func (p Params) Only(allowed ...string) error {
+	for key := range p {
+		if !slices.Contains(allowed, key) {
+			return fmt.Errorf("unknown param %s", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PrechecksConfig lists the checkers of the precheck stage
+type PrechecksConfig struct {
+	Prechecks []PrecheckConfiguration `yaml:"prechecks"`
+
+	// CategoryVerdicts caps the verdict of evaluations with a stage of the given
+	// category (e.g. refusal: review), whatever the confidence
+	CategoryVerdicts map[string]string `yaml:"category_verdicts,omitempty"`
+}
+
+// PrecheckConfiguration enables a checker by its registered type name
+type PrecheckConfiguration struct {
+	Type    string         `yaml:"type"`
+	Enabled bool           `yaml:"enabled"`
+	Weight  float64        `yaml:"weight,omitempty"` // Weight in the precheck average (default: 1)
+	Params  map[string]any `yaml:"params,omitempty"` // Checker specific parameters
+}
+
+// DefaultPrechecks returns the precheck stage used without a prechecks file
+func DefaultPrechecks() *PrechecksConfig {
+	cfg := &PrechecksConfig{
+		Prechecks: []PrecheckConfiguration{
+			{Type: "length", Enabled: true},
+			{Type: "overlap", Enabled: true, Params: map[string]any{"min_overlap": 0.3}},
+			{Type: "format", Enabled: true},
+		},
+	}
+	cfg.applyDefaults()
+	return cfg
+}
+
+// LoadPrechecks loads and validates the precheck stage configuration from YAML
+func LoadPrechecks(path string) (*PrechecksConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prechecks file %s: %w", path, err)
+	}
+
+	var cfg PrechecksConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	cfg.applyDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("prechecks validation failed: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+func (cfg *PrechecksConfig) applyDefaults() {
+	for i := range cfg.Prechecks {
+		if cfg.Prechecks[i].Weight == 0 {
+			cfg.Prechecks[i].Weight = 1
+		}
+	}
+}
+
+func (cfg *PrechecksConfig) Validate() error {
+	types := make(map[string]bool)
+	enabled := 0
+
+	for i, precheck := range cfg.Prechecks {
+		if precheck.Type == "" {
+			return fmt.Errorf("precheck at index %d is missing type", i)
+		}
+		if types[precheck.Type] {
+			return fmt.Errorf("duplicate precheck type: %s", precheck.Type)
+		}
+		types[precheck.Type] = true
+
+		if precheck.Weight < 0 {
+			return fmt.Errorf("precheck %s has negative weight: %f", precheck.Type, precheck.Weight)
+		}
+		if precheck.Enabled {
+			enabled++
+		}
+	}
+
+	if enabled == 0 {
+		return fmt.Errorf("no prechecks enabled")
+	}
+
+	for category, verdict := range cfg.CategoryVerdicts {
+		if verdict != "pass" && verdict != "review" && verdict != "fail" {
+			return fmt.Errorf("category %s has invalid verdict %q (must be pass, review or fail)", category, verdict)
+		}
+	}
+
+	return nil
+}
+
+// Enabled returns the enabled prechecks in configuration order
+func (cfg *PrechecksConfig) Enabled() []PrecheckConfiguration {
+	var enabled []PrecheckConfiguration
+	for _, precheck := range cfg.Prechecks {
+		if precheck.Enabled {
+			enabled = append(enabled, precheck)
+		}
+	}
+	return enabled
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPrechecks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prechecks.yaml")
+	content := `prechecks:
+  - type: length
+    enabled: true
+    params:
+      min_ratio: 0.2
+  - type: overlap
+    enabled: false
+  - type: format
+    enabled: true
+    weight: 2
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadPrechecks(path)
+	if err != nil {
+		t.Fatalf("LoadPrechecks failed: %v", err)
+	}
+
+	enabled := cfg.Enabled()
+	if len(enabled) != 2 || enabled[0].Type != "length" || enabled[1].Type != "format" {
+		t.Fatalf("Expected length and format enabled, got %+v", enabled)
+	}
+	if enabled[0].Weight != 1 || enabled[1].Weight != 2 {
+		t.Errorf("Expected default weight 1 and weight 2, got %v %v", enabled[0].Weight, enabled[1].Weight)
+	}
+	if enabled[0].Params["min_ratio"] != 0.2 {
+		t.Errorf("Expected min_ratio param, got %v", enabled[0].Params)
+	}
+}
+
+func TestPrechecksConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name             string
+		prechecks        []PrecheckConfiguration
+		categoryVerdicts map[string]string
+		wantErr          string
+	}{
+		{"missing type", []PrecheckConfiguration{{Enabled: true}}, nil, "missing type"},
+		{"duplicate type", []PrecheckConfiguration{{Type: "length", Enabled: true}, {Type: "length"}}, nil, "duplicate precheck type"},
+		{"negative weight", []PrecheckConfiguration{{Type: "length", Enabled: true, Weight: -1}}, nil, "negative weight"},
+		{"none enabled", []PrecheckConfiguration{{Type: "length"}}, nil, "no prechecks enabled"},
+		{"invalid category verdict", []PrecheckConfiguration{{Type: "refusal", Enabled: true}}, map[string]string{"refusal": "block"}, "invalid verdict"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &PrechecksConfig{Prechecks: tt.prechecks, CategoryVerdicts: tt.categoryVerdicts}
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %q error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if err := DefaultPrechecks().Validate(); err != nil {
+		t.Errorf("Expected default prechecks to be valid, got %v", err)
+	}
+}
+
+func TestLoadPrechecks_ConfigFile(t *testing.T) {
+	cfg, err := LoadPrechecks("../../configs/prechecks.yaml")
+	if err != nil {
+		t.Fatalf("LoadPrechecks failed: %v", err)
+	}
+	if len(cfg.Enabled()) != 3 {
+		t.Errorf("Expected the 3 default prechecks enabled, got %+v", cfg.Enabled())
+	}
+}
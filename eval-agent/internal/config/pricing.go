@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PricingConfig maps model IDs to token prices, used to turn judge token usage into cost
+type PricingConfig struct {
+	Models map[string]ModelPrice `yaml:"models"`
+}
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	InputPerMillion  float64 `yaml:"input_per_million"`
+	OutputPerMillion float64 `yaml:"output_per_million"`
+}
+
+// LoadPricing loads and validates a price table from YAML
+func LoadPricing(path string) (*PricingConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing file %s: %w", path, err)
+	}
+
+	var cfg PricingConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("pricing validation failed: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+func (cfg *PricingConfig) Validate() error {
+	for model, price := range cfg.Models {
+		if model == "" {
+			return fmt.Errorf("price with empty model ID")
+		}
+		if price.InputPerMillion < 0 || price.OutputPerMillion < 0 {
+			return fmt.Errorf("model %s has a negative price", model)
+		}
+	}
+	return nil
+}
+
+// Price returns the price of a model. An exact match wins; otherwise the longest
+// configured ID contained in the model ID is used, so "claude-3-5-haiku" prices
+// "us.anthropic.claude-3-5-haiku-20241022-v1:0" and "gpt-4o" prices "gpt-4o-2024-08-06".
+func (cfg *PricingConfig) Price(modelID string) (ModelPrice, bool) {
+	if cfg == nil || modelID == "" {
+		return ModelPrice{}, false
+	}
+
+	if price, ok := cfg.Models[modelID]; ok {
+		return price, true
+	}
+
+	var best string
+	for model := range cfg.Models {
+		if strings.Contains(modelID, model) && len(model) > len(best) {
+			best = model
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return cfg.Models[best], true
+}
+
+// Cost returns the cost in USD of the tokens, and false if the model has no price
+func (cfg *PricingConfig) Cost(modelID string, inputTokens int, outputTokens int) (float64, bool) {
+	price, ok := cfg.Price(modelID)
+	if !ok {
+		return 0, false
+	}
+	return (float64(inputTokens)*price.InputPerMillion + float64(outputTokens)*price.OutputPerMillion) / 1e6, true
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPricingConfig_Price(t *testing.T) {
+	pricing := &PricingConfig{Models: map[string]ModelPrice{
+		"claude-3-5-haiku": {InputPerMillion: 0.8, OutputPerMillion: 4},
+		"gpt-4o":           {InputPerMillion: 2.5, OutputPerMillion: 10},
+		"gpt-4o-mini":      {InputPerMillion: 0.15, OutputPerMillion: 0.6},
+	}}
+
+	tests := []struct {
+		modelID string
+		want    float64
+		found   bool
+	}{
+		{"gpt-4o", 2.5, true},
+		{"gpt-4o-2024-08-06", 2.5, true},
+		{"gpt-4o-mini-2024-07-18", 0.15, true}, // Longest match wins
+		{"us.anthropic.claude-3-5-haiku-20241022-v1:0", 0.8, true},
+		{"llama3.1:8b", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		price, found := pricing.Price(tt.modelID)
+		if found != tt.found || price.InputPerMillion != tt.want {
+			t.Errorf("Price(%q): got %v %v, want %v %v", tt.modelID, price.InputPerMillion, found, tt.want, tt.found)
+		}
+	}
+
+	cost, ok := pricing.Cost("gpt-4o", 1000, 200)
+	if !ok || math.Abs(cost-0.0045) > 1e-12 {
+		t.Errorf("Cost: got %v %v, want 0.0045", cost, ok)
+	}
+
+	var none *PricingConfig
+	if _, ok := none.Cost("gpt-4o", 1000, 200); ok {
+		t.Error("expected no price without a price table")
+	}
+}
+
+func TestLoadPricing(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "pricing.yaml")
+	os.WriteFile(valid, []byte("models:\n  gpt-4o:\n    input_per_million: 2.5\n    output_per_million: 10\n"), 0644)
+	pricing, err := LoadPricing(valid)
+	if err != nil {
+		t.Fatalf("LoadPricing failed: %v", err)
+	}
+	if pricing.Models["gpt-4o"].OutputPerMillion != 10 {
+		t.Errorf("unexpected pricing: %+v", pricing.Models)
+	}
+
+	negative := filepath.Join(dir, "negative.yaml")
+	os.WriteFile(negative, []byte("models:\n  gpt-4o:\n    input_per_million: -1\n"), 0644)
+	if _, err := LoadPricing(negative); err == nil {
+		t.Error("expected error for negative price")
+	}
+
+	if _, err := LoadPricing(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestDefaultPricingFile(t *testing.T) {
+	if _, err := LoadPricing("../../configs/pricing.yaml"); err != nil {
+		t.Errorf("default pricing file is invalid: %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// PromptData is the data passed to judge prompt templates. The retrieved chunks are
+// available as .Chunks; per-chunk judges also get the chunk being judged as .Chunk
+// and its 1-based rank as .Rank, per-statement judges the reference statement being
+// judged as .Statement and per-claim judges the answer claim being verified as .Claim.
+// .Mode is the judge mode, structured or reasoning.
+type PromptData struct {
+	models.EvaluationContext
+	Mode      string
+	Examples  []FewShotExample
+	Chunk     *models.ContextChunk
+	Rank      int
+	Statement string
+	Claim     string
+}
+
+// Prompts ask for the verdict format with the output partial. Reasoning judges
+// render the reasoning output partial in its place, so that one prompt serves
+// both modes.
+const (
+	OutputPartial          = "json_output"
+	ReasoningOutputPartial = "verdict_output"
+)
+
+// defaultReasoningOutput is the reasoning output partial of configurations whose
+// partials do not define one
+//
+//go:embed partials/verdict_output.tmpl
+var defaultReasoningOutput string
+
+// ReasoningOutput returns the reasoning output partial of the partials, or the
+// built-in one
+func ReasoningOutput(partials map[string]string) string {
+	if reasoning, ok := partials[ReasoningOutputPartial]; ok {
+		return reasoning
+	}
+	return defaultReasoningOutput
+}
+
+// PartialsForMode returns the partials of a judge in the mode. In reasoning mode
+// the output partial is replaced by the reasoning output partial.
+func PartialsForMode(partials map[string]string, mode string) map[string]string {
+	if mode != JudgeModeReasoning {
+		return partials
+	}
+
+	selected := maps.Clone(partials)
+	if selected == nil {
+		selected = make(map[string]string, 1)
+	}
+	selected[OutputPartial] = ReasoningOutput(partials)
+	return selected
+}
+
+// VerdictInstruction renders the reasoning output partial of the partials, for
+// reasoning prompts that do not ask for the verdict block themselves
+func VerdictInstruction(partials map[string]string) (string, error) {
+	tmpl, err := ParsePrompt(ReasoningOutputPartial, ReasoningOutput(partials), nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid partial %s: %w", ReasoningOutputPartial, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return "", fmt.Errorf("invalid partial %s: %w", ReasoningOutputPartial, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// PromptFuncs returns the helper functions available in judge prompt templates:
+//
+//	{{.Context | truncate 2000}}   keep the first N whitespace-delimited tokens
+//	{{join ", " .Items}}           join a list of strings
+//	{{.Context | indent 4}}        indent every line by N spaces
+//	{{.Answer | jsonEscape}}       escape a string for use inside a JSON string literal
+//	{{range $i, $c := .Chunks}}[{{inc $i}}] {{$c.Content}}{{end}}   number the retrieved chunks from 1
+func PromptFuncs() template.FuncMap {
+	return template.FuncMap{
+		"truncate":   truncateTokens,
+		"join":       join,
+		"indent":     indent,
+		"jsonEscape": jsonEscape,
+		"inc":        inc,
+	}
+}
+
+// ParsePrompt parses a judge prompt with the helper functions and shared partials.
+// Each partial is available to the prompt as {{template "<name>"}}.
+func ParsePrompt(name string, text string, partials map[string]string) (*template.Template, error) {
+	tmpl := template.New(name).Funcs(PromptFuncs())
+
+	for partialName, partial := range partials {
+		if _, err := tmpl.New(partialName).Parse(partial); err != nil {
+			return nil, fmt.Errorf("invalid partial %s: %w", partialName, err)
+		}
+	}
+
+	if _, err := tmpl.Parse(text); err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+// Units of the per-unit judges, whose prompt is rendered once per unit
+const (
+	UnitChunk     = "chunk"
+	UnitStatement = "statement"
+	UnitClaim     = "claim"
+)
+
+// dryRunData is the sample of the fields every prompt gets
+type dryRunData struct {
+	models.EvaluationContext
+	Mode     string
+	Examples []FewShotExample
+}
+
+// The samples of the per-unit prompts add the field of their unit only, so that
+// a prompt using the field of another unit fails the dry run
+type chunkDryRunData struct {
+	dryRunData
+	Chunk *models.ContextChunk
+	Rank  int
+}
+
+type statementDryRunData struct {
+	dryRunData
+	Statement string
+}
+
+type claimDryRunData struct {
+	dryRunData
+	Claim string
+}
+
+// DryRunPrompt renders the template against a sample context so that references
+// to missing fields and broken partials are reported at load time instead of
+// on the first evaluation. unit is the unit the prompt is rendered for (UnitChunk,
+// UnitStatement or UnitClaim), or "" for prompts of the whole answer.
+func DryRunPrompt(tmpl *template.Template, unit string) error {
+	chunk := models.ContextChunk{ID: "sample", Content: "sample context", Score: 1.0, Source: "sample source", Metadata: map[string]any{}}
+	base := dryRunData{
+		EvaluationContext: models.EvaluationContext{
+			RequestID: "dry-run",
+			Query:     "sample query",
+			Context:   "sample context",
+			Contexts:  []models.ContextChunk{chunk},
+			Answer:    "sample answer",
+			Reference: "sample reference",
+			CreatedAt: time.Now(),
+		},
+		Mode: JudgeModeStructured,
+		Examples: []FewShotExample{
+			{Query: "example query", Answer: "example answer", Context: "example context", Score: 1.0, Reason: "example reason"},
+		},
+	}
+
+	var sample any = base
+	switch unit {
+	case UnitChunk:
+		sample = chunkDryRunData{dryRunData: base, Chunk: &chunk, Rank: 1}
+	case UnitStatement:
+		sample = statementDryRunData{dryRunData: base, Statement: "sample statement"}
+	case UnitClaim:
+		sample = claimDryRunData{dryRunData: base, Claim: "sample claim"}
+	}
+
+	if err := tmpl.Option("missingkey=error").Execute(&bytes.Buffer{}, sample); err != nil {
+		return fmt.Errorf("dry-render failed: %w", err)
+	}
+
+	return nil
+}
+
+// loadPartials reads every *.tmpl file of the directory as a partial named after the file
+func loadPartials(dir string) (map[string]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partials in %s: %w", dir, err)
+	}
+
+	partials := make(map[string]string, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read partial %s: %w", path, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		partials[name] = strings.TrimRight(string(data), "\n")
+	}
+
+	return partials, nil
+}
+
+func truncateTokens(n int, s string) string {
+	tokens := strings.Fields(s)
+	if n < 0 || len(tokens) <= n {
+		return s
+	}
+	return strings.Join(tokens[:n], " ") + " ...[truncated]"
+}
+
+func inc(i int) int {
+	return i + 1
+}
+
+func join(sep string, items []string) string {
+	return strings.Join(items, sep)
+}
+
+func indent(n int, s string) string {
+	if n <= 0 {
+		return s
+	}
+	pad := strings.Repeat(" ", n)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+func jsonEscape(s string) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		return "", err
+	}
+	encoded := strings.TrimSuffix(buf.String(), "\n")
+	return encoded[1 : len(encoded)-1], nil
+}
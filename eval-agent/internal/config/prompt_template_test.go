@@ -0,0 +1,236 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPromptFuncs(t *testing.T) {
+	tests := []struct {
+		name     string
+		prompt   string
+		data     any
+		expected string
+	}{
+		{"truncate", `{{. | truncate 3}}`, "one two three four five", "one two three ...[truncated]"},
+		{"truncate short", `{{. | truncate 10}}`, "one two", "one two"},
+		{"join", `{{join ", " .}}`, []string{"a", "b", "c"}, "a, b, c"},
+		{"indent", `{{. | indent 2}}`, "line1\nline2", "  line1\n  line2"},
+		{"jsonEscape", `{"reason": "{{. | jsonEscape}}"}`, "say \"hi\" <now>\n", `{"reason": "say \"hi\" <now>\n"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParsePrompt(tt.name, tt.prompt, nil)
+			if err != nil {
+				t.Fatalf("ParsePrompt failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, tt.data); err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestParsePrompt_Partials(t *testing.T) {
+	partials := map[string]string{
+		"json_output": `{"score": <float>, "reason": "<{{if .}}{{.}}{{else}}string{{end}}>"}`,
+	}
+
+	tmpl, err := ParsePrompt("judge", `Answer: {{.Answer}}
+{{template "json_output"}}
+{{template "json_output" "why"}}`, partials)
+	if err != nil {
+		t.Fatalf("ParsePrompt failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, PromptData{}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"reason": "<string>"`) || !strings.Contains(buf.String(), `"reason": "<why>"`) {
+		t.Errorf("Partial not rendered: %q", buf.String())
+	}
+}
+
+func TestDryRunPrompt(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  string
+		unit    string
+		wantErr bool
+	}{
+		{"valid fields", "{{.Query}} {{.Context}} {{.Answer}}", "", false},
+		{"examples", "{{range .Examples}}{{.Query}} {{.Score}} {{.Reason}}{{end}}", "", false},
+		{"chunks", "{{range .Chunks}}{{.Content}}{{end}}", "", false},
+		{"missing field", "{{.Question}}", "", true},
+		{"missing example field", "{{range .Examples}}{{.Label}}{{end}}", "", true},
+		{"missing partial", `{{template "nope"}}`, "", true},
+		{"chunk of a per-chunk judge", "{{.Rank}} {{.Chunk.Content}} {{.Query}}", UnitChunk, false},
+		{"statement of a per-statement judge", "{{.Statement}} {{.Context}}", UnitStatement, false},
+		{"claim of a per-claim judge", "{{.Claim}} {{.Context}}", UnitClaim, false},
+		{"chunk of a whole-answer judge", "{{.Chunk.Content}}", "", true},
+		{"claim of a whole-answer judge", "{{.Claim}}", "", true},
+		{"statement of a per-chunk judge", "{{.Statement}}", UnitChunk, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParsePrompt(tt.name, tt.prompt, nil)
+			if err != nil {
+				t.Fatalf("ParsePrompt failed: %v", err)
+			}
+
+			err = DryRunPrompt(tmpl, tt.unit)
+			if tt.wantErr && err == nil {
+				t.Error("Expected dry-render error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected dry-render error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadJudgesConfig_PromptFileAndPartials(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "judges.yaml")
+
+	files := map[string]string{
+		"judges.yaml": `judges:
+  partials_dir: prompts/partials
+  evaluators:
+    - name: relevance
+      enabled: true
+      prompt_file: prompts/relevance.tmpl
+    - name: claims
+      enabled: true
+      per_claim: true
+      claims_prompt_file: prompts/claims.tmpl
+      prompt: "Claim: {{.Claim}}"
+`,
+		"prompts/relevance.tmpl":            "Answer: {{.Answer}}\n{{template \"json_output\"}}\n",
+		"prompts/claims.tmpl":               "List the claims of: {{.Answer}}\n",
+		"prompts/partials/json_output.tmpl": "Respond ONLY in raw JSON\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	os.Setenv("JUDGES_CONFIG_PATH", configPath)
+	defer os.Unsetenv("JUDGES_CONFIG_PATH")
+
+	cfg, err := LoadJudgesConfig()
+	if err != nil {
+		t.Fatalf("LoadJudgesConfig() failed: %v", err)
+	}
+
+	judge := cfg.Judges.Evaluators[0]
+	if judge.Prompt != files["prompts/relevance.tmpl"] {
+		t.Errorf("Expected prompt loaded from file, got %q", judge.Prompt)
+	}
+	if judge.PromptFile != "" {
+		t.Errorf("Expected prompt_file to be resolved, got %q", judge.PromptFile)
+	}
+	if claims := cfg.Judges.Evaluators[1]; claims.ClaimsPrompt != files["prompts/claims.tmpl"] || claims.ClaimsPromptFile != "" {
+		t.Errorf("Expected claims prompt loaded from file, got %q", claims.ClaimsPrompt)
+	}
+	if cfg.Judges.Partials["json_output"] != "Respond ONLY in raw JSON" {
+		t.Errorf("Expected json_output partial, got %q", cfg.Judges.Partials["json_output"])
+	}
+	if !filepath.IsAbs(cfg.Judges.PartialsDir) {
+		t.Errorf("Expected absolute partials_dir, got %q", cfg.Judges.PartialsDir)
+	}
+}
+
+func TestLoadJudgesConfig_PromptAndPromptFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "judges.yaml")
+
+	configContent := `judges:
+  evaluators:
+    - name: relevance
+      prompt: "{{.Answer}}"
+      prompt_file: relevance.tmpl
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	os.Setenv("JUDGES_CONFIG_PATH", configPath)
+	defer os.Unsetenv("JUDGES_CONFIG_PATH")
+
+	_, err := LoadJudgesConfig()
+	if err == nil || !strings.Contains(err.Error(), "both prompt and prompt_file") {
+		t.Errorf("Expected error for prompt and prompt_file, got %v", err)
+	}
+}
+
+func TestValidate_DryRenderMissingField(t *testing.T) {
+	cfg := &JudgesConfig{
+		Judges: Judges{
+			Evaluators: []JudgeConfiguration{
+				{Name: "relevance", Prompt: "Question: {{.Question}}"},
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "relevance") {
+		t.Errorf("Expected dry-render error mentioning judge, got %v", err)
+	}
+}
+
+func TestPartialsForMode(t *testing.T) {
+	partials := map[string]string{OutputPartial: "json", ReasoningOutputPartial: "verdict"}
+
+	if got := PartialsForMode(partials, JudgeModeStructured); got[OutputPartial] != "json" {
+		t.Errorf("Expected the JSON output partial in structured mode, got %q", got[OutputPartial])
+	}
+	if got := PartialsForMode(partials, JudgeModeReasoning); got[OutputPartial] != "verdict" {
+		t.Errorf("Expected the verdict output partial in reasoning mode, got %q", got[OutputPartial])
+	}
+	if partials[OutputPartial] != "json" {
+		t.Error("Expected the shared partials to be left untouched")
+	}
+
+	jsonOnly := map[string]string{OutputPartial: "json"}
+	if got := PartialsForMode(jsonOnly, JudgeModeReasoning); got[OutputPartial] != defaultReasoningOutput {
+		t.Errorf("Expected the built-in verdict output partial without a reasoning partial, got %q", got[OutputPartial])
+	}
+	if got := PartialsForMode(nil, JudgeModeReasoning); got[OutputPartial] != defaultReasoningOutput {
+		t.Errorf("Expected the built-in verdict output partial without partials, got %q", got[OutputPartial])
+	}
+}
+
+func TestVerdictInstruction(t *testing.T) {
+	instruction, err := VerdictInstruction(nil)
+	if err != nil {
+		t.Fatalf("VerdictInstruction failed: %v", err)
+	}
+	if !strings.HasPrefix(instruction, "Think through") || !strings.HasSuffix(instruction, "</verdict>") || !strings.Contains(instruction, "one sentence summary") {
+		t.Errorf("Expected the built-in verdict instruction, got %q", instruction)
+	}
+
+	instruction, err = VerdictInstruction(map[string]string{ReasoningOutputPartial: "End with <verdict>{{if .}}{{.}}{{else}}json{{end}}</verdict>"})
+	if err != nil || instruction != "End with <verdict>json</verdict>" {
+		t.Errorf("Expected the configured verdict instruction, got %q (%v)", instruction, err)
+	}
+}
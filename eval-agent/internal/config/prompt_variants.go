@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PromptVariantsConfig lists candidate prompts for a single judge, used by the
+// batch CLI optimization mode to search for a better prompt
+type PromptVariantsConfig struct {
+	Judge    string          `yaml:"judge"`
+	Variants []PromptVariant `yaml:"variants"`
+}
+
+// PromptVariant is one candidate prompt template for a judge
+type PromptVariant struct {
+	Name   string `yaml:"name"`
+	Prompt string `yaml:"prompt"`
+}
+
+// LoadPromptVariants loads and validates prompt variants from a YAML file
+func LoadPromptVariants(path string) (*PromptVariantsConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variants file %s: %w", path, err)
+	}
+
+	var cfg PromptVariantsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("variants validation failed: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+func (cfg *PromptVariantsConfig) Validate() error {
+	seen := make(map[string]bool)
+
+	for i, variant := range cfg.Variants {
+		if variant.Name == "" {
+			return fmt.Errorf("variant at index %d is missing name", i)
+		}
+
+		if seen[variant.Name] {
+			return fmt.Errorf("duplicate variant name: %s", variant.Name)
+		}
+		seen[variant.Name] = true
+
+		if variant.Prompt == "" {
+			return fmt.Errorf("variant %s is missing prompt", variant.Name)
+		}
+
+		if _, err := ParsePrompt(variant.Name, variant.Prompt, nil); err != nil {
+			return fmt.Errorf("variant %s has invalid prompt template: %w", variant.Name, err)
+		}
+	}
+
+	return nil
+}
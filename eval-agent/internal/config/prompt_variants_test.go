@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPromptVariants_Success(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "variants.yaml")
+
+	content := `judge: relevance
+variants:
+  - name: strict
+    prompt: |
+      Be strict. Query: {{.Query}} Answer: {{.Answer}}
+  - name: lenient
+    prompt: |
+      Be lenient. Answer: {{.Answer}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write variants file: %v", err)
+	}
+
+	cfg, err := LoadPromptVariants(path)
+	if err != nil {
+		t.Fatalf("LoadPromptVariants() failed: %v", err)
+	}
+
+	if cfg.Judge != "relevance" {
+		t.Errorf("Expected judge 'relevance', got '%s'", cfg.Judge)
+	}
+	if len(cfg.Variants) != 2 || cfg.Variants[0].Name != "strict" {
+		t.Errorf("Unexpected variants: %+v", cfg.Variants)
+	}
+}
+
+func TestPromptVariantsConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		variants []PromptVariant
+	}{
+		{"missing name", []PromptVariant{{Prompt: "{{.Answer}}"}}},
+		{"duplicate name", []PromptVariant{{Name: "a", Prompt: "x"}, {Name: "a", Prompt: "y"}}},
+		{"missing prompt", []PromptVariant{{Name: "a"}}},
+		{"invalid template", []PromptVariant{{Name: "a", Prompt: "{{.Answer"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := PromptVariantsConfig{Judge: "relevance", Variants: tt.variants}
+			if err := cfg.Validate(); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestJudgesConfig_WithJudgePrompt(t *testing.T) {
+	cfg := &JudgesConfig{
+		Judges: Judges{
+			Evaluators: []JudgeConfiguration{
+				{Name: "relevance", Prompt: "original {{.Answer}}"},
+				{Name: "coherence", Prompt: "coherence {{.Answer}}"},
+			},
+		},
+	}
+
+	updated, err := cfg.WithJudgePrompt("relevance", "variant {{.Answer}}")
+	if err != nil {
+		t.Fatalf("WithJudgePrompt failed: %v", err)
+	}
+
+	judge, _ := updated.Judge("relevance")
+	if judge.Prompt != "variant {{.Answer}}" {
+		t.Errorf("Expected variant prompt, got '%s'", judge.Prompt)
+	}
+
+	// Original config is not modified
+	original, _ := cfg.Judge("relevance")
+	if original.Prompt != "original {{.Answer}}" {
+		t.Errorf("Original config was modified: '%s'", original.Prompt)
+	}
+
+	if _, err := cfg.WithJudgePrompt("missing", "x"); err == nil {
+		t.Error("Expected error for unknown judge")
+	}
+	if _, err := cfg.WithJudgePrompt("relevance", "{{.Answer"); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+}
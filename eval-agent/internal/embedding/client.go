@@ -0,0 +1,33 @@
+package embedding
+
+import (
+	"context"
+	"math"
+)
+
+// Client is an interface for computing text embeddings
+// This allows mocking in tests without making real API calls
+type Client interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, or 0 if either is empty
+// or they have different dimensions
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,77 @@
+package embedding
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// DefaultLocalDimensions is the vector size of the local client
+const DefaultLocalDimensions = 256
+
+// LocalClient is a deterministic stand-in for an embedding model. It hashes the
+// words of a text and their character trigrams into a fixed-size vector, so texts
+// sharing words or word stems are similar. It needs no network access and is meant
+// for tests, offline runs and development.
+type LocalClient struct {
+	Dimensions int
+}
+
+func NewLocalClient(dimensions int) *LocalClient {
+	if dimensions <= 0 {
+		dimensions = DefaultLocalDimensions
+	}
+	return &LocalClient{Dimensions: dimensions}
+}
+
+// Embed returns one normalized vector per text
+func (c *LocalClient) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = c.embed(text)
+	}
+	return vectors, nil
+}
+
+func (c *LocalClient) embed(text string) []float64 {
+	vector := make([]float64, c.Dimensions)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		c.add(vector, word, 1.0)
+
+		padded := []rune("^" + word + "$")
+		for i := 0; i+3 <= len(padded); i++ {
+			c.add(vector, string(padded[i:i+3]), 0.5)
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vector {
+			vector[i] /= norm
+		}
+	}
+	return vector
+}
+
+// add hashes the feature to a dimension; one hash bit selects the sign so that
+// collisions tend to cancel out
+func (c *LocalClient) add(vector []float64, feature string, weight float64) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	if sum&(1<<63) != 0 {
+		weight = -weight
+	}
+	vector[sum%uint64(c.Dimensions)] += weight
+}
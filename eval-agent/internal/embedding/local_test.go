@@ -0,0 +1,46 @@
+package embedding
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestLocalClient_Embed(t *testing.T) {
+	client := NewLocalClient(0)
+
+	vectors, err := client.Embed(context.Background(), []string{
+		"How do I reverse a list in Python?",
+		"How do I reverse a list in Python?",
+		"To reverse a Python list, call reverse() on the list.",
+		"The boiling point of water is 100 degrees.",
+	})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	if len(vectors) != 4 || len(vectors[0]) != DefaultLocalDimensions {
+		t.Fatalf("Expected 4 vectors of %d dimensions, got %d", DefaultLocalDimensions, len(vectors))
+	}
+
+	if similarity := CosineSimilarity(vectors[0], vectors[1]); math.Abs(similarity-1) > 1e-9 {
+		t.Errorf("Expected identical texts to have similarity 1, got %f", similarity)
+	}
+
+	related := CosineSimilarity(vectors[0], vectors[2])
+	unrelated := CosineSimilarity(vectors[0], vectors[3])
+	if related <= unrelated {
+		t.Errorf("Expected related texts to be closer (%f) than unrelated texts (%f)", related, unrelated)
+	}
+}
+
+func TestLocalClient_EmptyText(t *testing.T) {
+	vectors, err := NewLocalClient(8).Embed(context.Background(), []string{""})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	if CosineSimilarity(vectors[0], vectors[0]) != 0 {
+		t.Errorf("Expected zero vector for empty text, got %v", vectors[0])
+	}
+}
@@ -2,7 +2,13 @@ package executor
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 )
@@ -17,16 +23,46 @@ type JudgeRunner interface {
 	Run(ctx context.Context, evalCtx models.EvaluationContext) []models.StageResult
 }
 
+// VersionedJudgeRunner is implemented by judge runners whose configuration can be
+// reloaded at runtime. The executor stamps the reported hash on every result.
+type VersionedJudgeRunner interface {
+	RunVersioned(ctx context.Context, evalCtx models.EvaluationContext) ([]models.StageResult, string)
+	ConfigHash() string
+}
+
+// SelectiveJudgeRunner is implemented by judge runners that can run a subset of
+// their judges. Select resolves the names against the active configuration and
+// returns its hash.
+type SelectiveJudgeRunner interface {
+	Select(judges []string) (*judge.JudgeRunner, string, error)
+}
+
 // Aggregator aggregates stage results into final evaluation
 type Aggregator interface {
 	Aggregate(id string, stage1 []models.StageResult, stage2 []models.StageResult) models.EvaluationResult
 }
 
+// OptionsAggregator is implemented by aggregators that accept per-request weights
+// and verdict thresholds
+type OptionsAggregator interface {
+	AggregateWithOptions(id string, stage1 []models.StageResult, stage2 []models.StageResult, options models.EvaluationOptions) models.EvaluationResult
+}
+
+// ErrInvalidOptions is returned for evaluation options the pipeline cannot honor,
+// e.g. a judge that is not enabled
+var ErrInvalidOptions = errors.New("invalid evaluation options")
+
+// PipelineRevision is part of every pipeline version. Bump it when a code change
+// alters scores for an unchanged configuration (e.g. aggregation or precheck logic).
+const PipelineRevision = 2
+
 type Executor struct {
 	precheckStageRunner PrecheckRunner
 	judgeRunner         JudgeRunner
 	aggregator          Aggregator
 	earlyExitThreshold  float64
+	precheckWeights     map[string]float64
+	pipeline            *models.PipelineInfo
 	logger              *zerolog.Logger
 }
 
@@ -46,7 +82,65 @@ func NewExecutor(
 	}
 }
 
+// WithPipeline sets the pipeline description stamped on every result. The early
+// exit threshold is taken from the executor and the version is computed per result,
+// as it depends on the judges configuration in use.
+func (e *Executor) WithPipeline(weights models.AggregationWeights, prechecks []string) *Executor {
+	e.pipeline = &models.PipelineInfo{
+		Weights:            weights,
+		Prechecks:          prechecks,
+		EarlyExitThreshold: e.earlyExitThreshold,
+	}
+	return e
+}
+
+// WithPrecheckConfig sets the precheck weights used for the early exit and records
+// the weights and checker parameters in the pipeline description. Prechecks missing
+// from weights count with weight 1.
+func (e *Executor) WithPrecheckConfig(weights map[string]float64, params map[string]any) *Executor {
+	e.precheckWeights = weights
+	if e.pipeline != nil {
+		if !uniform(weights) {
+			e.pipeline.PrecheckWeights = weights
+		}
+		if len(params) > 0 {
+			e.pipeline.PrecheckParams = params
+		}
+	}
+	return e
+}
+
+// WithCategoryVerdicts records the verdict caps per stage category, applied by
+// the aggregator, in the pipeline description
+func (e *Executor) WithCategoryVerdicts(verdicts map[string]models.Verdict) *Executor {
+	if e.pipeline != nil && len(verdicts) > 0 {
+		e.pipeline.CategoryVerdicts = verdicts
+	}
+	return e
+}
+
+// uniform reports whether all weights are equal, in which case they do not change
+// the average
+func uniform(weights map[string]float64) bool {
+	first := -1.0
+	for _, weight := range weights {
+		if first >= 0 && weight != first {
+			return false
+		}
+		first = weight
+	}
+	return true
+}
+
 func (e *Executor) Execute(ctx context.Context, evalCtx models.EvaluationContext) models.EvaluationResult {
+	result, _ := e.ExecuteWithOptions(ctx, evalCtx, nil)
+	return result
+}
+
+// ExecuteWithOptions runs the pipeline with the overrides of one request; nil
+// options run the configured pipeline. The pipeline description of the result
+// records the overrides applied.
+func (e *Executor) ExecuteWithOptions(ctx context.Context, evalCtx models.EvaluationContext, options *models.EvaluationOptions) (models.EvaluationResult, error) {
 	id := evalCtx.RequestID
 	e.logger.Info().Str("requestID", id).Msg("starting evaluation")
 
@@ -56,36 +150,133 @@ func (e *Executor) Execute(ctx context.Context, evalCtx models.EvaluationContext
 		Confidence: 0,
 		Verdict:    "",
 	}
+	if options == nil {
+		options = &models.EvaluationOptions{}
+	}
 
-	stageEvalResults := e.precheckStageRunner.Run(evalCtx)
+	versioned, isVersioned := e.judgeRunner.(VersionedJudgeRunner)
+	if isVersioned {
+		result.ConfigHash = versioned.ConfigHash()
+	}
 
-	if len(stageEvalResults) == 0 {
-		result.Verdict = models.VerdictFail
-		return result
+	// Resolve the judges before the prechecks run, so that the set (and its
+	// configuration hash) is fixed for the whole evaluation
+	var selected *judge.JudgeRunner
+	if len(options.Judges) > 0 {
+		selective, ok := e.judgeRunner.(SelectiveJudgeRunner)
+		if !ok {
+			return result, fmt.Errorf("%w: judge selection is not supported", ErrInvalidOptions)
+		}
+		var err error
+		if selected, result.ConfigHash, err = selective.Select(options.Judges); err != nil {
+			return result, fmt.Errorf("%w: %v", ErrInvalidOptions, err)
+		}
 	}
 
-	stageEvalScore := 0.0
-	for _, stageEval := range stageEvalResults {
-		stageEvalScore += stageEval.Score
+	overridesAggregation := options.Weights != nil || options.Thresholds != nil || options.SkipPrechecks || options.ExpectedRefusal
+	optionsAggregator, ok := e.aggregator.(OptionsAggregator)
+	if overridesAggregation && !ok {
+		return result, fmt.Errorf("%w: aggregation overrides are not supported", ErrInvalidOptions)
 	}
+	result.Pipeline = e.pipelineInfo(result.ConfigHash, options)
 
-	stageEvalAvgScore := stageEvalScore / float64(len(stageEvalResults))
+	var stageEvalResults []models.StageResult
+	if !options.SkipPrechecks {
+		stageEvalResults = e.precheckStageRunner.Run(evalCtx)
 
-	if stageEvalAvgScore < e.earlyExitThreshold {
-		result.Stages = append(result.Stages, stageEvalResults...)
-		result.Verdict = models.VerdictFail
-		e.logger.Info().Float64("avgScore", stageEvalAvgScore).Msg("early exit triggered")
+		if len(stageEvalResults) == 0 {
+			result.Verdict = models.VerdictFail
+			return result, nil
+		}
 
-		return result
+		stageEvalAvgScore := models.AverageScore(stageEvalResults, e.precheckWeights)
+
+		vetoed := models.Vetoes(stageEvalResults)
+		if options.EarlyExitEnabled() && (stageEvalAvgScore < e.earlyExitThreshold || len(vetoed) > 0) {
+			result.Stages = append(result.Stages, stageEvalResults...)
+			result.Usage = models.TotalUsage(result.Stages)
+			result.Verdict = models.VerdictFail
+			e.logger.Info().
+				Float64("avgScore", stageEvalAvgScore).
+				Strs("vetoedBy", vetoed).
+				Msg("early exit triggered")
+
+			return result, nil
+		}
 	}
 
-	judgeEvaResults := e.judgeRunner.Run(ctx, evalCtx)
+	var judgeEvaResults []models.StageResult
+	configHash := result.ConfigHash
+	switch {
+	case selected != nil:
+		judgeEvaResults = selected.Run(ctx, evalCtx)
+	case isVersioned:
+		judgeEvaResults, configHash = versioned.RunVersioned(ctx, evalCtx)
+	default:
+		judgeEvaResults = e.judgeRunner.Run(ctx, evalCtx)
+	}
 
-	finalResult := e.aggregator.Aggregate(id, stageEvalResults, judgeEvaResults)
+	var finalResult models.EvaluationResult
+	if overridesAggregation {
+		finalResult = optionsAggregator.AggregateWithOptions(id, stageEvalResults, judgeEvaResults, *options)
+	} else {
+		finalResult = e.aggregator.Aggregate(id, stageEvalResults, judgeEvaResults)
+	}
+	finalResult.ConfigHash = configHash
+	finalResult.Pipeline = e.pipelineInfo(configHash, options)
+	finalResult.Usage = models.TotalUsage(finalResult.Stages)
 	e.logger.
 		Info().
 		Str("verdict", string(finalResult.Verdict)).
 		Float64("confidence", result.Confidence).
 		Msg("evaluation complete")
-	return finalResult
+	return finalResult, nil
+}
+
+// Config returns the configured pipeline for the active judges configuration
+func (e *Executor) Config() models.PipelineConfig {
+	cfg := models.PipelineConfig{Thresholds: models.DefaultVerdictThresholds}
+	if versioned, ok := e.judgeRunner.(VersionedJudgeRunner); ok {
+		cfg.ConfigHash = versioned.ConfigHash()
+	}
+	cfg.Pipeline = e.pipelineInfo(cfg.ConfigHash, nil)
+	return cfg
+}
+
+// pipelineInfo returns the pipeline description, with the overrides of the
+// options applied and the version computed for the judges configuration, or nil
+// when no pipeline description was set
+func (e *Executor) pipelineInfo(configHash string, options *models.EvaluationOptions) *models.PipelineInfo {
+	if e.pipeline == nil {
+		return nil
+	}
+
+	info := *e.pipeline
+	if options != nil {
+		info.Judges = options.Judges
+		info.Thresholds = options.Thresholds
+		if options.Weights != nil {
+			info.Weights = *options.Weights
+		}
+		info.EarlyExitDisabled = !options.EarlyExitEnabled()
+		info.ExpectedRefusal = options.ExpectedRefusal
+		if options.SkipPrechecks {
+			info.SkipPrechecks = true
+			info.Weights = models.AggregationWeights{PreChecks: 0, LLMJudge: 1}
+			info.Prechecks = []string{}
+			info.PrecheckWeights = nil
+			info.PrecheckParams = nil
+			info.EarlyExitThreshold = 0
+		}
+	}
+
+	data, _ := json.Marshal(struct {
+		Revision   int                 `json:"revision"`
+		Pipeline   models.PipelineInfo `json:"pipeline"`
+		ConfigHash string              `json:"config_hash"`
+	}{PipelineRevision, info, configHash})
+
+	sum := sha256.Sum256(data)
+	info.Version = hex.EncodeToString(sum[:])[:12]
+	return &info
 }
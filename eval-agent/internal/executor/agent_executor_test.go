@@ -2,10 +2,15 @@ package executor
 
 import (
 	"context"
+	"errors"
+	"math"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/aggregator"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor/mocks"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 	"go.uber.org/mock/gomock"
@@ -104,6 +109,58 @@ func TestExecutor_Execute_EarlyExit_LowScore(t *testing.T) {
 	}
 }
 
+func TestExecutor_Execute_EarlyExit_WeightedPrechecks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	mockJudge := mocks.NewMockJudgeRunner(ctrl)
+	mockAgg := mocks.NewMockAggregator(ctrl)
+
+	evalCtx := models.EvaluationContext{RequestID: "test-weights", Query: "test", Answer: "a"}
+
+	// Unweighted avg = 0.5 would continue; format weighted 3x gives 0.125 < 0.2
+	precheckResults := []models.StageResult{
+		{Name: "format", Score: 0.0},
+		{Name: "length", Score: 0.5},
+	}
+	mockPrecheck.EXPECT().Run(evalCtx).Return(precheckResults)
+
+	executor := NewExecutor(mockPrecheck, mockJudge, mockAgg, 0.2, newTestLogger()).
+		WithPrecheckConfig(map[string]float64{"format": 3, "length": 1}, nil)
+
+	result := executor.Execute(context.Background(), evalCtx)
+	if result.Verdict != models.VerdictFail || len(result.Stages) != 2 {
+		t.Errorf("expected weighted early exit, got %+v", result)
+	}
+}
+
+func TestExecutor_Execute_EarlyExit_Veto(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	mockJudge := mocks.NewMockJudgeRunner(ctrl)
+	mockAgg := mocks.NewMockAggregator(ctrl)
+
+	evalCtx := models.EvaluationContext{RequestID: "test-veto", Query: "test", Answer: "a"}
+
+	// A veto skips the judges even with a high average score
+	precheckResults := []models.StageResult{
+		{Name: "format", Score: 1.0},
+		{Name: "length", Score: 1.0},
+		{Name: "pii-leakage", Score: 0.0, Veto: true},
+	}
+	mockPrecheck.EXPECT().Run(evalCtx).Return(precheckResults)
+
+	executor := NewExecutor(mockPrecheck, mockJudge, mockAgg, 0.2, newTestLogger())
+
+	result := executor.Execute(context.Background(), evalCtx)
+	if result.Verdict != models.VerdictFail || len(result.Stages) != 3 {
+		t.Errorf("expected vetoed early exit, got %+v", result)
+	}
+}
+
 func TestExecutor_Execute_EmptyPrechecks_Fail(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -240,3 +297,337 @@ func TestExecutor_Execute_EarlyExitThreshold(t *testing.T) {
 		})
 	}
 }
+
+type versionedRunnerStub struct {
+	results []models.StageResult
+	hash    string
+}
+
+func (r *versionedRunnerStub) Run(ctx context.Context, evalCtx models.EvaluationContext) []models.StageResult {
+	return r.results
+}
+
+func (r *versionedRunnerStub) RunVersioned(ctx context.Context, evalCtx models.EvaluationContext) ([]models.StageResult, string) {
+	return r.results, r.hash
+}
+
+func (r *versionedRunnerStub) ConfigHash() string {
+	return r.hash
+}
+
+func TestExecutor_Execute_StampsConfigHash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	mockAgg := mocks.NewMockAggregator(ctrl)
+
+	evalCtx := models.EvaluationContext{RequestID: "test-hash", Query: "q", Answer: "a", CreatedAt: time.Now()}
+
+	precheckResults := []models.StageResult{{Name: "length", Score: 0.8}}
+	judgeResults := []models.StageResult{{Name: "relevance", Score: 0.9}}
+	runner := &versionedRunnerStub{results: judgeResults, hash: "abc123"}
+
+	mockPrecheck.EXPECT().Run(evalCtx).Return(precheckResults)
+	mockAgg.EXPECT().Aggregate("test-hash", precheckResults, judgeResults).
+		Return(models.EvaluationResult{ID: "test-hash", Verdict: models.VerdictPass})
+
+	result := NewExecutor(mockPrecheck, runner, mockAgg, 0.2, newTestLogger()).Execute(context.Background(), evalCtx)
+	if result.ConfigHash != "abc123" {
+		t.Errorf("expected config hash abc123, got %q", result.ConfigHash)
+	}
+
+	// Early exit results carry the active configuration as well
+	mockPrecheck.EXPECT().Run(evalCtx).Return([]models.StageResult{{Name: "length", Score: 0.0}})
+
+	result = NewExecutor(mockPrecheck, runner, mockAgg, 0.2, newTestLogger()).Execute(context.Background(), evalCtx)
+	if result.Verdict != models.VerdictFail || result.ConfigHash != "abc123" {
+		t.Errorf("expected early exit fail with config hash abc123, got %s %q", result.Verdict, result.ConfigHash)
+	}
+}
+
+func TestExecutor_Execute_StampsPipeline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	mockAgg := mocks.NewMockAggregator(ctrl)
+
+	evalCtx := models.EvaluationContext{RequestID: "test-pipeline", Query: "q", Answer: "a", CreatedAt: time.Now()}
+	weights := models.AggregationWeights{PreChecks: 0.3, LLMJudge: 0.7}
+	prechecks := []string{"length-checker", "format-checker"}
+
+	run := func(hash string) *models.PipelineInfo {
+		mockPrecheck.EXPECT().Run(evalCtx).Return([]models.StageResult{{Name: "length-checker", Score: 0.8}})
+		mockAgg.EXPECT().Aggregate("test-pipeline", gomock.Any(), gomock.Any()).
+			Return(models.EvaluationResult{ID: "test-pipeline", Verdict: models.VerdictPass})
+
+		runner := &versionedRunnerStub{results: []models.StageResult{{Name: "relevance", Score: 0.9}}, hash: hash}
+		exec := NewExecutor(mockPrecheck, runner, mockAgg, 0.2, newTestLogger()).WithPipeline(weights, prechecks)
+		return exec.Execute(context.Background(), evalCtx).Pipeline
+	}
+
+	first := run("hash-1")
+	if first == nil {
+		t.Fatal("expected pipeline info on result")
+	}
+	if first.Weights != weights || len(first.Prechecks) != 2 || first.EarlyExitThreshold != 0.2 {
+		t.Errorf("unexpected pipeline info: %+v", first)
+	}
+	if first.Version == "" {
+		t.Error("expected pipeline version")
+	}
+
+	if again := run("hash-1"); again.Version != first.Version {
+		t.Errorf("expected stable version, got %s and %s", first.Version, again.Version)
+	}
+	if reloaded := run("hash-2"); reloaded.Version == first.Version {
+		t.Error("expected version to change with the judges configuration")
+	}
+}
+
+func TestExecutor_Execute_TotalsUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	mockJudge := mocks.NewMockJudgeRunner(ctrl)
+	mockAgg := mocks.NewMockAggregator(ctrl)
+
+	evalCtx := models.EvaluationContext{RequestID: "test-usage", Query: "q", Answer: "a", CreatedAt: time.Now()}
+
+	precheckResults := []models.StageResult{{Name: "length", Score: 0.8}}
+	judgeResults := []models.StageResult{
+		{Name: "relevance", Score: 0.9, Usage: &models.TokenUsage{InputTokens: 1000, OutputTokens: 100, CostUSD: 0.0045}},
+		{Name: "faithfulness", Score: 0.8, Usage: &models.TokenUsage{InputTokens: 500, OutputTokens: 50, CostUSD: 0.002}},
+	}
+
+	mockPrecheck.EXPECT().Run(evalCtx).Return(precheckResults)
+	mockJudge.EXPECT().Run(gomock.Any(), evalCtx).Return(judgeResults)
+	mockAgg.EXPECT().Aggregate("test-usage", precheckResults, judgeResults).
+		Return(models.EvaluationResult{
+			ID:      "test-usage",
+			Stages:  append(append([]models.StageResult{}, precheckResults...), judgeResults...),
+			Verdict: models.VerdictPass,
+		})
+
+	result := NewExecutor(mockPrecheck, mockJudge, mockAgg, 0.2, newTestLogger()).Execute(context.Background(), evalCtx)
+	if result.Usage == nil {
+		t.Fatal("expected total usage on result")
+	}
+	if result.Usage.InputTokens != 1500 || result.Usage.OutputTokens != 150 || result.Usage.CostUSD != 0.0065 {
+		t.Errorf("unexpected total usage: %+v", *result.Usage)
+	}
+
+	// Results without LLM calls carry no usage
+	mockPrecheck.EXPECT().Run(evalCtx).Return([]models.StageResult{{Name: "length", Score: 0.0}})
+	result = NewExecutor(mockPrecheck, mockJudge, mockAgg, 0.2, newTestLogger()).Execute(context.Background(), evalCtx)
+	if result.Usage != nil {
+		t.Errorf("expected no usage on early exit, got %+v", *result.Usage)
+	}
+}
+
+type scoreJudge struct {
+	name  string
+	score float64
+}
+
+func (j *scoreJudge) Name() string { return j.name }
+
+func (j *scoreJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContext) models.StageResult {
+	return models.StageResult{Name: j.name + "-judge", Score: j.score}
+}
+
+// selectiveRunnerStub serves its judges like the reloadable judges of a configuration
+type selectiveRunnerStub struct {
+	versionedRunnerStub
+	runner *judge.JudgeRunner
+}
+
+func (r *selectiveRunnerStub) Select(names []string) (*judge.JudgeRunner, string, error) {
+	selected, err := r.runner.Select(names)
+	return selected, r.hash, err
+}
+
+func newSelectiveRunnerStub(judges ...judge.Judge) *selectiveRunnerStub {
+	runner := judge.NewJudgeRunner(judges, newTestLogger())
+	return &selectiveRunnerStub{
+		versionedRunnerStub: versionedRunnerStub{results: runner.Run(context.Background(), models.EvaluationContext{}), hash: "abc123"},
+		runner:              runner,
+	}
+}
+
+func TestExecutor_ExecuteWithOptions_SelectsJudges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	mockAgg := mocks.NewMockAggregator(ctrl)
+	runner := newSelectiveRunnerStub(&scoreJudge{name: "relevance", score: 0.9}, &scoreJudge{name: "coherence", score: 0.1})
+
+	evalCtx := models.EvaluationContext{RequestID: "test-select", Query: "q", Answer: "a", CreatedAt: time.Now()}
+	precheckResults := []models.StageResult{{Name: "length", Score: 0.8}}
+	judgeResults := []models.StageResult{{Name: "relevance-judge", Score: 0.9}}
+
+	mockPrecheck.EXPECT().Run(evalCtx).Return(precheckResults)
+	mockAgg.EXPECT().Aggregate("test-select", precheckResults, judgeResults).
+		Return(models.EvaluationResult{ID: "test-select", Verdict: models.VerdictPass})
+
+	exec := NewExecutor(mockPrecheck, runner, mockAgg, 0.2, newTestLogger()).
+		WithPipeline(models.AggregationWeights{PreChecks: 0.3, LLMJudge: 0.7}, []string{"length"})
+
+	result, err := exec.ExecuteWithOptions(context.Background(), evalCtx, &models.EvaluationOptions{Judges: []string{"relevance"}})
+	if err != nil {
+		t.Fatalf("ExecuteWithOptions failed: %v", err)
+	}
+	if result.ConfigHash != "abc123" {
+		t.Errorf("expected config hash abc123, got %q", result.ConfigHash)
+	}
+	if len(result.Pipeline.Judges) != 1 || result.Pipeline.Judges[0] != "relevance" {
+		t.Errorf("expected the selected judges on the pipeline, got %+v", result.Pipeline)
+	}
+	if result.Pipeline.Version == exec.pipelineInfo("abc123", nil).Version {
+		t.Error("expected the judge selection to change the pipeline version")
+	}
+}
+
+func TestExecutor_ExecuteWithOptions_UnknownJudge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Neither the prechecks nor the aggregator run for a rejected selection
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	mockAgg := mocks.NewMockAggregator(ctrl)
+	runner := newSelectiveRunnerStub(&scoreJudge{name: "relevance", score: 0.9})
+
+	evalCtx := models.EvaluationContext{RequestID: "test-unknown", Query: "q", Answer: "a"}
+	exec := NewExecutor(mockPrecheck, runner, mockAgg, 0.2, newTestLogger())
+
+	_, err := exec.ExecuteWithOptions(context.Background(), evalCtx, &models.EvaluationOptions{Judges: []string{"tone"}})
+	if !errors.Is(err, ErrInvalidOptions) || !strings.Contains(err.Error(), "tone") {
+		t.Errorf("expected ErrInvalidOptions naming the judge, got %v", err)
+	}
+
+	// Runners without judge selection reject it as well
+	_, err = NewExecutor(mockPrecheck, mocks.NewMockJudgeRunner(ctrl), mockAgg, 0.2, newTestLogger()).
+		ExecuteWithOptions(context.Background(), evalCtx, &models.EvaluationOptions{Judges: []string{"relevance"}})
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("expected ErrInvalidOptions, got %v", err)
+	}
+}
+
+func TestExecutor_ExecuteWithOptions_SkipPrechecks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The precheck mock expects no call
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	runner := newSelectiveRunnerStub(&scoreJudge{name: "relevance", score: 0.9}, &scoreJudge{name: "coherence", score: 0.7})
+	agg := aggregator.NewAggregator(aggregator.Weights{PreChecks: 0.3, LLMJudge: 0.7}, newTestLogger())
+
+	evalCtx := models.EvaluationContext{RequestID: "test-skip", Query: "q", Answer: "a"}
+	exec := NewExecutor(mockPrecheck, runner, agg, 0.2, newTestLogger()).
+		WithPipeline(models.AggregationWeights{PreChecks: 0.3, LLMJudge: 0.7}, []string{"length"}).
+		WithPrecheckConfig(map[string]float64{"length": 2, "format": 1}, nil)
+
+	result, err := exec.ExecuteWithOptions(context.Background(), evalCtx, &models.EvaluationOptions{
+		SkipPrechecks: true,
+		Thresholds:    &models.VerdictThresholds{Pass: 0.75, Review: 0.5},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWithOptions failed: %v", err)
+	}
+	if math.Abs(result.Confidence-0.8) > 1e-9 || result.Verdict != models.VerdictPass {
+		t.Errorf("expected Pass on the judge average 0.8, got %s (%f)", result.Verdict, result.Confidence)
+	}
+
+	pipeline := result.Pipeline
+	if !pipeline.SkipPrechecks || len(pipeline.Prechecks) != 0 || pipeline.PrecheckWeights != nil {
+		t.Errorf("expected the pipeline to record the skipped prechecks, got %+v", pipeline)
+	}
+	if pipeline.Weights != (models.AggregationWeights{PreChecks: 0, LLMJudge: 1}) {
+		t.Errorf("expected effective weights 0/1, got %+v", pipeline.Weights)
+	}
+	if pipeline.Thresholds == nil || pipeline.Thresholds.Pass != 0.75 {
+		t.Errorf("expected the thresholds on the pipeline, got %+v", pipeline.Thresholds)
+	}
+}
+
+func TestExecutor_ExecuteWithOptions_ExpectedRefusal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	runner := newSelectiveRunnerStub(&scoreJudge{name: "relevance", score: 0.9})
+	agg := aggregator.NewAggregator(aggregator.Weights{PreChecks: 0.3, LLMJudge: 0.7}, newTestLogger()).
+		WithCategoryVerdicts(map[string]models.Verdict{"refusal": models.VerdictReview})
+
+	evalCtx := models.EvaluationContext{RequestID: "test-refusal", Query: "q", Answer: "I can't help with that."}
+	mockPrecheck.EXPECT().Run(evalCtx).Return([]models.StageResult{{Name: "refusal-checker", Score: 1.0, Category: "refusal"}}).Times(2)
+	exec := NewExecutor(mockPrecheck, runner, agg, 0.2, newTestLogger()).
+		WithPipeline(models.AggregationWeights{PreChecks: 0.3, LLMJudge: 0.7}, []string{"refusal-checker"})
+
+	capped, _ := exec.ExecuteWithOptions(context.Background(), evalCtx, nil)
+	if capped.Verdict != models.VerdictReview {
+		t.Errorf("expected the refusal to be capped at review, got %s", capped.Verdict)
+	}
+
+	result, err := exec.ExecuteWithOptions(context.Background(), evalCtx, &models.EvaluationOptions{ExpectedRefusal: true})
+	if err != nil {
+		t.Fatalf("ExecuteWithOptions failed: %v", err)
+	}
+	if result.Verdict != models.VerdictPass {
+		t.Errorf("expected an expected refusal to pass, got %s (%f)", result.Verdict, result.Confidence)
+	}
+	if !result.Pipeline.ExpectedRefusal || result.Pipeline.Version == capped.Pipeline.Version {
+		t.Errorf("expected the pipeline to record the expected refusal, got %+v", result.Pipeline)
+	}
+}
+
+func TestExecutor_ExecuteWithOptions_NoEarlyExit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	mockJudge := mocks.NewMockJudgeRunner(ctrl)
+	mockAgg := mocks.NewMockAggregator(ctrl)
+
+	evalCtx := models.EvaluationContext{RequestID: "test-no-exit", Query: "q", Answer: "a"}
+
+	// A score below the threshold still runs the judges
+	precheckResults := []models.StageResult{{Name: "length", Score: 0.1}}
+	judgeResults := []models.StageResult{{Name: "relevance", Score: 0.9}}
+	mockPrecheck.EXPECT().Run(evalCtx).Return(precheckResults)
+	mockJudge.EXPECT().Run(gomock.Any(), evalCtx).Return(judgeResults)
+	mockAgg.EXPECT().Aggregate("test-no-exit", precheckResults, judgeResults).
+		Return(models.EvaluationResult{ID: "test-no-exit", Verdict: models.VerdictFail})
+
+	earlyExit := false
+	exec := NewExecutor(mockPrecheck, mockJudge, mockAgg, 0.2, newTestLogger()).
+		WithPipeline(models.AggregationWeights{PreChecks: 0.3, LLMJudge: 0.7}, []string{"length"})
+
+	result, err := exec.ExecuteWithOptions(context.Background(), evalCtx, &models.EvaluationOptions{EarlyExit: &earlyExit})
+	if err != nil {
+		t.Fatalf("ExecuteWithOptions failed: %v", err)
+	}
+	if !result.Pipeline.EarlyExitDisabled {
+		t.Error("expected the pipeline to record the disabled early exit")
+	}
+}
+
+func TestExecutor_Config(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	runner := &versionedRunnerStub{hash: "abc123"}
+	exec := NewExecutor(mocks.NewMockPrecheckRunner(ctrl), runner, mocks.NewMockAggregator(ctrl), 0.2, newTestLogger()).
+		WithPipeline(models.AggregationWeights{PreChecks: 0.3, LLMJudge: 0.7}, []string{"length"})
+
+	cfg := exec.Config()
+	if cfg.ConfigHash != "abc123" || cfg.Thresholds != models.DefaultVerdictThresholds {
+		t.Errorf("Expected the active hash and default thresholds, got %+v", cfg)
+	}
+	if cfg.Pipeline == nil || cfg.Pipeline.EarlyExitThreshold != 0.2 || cfg.Pipeline.Version != exec.pipelineInfo("abc123", nil).Version {
+		t.Errorf("Expected the pipeline of the results, got %+v", cfg.Pipeline)
+	}
+}
@@ -13,6 +13,12 @@ type JudgeFactory interface {
 	Get(judgeName string) (judge.Judge, error)
 }
 
+// VersionedJudgeFactory is implemented by judge factories whose configuration can
+// be reloaded at runtime. The executor stamps the reported hash on every result.
+type VersionedJudgeFactory interface {
+	GetVersioned(judgeName string) (judge.Judge, string, error)
+}
+
 type JudgeExecutor struct {
 	judges JudgeFactory
 	logger *zerolog.Logger
@@ -36,7 +42,13 @@ func (e *JudgeExecutor) Execute(ctx context.Context, judgeName string, threshold
 		Stages: []models.StageResult{},
 	}
 
-	judge, err := e.judges.Get(judgeName)
+	var judge judge.Judge
+	var err error
+	if versioned, ok := e.judges.(VersionedJudgeFactory); ok {
+		judge, result.ConfigHash, err = versioned.GetVersioned(judgeName)
+	} else {
+		judge, err = e.judges.Get(judgeName)
+	}
 	if err != nil {
 		e.logger.Error().Err(err).Str("judgeName", judgeName).Msg("Judge not found")
 		return result, ErrJudgeNotFound
@@ -51,6 +63,7 @@ func (e *JudgeExecutor) Execute(ctx context.Context, judgeName string, threshold
 		result.Verdict = models.VerdictFail
 	}
 	result.Confidence = judgeResponse.Score
+	result.Usage = judgeResponse.Usage
 
 	return result, nil
 }
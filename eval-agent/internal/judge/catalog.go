@@ -0,0 +1,111 @@
+package judge
+
+import (
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+)
+
+// Catalog lists the judges of one judges configuration, enabled or not
+type Catalog struct {
+	Version ConfigVersion `json:"version" description:"Judges configuration the catalog was built from"`
+	Judges  []JudgeInfo   `json:"judges" description:"Judges in configuration order"`
+}
+
+// JudgeInfo describes a configured judge
+type JudgeInfo struct {
+	Name            string         `json:"name" description:"Judge name, as accepted by the evaluate endpoints"`
+	Type            string         `json:"type" description:"llm or a code judge type, e.g. regex"`
+	Description     string         `json:"description,omitempty" description:"What the judge evaluates"`
+	Enabled         bool           `json:"enabled" description:"Whether the judge runs in evaluations"`
+	RequiresContext bool           `json:"requires_context" description:"Whether the judge needs the retrieved context"`
+	Mode            string         `json:"mode,omitempty" description:"structured or reasoning (LLM judges)"`
+	Unit            string         `json:"unit,omitempty" description:"chunk, statement or claim for judges scoring each unit separately"`
+	Model           *ModelInfo     `json:"model,omitempty" description:"Model settings (LLM judges)"`
+	PromptHash      string         `json:"prompt_hash,omitempty" description:"Fingerprint of the prompt, as in the stage fingerprint (LLM judges)"`
+	Params          map[string]any `json:"params,omitempty" description:"Type specific parameters (built-in code judges other than exec)"`
+}
+
+// ModelInfo are the model settings of an LLM judge. An empty provider and model
+// select the service default model.
+type ModelInfo struct {
+	Provider    string            `json:"provider,omitempty" description:"bedrock, openai or local"`
+	ModelID     string            `json:"model_id,omitempty" description:"Provider model ID"`
+	MaxTokens   int               `json:"max_tokens" description:"Output token limit"`
+	Temperature float64           `json:"temperature" description:"Sampling temperature"`
+	Retry       bool              `json:"retry" description:"Whether failed calls are retried"`
+	Fallbacks   []config.ModelRef `json:"fallbacks,omitempty" description:"Models tried in order when the model is unavailable"`
+}
+
+// publicParamTypes are the code judge types whose params are published. The
+// params of exec judges hold command lines and paths, and those of types
+// registered by the deployment may hold anything.
+var publicParamTypes = map[string]bool{
+	"regex":        true,
+	"json_schema":  true,
+	"contains":     true,
+	"not_contains": true,
+	"numeric":      true,
+}
+
+// NewCatalog describes the judges of the configuration
+func NewCatalog(cfg *config.JudgesConfig, version ConfigVersion) Catalog {
+	catalog := Catalog{
+		Version: version,
+		Judges:  make([]JudgeInfo, 0, len(cfg.Judges.Evaluators)),
+	}
+
+	for _, judgeCfg := range cfg.Judges.Evaluators {
+		info := JudgeInfo{
+			Name:            judgeCfg.Name,
+			Type:            judgeCfg.Type,
+			Description:     judgeCfg.Description,
+			Enabled:         judgeCfg.Enabled,
+			RequiresContext: judgeCfg.RequiresContext,
+		}
+
+		if !judgeCfg.IsLLM() {
+			if publicParamTypes[judgeCfg.Type] {
+				info.Params = judgeCfg.Params
+			}
+			catalog.Judges = append(catalog.Judges, info)
+			continue
+		}
+
+		info.Type = config.JudgeTypeLLM
+		info.Mode = judgeCfg.Mode
+		if info.Mode == "" {
+			info.Mode = config.JudgeModeStructured
+		}
+		switch {
+		case judgeCfg.PerChunk:
+			info.Unit = "chunk"
+		case judgeCfg.PerStatement:
+			info.Unit = "statement"
+		case judgeCfg.PerClaim:
+			info.Unit = "claim"
+		}
+		if model := judgeCfg.Model; model != nil {
+			info.Model = &ModelInfo{
+				Provider:    model.Provider,
+				ModelID:     model.ModelID,
+				MaxTokens:   model.MaxTokens,
+				Temperature: model.Temperature,
+				Retry:       model.Retry,
+				Fallbacks:   model.Fallbacks,
+			}
+		}
+		info.PromptHash = judgeCfg.PromptHash(cfg.Judges.Partials)
+		catalog.Judges = append(catalog.Judges, info)
+	}
+
+	return catalog
+}
+
+// Judge returns the catalog entry of the named judge
+func (c Catalog) Judge(name string) (JudgeInfo, bool) {
+	for _, info := range c.Judges {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return JudgeInfo{}, false
+}
@@ -0,0 +1,65 @@
+package judge
+
+import (
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+)
+
+func TestNewCatalog(t *testing.T) {
+	relevance := config.JudgeConfiguration{
+		Name:        "relevance",
+		Enabled:     true,
+		Description: "Answer addresses the query",
+		Prompt:      "Query: {{.Query}}",
+		Model:       &config.ModelConfig{Provider: "openai", ModelID: "gpt-4o", MaxTokens: 256, Retry: true},
+	}
+	cfg := &config.JudgesConfig{
+		Judges: config.Judges{
+			Evaluators: []config.JudgeConfiguration{
+				relevance,
+				{Name: "context-precision", RequiresContext: true, PerChunk: true, Mode: config.JudgeModeReasoning, Prompt: "{{.Chunk.Content}}"},
+				{Name: "no-apology", Type: "not_contains", Enabled: true, Params: map[string]any{"values": []any{"sorry"}}},
+				{Name: "lint", Type: "exec", Enabled: true, Params: map[string]any{"command": []any{"/opt/checks/lint", "--token", "s3cret"}}},
+			},
+		},
+	}
+
+	catalog := NewCatalog(cfg, ConfigVersion{Version: 3, Hash: "abc123"})
+	if catalog.Version.Version != 3 || len(catalog.Judges) != 4 {
+		t.Fatalf("Expected version 3 with 4 judges, got %+v", catalog)
+	}
+
+	info, ok := catalog.Judge("relevance")
+	if !ok {
+		t.Fatal("Expected the relevance judge in the catalog")
+	}
+	if info.Type != config.JudgeTypeLLM || info.Mode != config.JudgeModeStructured || !info.Enabled {
+		t.Errorf("Expected an enabled structured LLM judge, got %+v", info)
+	}
+	if info.Model == nil || info.Model.ModelID != "gpt-4o" || info.Model.MaxTokens != 256 || !info.Model.Retry {
+		t.Errorf("Expected the model settings, got %+v", info.Model)
+	}
+	if info.PromptHash != relevance.PromptHash(nil) {
+		t.Errorf("Expected the prompt hash of the stage fingerprint, got %q", info.PromptHash)
+	}
+
+	info, _ = catalog.Judge("context-precision")
+	if info.Enabled || !info.RequiresContext || info.Unit != "chunk" || info.Mode != config.JudgeModeReasoning {
+		t.Errorf("Expected a disabled per-chunk reasoning judge, got %+v", info)
+	}
+
+	info, _ = catalog.Judge("no-apology")
+	if info.Type != "not_contains" || info.Params == nil || info.Model != nil || info.PromptHash != "" {
+		t.Errorf("Expected a code judge with params only, got %+v", info)
+	}
+
+	info, _ = catalog.Judge("lint")
+	if info.Type != "exec" || info.Params != nil {
+		t.Errorf("Expected an exec judge without its command, got %+v", info)
+	}
+
+	if _, ok := catalog.Judge("unknown"); ok {
+		t.Error("Expected no entry for an unknown judge")
+	}
+}
@@ -0,0 +1,344 @@
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// RegexJudge asserts that the answer matches a pattern, or with MustMatch unset
+// that it does not. The score is 1.0 when the assertion holds, 0.0 otherwise.
+type RegexJudge struct {
+	name      string
+	pattern   *regexp.Regexp
+	mustMatch bool
+}
+
+func NewRegexJudge(name string, pattern *regexp.Regexp, mustMatch bool) *RegexJudge {
+	return &RegexJudge{name: name, pattern: pattern, mustMatch: mustMatch}
+}
+
+func (j *RegexJudge) Name() string {
+	return j.name
+}
+
+func (j *RegexJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContext) models.StageResult {
+	now := time.Now()
+	result := models.StageResult{Name: fmt.Sprintf("%s-judge", j.name)}
+
+	match := j.pattern.FindStringIndex(evalCtx.Answer)
+	switch {
+	case match != nil && j.mustMatch:
+		result.Score = 1.0
+		result.Reason = fmt.Sprintf("Answer matches %s", j.pattern)
+	case match == nil && j.mustMatch:
+		result.Reason = fmt.Sprintf("Answer does not match %s", j.pattern)
+	case match != nil:
+		result.Reason = fmt.Sprintf("Answer matches forbidden pattern %s", j.pattern)
+		result.Findings = []models.Finding{{Type: "regex", Start: match[0], End: match[1]}}
+	default:
+		result.Score = 1.0
+		result.Reason = fmt.Sprintf("Answer does not match forbidden pattern %s", j.pattern)
+	}
+
+	result.Duration = time.Since(now)
+	return result
+}
+
+// newRegexJudgeFromParams creates a RegexJudge from the pattern and must_match
+// (default true) params
+func newRegexJudgeFromParams(name string, params Params, deps Dependencies) (Judge, error) {
+	if err := params.Only("pattern", "must_match"); err != nil {
+		return nil, err
+	}
+	pattern, err := params.String("pattern", "")
+	if err != nil {
+		return nil, err
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("param pattern is required")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("param pattern is invalid: %w", err)
+	}
+	mustMatch, err := params.Bool("must_match", true)
+	if err != nil {
+		return nil, err
+	}
+	return NewRegexJudge(name, re, mustMatch), nil
+}
+
+// JSONSchemaJudge checks that the answer is a JSON document valid against a
+// schema. Markdown code fences around the JSON are ignored.
+type JSONSchemaJudge struct {
+	name   string
+	schema map[string]any
+}
+
+func NewJSONSchemaJudge(name string, schema map[string]any) *JSONSchemaJudge {
+	return &JSONSchemaJudge{name: name, schema: schema}
+}
+
+func (j *JSONSchemaJudge) Name() string {
+	return j.name
+}
+
+func (j *JSONSchemaJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContext) models.StageResult {
+	now := time.Now()
+	result := models.StageResult{Name: fmt.Sprintf("%s-judge", j.name)}
+
+	var value any
+	if err := json.Unmarshal([]byte(stripMarkdownCodeBlock(evalCtx.Answer)), &value); err != nil {
+		result.Reason = fmt.Sprintf("Answer is not valid JSON: %v", err)
+	} else if err := llm.ValidateJSONAs(j.schema, value, "answer"); err != nil {
+		result.Reason = fmt.Sprintf("Answer does not match the schema: %v", err)
+	} else {
+		result.Score = 1.0
+		result.Reason = "Answer matches the schema"
+	}
+
+	result.Duration = time.Since(now)
+	return result
+}
+
+// newJSONSchemaJudgeFromParams creates a JSONSchemaJudge from the schema param
+func newJSONSchemaJudgeFromParams(name string, params Params, deps Dependencies) (Judge, error) {
+	if err := params.Only("schema"); err != nil {
+		return nil, err
+	}
+	schema, ok := params["schema"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("param schema is required and must be a map")
+	}
+	// Keywords the validator does not enforce would let non-conforming answers pass
+	if err := llm.CheckSchema(schema); err != nil {
+		return nil, fmt.Errorf("param schema: %w", err)
+	}
+	return NewJSONSchemaJudge(name, schema), nil
+}
+
+// ContainsJudge checks the answer for the given values. A contains judge scores
+// the share of values found; a not_contains judge (Forbid) scores 1.0 when none
+// of the values is found and 0.0 otherwise.
+type ContainsJudge struct {
+	name          string
+	values        []string
+	forbid        bool
+	caseSensitive bool
+}
+
+func NewContainsJudge(name string, values []string, forbid bool, caseSensitive bool) *ContainsJudge {
+	return &ContainsJudge{name: name, values: values, forbid: forbid, caseSensitive: caseSensitive}
+}
+
+func (j *ContainsJudge) Name() string {
+	return j.name
+}
+
+func (j *ContainsJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContext) models.StageResult {
+	now := time.Now()
+	result := models.StageResult{Name: fmt.Sprintf("%s-judge", j.name)}
+
+	answer := evalCtx.Answer
+	if !j.caseSensitive {
+		answer = strings.ToLower(answer)
+	}
+
+	var found, missing []string
+	for _, value := range j.values {
+		needle := value
+		if !j.caseSensitive {
+			needle = strings.ToLower(value)
+		}
+		if strings.Contains(answer, needle) {
+			found = append(found, value)
+		} else {
+			missing = append(missing, value)
+		}
+	}
+
+	switch {
+	case j.forbid && len(found) > 0:
+		result.Reason = fmt.Sprintf("Answer mentions %s", quoteAll(found))
+	case j.forbid:
+		result.Score = 1.0
+		result.Reason = "Answer mentions none of the forbidden values"
+	case len(missing) > 0:
+		result.Score = float64(len(found)) / float64(len(j.values))
+		result.Reason = fmt.Sprintf("Answer does not mention %s", quoteAll(missing))
+	default:
+		result.Score = 1.0
+		result.Reason = "Answer mentions all required values"
+	}
+
+	result.Duration = time.Since(now)
+	return result
+}
+
+// newContainsJudgeFromParams returns the factory of contains (forbid unset) or
+// not_contains judges, reading the values and case_sensitive (default false) params
+func newContainsJudgeFromParams(forbid bool) Factory {
+	return func(name string, params Params, deps Dependencies) (Judge, error) {
+		if err := params.Only("values", "case_sensitive"); err != nil {
+			return nil, err
+		}
+		values, err := params.Strings("values")
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("param values is required")
+		}
+		caseSensitive, err := params.Bool("case_sensitive", false)
+		if err != nil {
+			return nil, err
+		}
+		return NewContainsJudge(name, values, forbid, caseSensitive), nil
+	}
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// numberPattern matches a decimal number, with optional thousands separators
+var numberPattern = regexp.MustCompile(`[-+]?(?:\d{1,3}(?:,\d{3})+|\d+)(?:\.\d+)?`)
+
+// NumericJudge compares the number in the answer to the expected value, or
+// without one to the number in the reference answer. The number is the first
+// one in the text, or the first capture group of Pattern. The score is 1.0 when
+// the difference is within the absolute or relative tolerance, 0.0 otherwise.
+type NumericJudge struct {
+	name              string
+	expected          *float64
+	tolerance         float64
+	relativeTolerance float64
+	pattern           *regexp.Regexp
+}
+
+func NewNumericJudge(name string, expected *float64, tolerance float64, relativeTolerance float64, pattern *regexp.Regexp) *NumericJudge {
+	return &NumericJudge{
+		name:              name,
+		expected:          expected,
+		tolerance:         tolerance,
+		relativeTolerance: relativeTolerance,
+		pattern:           pattern,
+	}
+}
+
+func (j *NumericJudge) Name() string {
+	return j.name
+}
+
+func (j *NumericJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContext) models.StageResult {
+	now := time.Now()
+	result := models.StageResult{Name: fmt.Sprintf("%s-judge", j.name)}
+
+	var expected float64
+	if j.expected != nil {
+		expected = *j.expected
+	} else {
+		value, ok := j.extract(evalCtx.Reference)
+		if !ok {
+			result.Reason = "Expected value or a reference answer with a number required but not provided"
+			result.Duration = time.Since(now)
+			return result
+		}
+		expected = value
+	}
+
+	actual, ok := j.extract(evalCtx.Answer)
+	if !ok {
+		result.Reason = "Answer contains no number"
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	allowed := max(j.tolerance, j.relativeTolerance*math.Abs(expected))
+	if diff := math.Abs(actual - expected); diff <= allowed {
+		result.Score = 1.0
+		result.Reason = fmt.Sprintf("Answer %g is within %g of the expected %g", actual, allowed, expected)
+	} else {
+		result.Reason = fmt.Sprintf("Answer %g differs from the expected %g by %g (tolerance %g)", actual, expected, diff, allowed)
+	}
+
+	result.Duration = time.Since(now)
+	return result
+}
+
+// extract returns the number of the text
+func (j *NumericJudge) extract(text string) (float64, bool) {
+	raw := ""
+	if j.pattern != nil {
+		match := j.pattern.FindStringSubmatch(text)
+		if len(match) < 2 {
+			return 0, false
+		}
+		raw = match[1]
+	} else {
+		raw = numberPattern.FindString(text)
+	}
+
+	value, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(raw), ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// newNumericJudgeFromParams creates a NumericJudge from the expected, tolerance,
+// relative_tolerance and pattern (with one capture group) params
+func newNumericJudgeFromParams(name string, params Params, deps Dependencies) (Judge, error) {
+	if err := params.Only("expected", "tolerance", "relative_tolerance", "pattern"); err != nil {
+		return nil, err
+	}
+
+	var expected *float64
+	if _, ok := params["expected"]; ok {
+		value, err := params.Float("expected", 0)
+		if err != nil {
+			return nil, err
+		}
+		expected = &value
+	}
+	tolerance, err := params.Float("tolerance", 0)
+	if err != nil {
+		return nil, err
+	}
+	relativeTolerance, err := params.Float("relative_tolerance", 0)
+	if err != nil {
+		return nil, err
+	}
+	if tolerance < 0 || relativeTolerance < 0 {
+		return nil, fmt.Errorf("tolerances must not be negative")
+	}
+
+	var re *regexp.Regexp
+	pattern, err := params.String("pattern", "")
+	if err != nil {
+		return nil, err
+	}
+	if pattern != "" {
+		if re, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("param pattern is invalid: %w", err)
+		}
+		if re.NumSubexp() < 1 {
+			return nil, fmt.Errorf("param pattern must have a capture group for the number")
+		}
+	}
+
+	return NewNumericJudge(name, expected, tolerance, relativeTolerance, re), nil
+}
@@ -0,0 +1,92 @@
+package judge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// buildCodeJudge builds a judge of the default registry
+func buildCodeJudge(t *testing.T, judgeType string, params map[string]any) Judge {
+	t.Helper()
+	logger := zerolog.Nop()
+	judge, err := defaultRegistry.Build(config.JudgeConfiguration{Name: "test", Type: judgeType, Params: params}, Dependencies{Logger: &logger})
+	if err != nil {
+		t.Fatalf("Build %s failed: %v", judgeType, err)
+	}
+	return judge
+}
+
+func TestCodeJudges(t *testing.T) {
+	tests := []struct {
+		name       string
+		judgeType  string
+		params     map[string]any
+		answer     string
+		reference  string
+		wantScore  float64
+		wantReason string
+	}{
+		{"regex match", "regex", map[string]any{"pattern": `(?i)\bSELECT\b`}, "select * from users", "", 1.0, "Answer matches"},
+		{"regex no match", "regex", map[string]any{"pattern": `^\d+$`}, "forty two", "", 0.0, "Answer does not match"},
+		{"regex forbidden", "regex", map[string]any{"pattern": `TODO`, "must_match": false}, "Done. TODO: tests", "", 0.0, "forbidden pattern"},
+		{"regex forbidden absent", "regex", map[string]any{"pattern": `TODO`, "must_match": false}, "Done.", "", 1.0, "does not match forbidden"},
+
+		{"json valid", "json_schema", jsonSchemaParams, "```json\n{\"name\": \"a\", \"count\": 2}\n```", "", 1.0, "Answer matches the schema"},
+		{"json invalid schema", "json_schema", jsonSchemaParams, `{"name": "a", "count": -1}`, "", 0.0, "count: -1 is out of range"},
+		{"json not json", "json_schema", jsonSchemaParams, "name is a", "", 0.0, "Answer is not valid JSON"},
+		{"json not an object", "json_schema", jsonSchemaParams, `[1]`, "", 0.0, "Answer does not match the schema: answer: expected object, got array"},
+		{"json response field", "json_schema", map[string]any{"schema": map[string]any{"required": []any{"response"}}}, `{}`, "", 0.0, "schema: response: missing required field"},
+
+		{"contains all", "contains", map[string]any{"values": []any{"Acme Cloud", "SLA"}}, "acme cloud offers a 99.9% sla", "", 1.0, "mentions all"},
+		{"contains some", "contains", map[string]any{"values": []any{"Acme Cloud", "SLA"}}, "Acme Cloud is great", "", 0.5, `does not mention "SLA"`},
+		{"contains case sensitive", "contains", map[string]any{"values": []any{"SLA"}, "case_sensitive": true}, "sla", "", 0.0, `does not mention "SLA"`},
+		{"not contains", "not_contains", map[string]any{"values": []any{"CompetitorX"}}, "We recommend Acme.", "", 1.0, "none of the forbidden"},
+		{"not contains found", "not_contains", map[string]any{"values": []any{"CompetitorX"}}, "competitorx is cheaper", "", 0.0, `mentions "CompetitorX"`},
+
+		{"numeric exact", "numeric", map[string]any{"expected": 42}, "The answer is 42.", "", 1.0, "within 0"},
+		{"numeric tolerance", "numeric", map[string]any{"expected": 100, "relative_tolerance": 0.05}, "About 1,03 thousand, i.e. 103", "", 0.0, "differs"},
+		{"numeric relative", "numeric", map[string]any{"expected": 1000, "relative_tolerance": 0.05}, "Roughly 1,030 users", "", 1.0, "within 50"},
+		{"numeric reference", "numeric", map[string]any{"tolerance": 0.01}, "Total: 3.14", "It is 3.14159", 1.0, "expected 3.14159"},
+		{"numeric pattern", "numeric", map[string]any{"expected": 7, "pattern": `total=(\d+)`}, "step 1, total=7", "", 1.0, "Answer 7"},
+		{"numeric no number", "numeric", map[string]any{"expected": 7}, "seven", "", 0.0, "Answer contains no number"},
+		{"numeric no expected", "numeric", map[string]any{}, "7", "", 0.0, "required but not provided"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			judge := buildCodeJudge(t, tt.judgeType, tt.params)
+			result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: tt.answer, Reference: tt.reference})
+
+			if result.Name != "test-judge" {
+				t.Errorf("Expected stage name test-judge, got %s", result.Name)
+			}
+			if result.Score != tt.wantScore || !strings.Contains(result.Reason, tt.wantReason) {
+				t.Errorf("Expected %f %q, got %f %q", tt.wantScore, tt.wantReason, result.Score, result.Reason)
+			}
+		})
+	}
+}
+
+var jsonSchemaParams = map[string]any{
+	"schema": map[string]any{
+		"type":     "object",
+		"required": []any{"name", "count"},
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string"},
+			"count": map[string]any{"type": "integer", "minimum": 0},
+		},
+	},
+}
+
+func TestRegexJudge_Findings(t *testing.T) {
+	judge := buildCodeJudge(t, "regex", map[string]any{"pattern": `TODO`, "must_match": false})
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "Done. TODO: tests"})
+	if len(result.Findings) != 1 || result.Findings[0].Start != 6 || result.Findings[0].End != 10 {
+		t.Errorf("Expected the forbidden match as finding, got %+v", result.Findings)
+	}
+}
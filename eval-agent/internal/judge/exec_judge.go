@@ -0,0 +1,153 @@
+package judge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// DefaultExecTimeout bounds the run time of exec judge commands
+const DefaultExecTimeout = 10 * time.Second
+
+// execWaitDelay bounds the wait for the output of a command killed at its
+// timeout, when a child process it started still holds stdout open
+const execWaitDelay = time.Second
+
+// maxStderr is the number of stderr bytes reported in the reason of a failed command
+const maxStderr = 500
+
+// ExecJudge runs an external command per evaluation. The command gets the
+// evaluation context as JSON on stdin and must print {"score": <0.0-1.0>,
+// "reason": "..."} on stdout. A failing command, a timeout or invalid output
+// score 0.0.
+type ExecJudge struct {
+	name    string
+	command []string
+	timeout time.Duration
+	logger  *zerolog.Logger
+}
+
+func NewExecJudge(name string, command []string, timeout time.Duration, logger *zerolog.Logger) *ExecJudge {
+	if timeout <= 0 {
+		timeout = DefaultExecTimeout
+	}
+	return &ExecJudge{name: name, command: command, timeout: timeout, logger: logger}
+}
+
+func (j *ExecJudge) Name() string {
+	return j.name
+}
+
+// Timeout returns the timeout of the runner for the judge: the command timeout
+// plus the wait for the output of a killed command, so that the command's own
+// timeout applies first
+func (j *ExecJudge) Timeout(evalCtx models.EvaluationContext) time.Duration {
+	return j.timeout + execWaitDelay
+}
+
+func (j *ExecJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContext) models.StageResult {
+	now := time.Now()
+	result := models.StageResult{Name: fmt.Sprintf("%s-judge", j.name)}
+
+	input, err := json.Marshal(evalCtx)
+	if err != nil {
+		result.Reason = fmt.Sprintf("Failed to encode the evaluation context: %v", err)
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, j.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(cmdCtx, j.command[0], j.command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = execWaitDelay
+
+	if err := cmd.Run(); err != nil {
+		j.logger.Error().
+			Err(err).
+			Str("judge", j.name).
+			Str("stderr", stderr.String()).
+			Msg("judge command failed")
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			result.Reason = fmt.Sprintf("Judge command timed out after %s", j.timeout)
+		} else {
+			result.Reason = fmt.Sprintf("Judge command failed: %v", err)
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				result.Reason += ": " + truncate(msg, maxStderr)
+			}
+		}
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	response, reason, err := parseResponse(stdout.String(), commandOutput)
+	if err != nil {
+		j.logger.Error().
+			Err(err).
+			Str("judge", j.name).
+			Str("stdout", stdout.String()).
+			Msg("invalid judge command output")
+		result.Reason = reason
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	result.Score = response.Score
+	result.Reason = response.Reason
+	result.Duration = time.Since(now)
+	return result
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// newExecJudgeFromParams creates an ExecJudge from the command (program and
+// arguments) and timeout (duration, default 10s) params
+func newExecJudgeFromParams(name string, params Params, deps Dependencies) (Judge, error) {
+	if err := params.Only("command", "timeout"); err != nil {
+		return nil, err
+	}
+	command, err := params.Strings("command")
+	if err != nil {
+		return nil, err
+	}
+	if len(command) == 0 || command[0] == "" {
+		return nil, fmt.Errorf("param command is required")
+	}
+
+	timeout := DefaultExecTimeout
+	value, err := params.String("timeout", "")
+	if err != nil {
+		return nil, err
+	}
+	if value != "" {
+		if timeout, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("param timeout is invalid: %w", err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("param timeout must be positive")
+		}
+	}
+
+	logger := deps.Logger
+	if logger == nil {
+		nop := zerolog.Nop()
+		logger = &nop
+	}
+	return NewExecJudge(name, command, timeout, logger), nil
+}
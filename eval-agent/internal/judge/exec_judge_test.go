@@ -0,0 +1,87 @@
+package judge
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func TestExecJudge_Evaluate(t *testing.T) {
+	logger := zerolog.Nop()
+	evalCtx := models.EvaluationContext{Query: "q", Answer: "SELECT 1"}
+
+	tests := []struct {
+		name       string
+		script     string
+		timeout    time.Duration
+		wantScore  float64
+		wantReason string
+	}{
+		{
+			name:       "reads the context from stdin",
+			script:     `grep -q '"answer":"SELECT 1"' && echo '{"score": 0.8, "reason": "valid SQL"}'`,
+			wantScore:  0.8,
+			wantReason: "valid SQL",
+		},
+		{
+			name:       "command fails",
+			script:     `echo 'syntax error' >&2; exit 3`,
+			wantReason: "Judge command failed: exit status 3: syntax error",
+		},
+		{
+			name:       "invalid output",
+			script:     `echo '{"score": 2, "reason": "too high"}'`,
+			wantReason: "Invalid judge command output: score: 2 is out of range [0, 1]",
+		},
+		{
+			name:       "output not JSON",
+			script:     `echo 'looks fine'`,
+			wantReason: "Failed to deserialize judge command output",
+		},
+		{
+			name:       "output not an object",
+			script:     `echo '[0.8]'`,
+			wantReason: "Invalid judge command output: output: expected object, got array",
+		},
+		{
+			name:       "timeout",
+			script:     `exec sleep 5`,
+			timeout:    50 * time.Millisecond,
+			wantReason: "Judge command timed out after 50ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			judge := NewExecJudge("sql", []string{"sh", "-c", tt.script}, tt.timeout, &logger)
+			result := judge.Evaluate(context.Background(), evalCtx)
+
+			if result.Name != "sql-judge" || result.Score != tt.wantScore || !strings.HasPrefix(result.Reason, tt.wantReason) {
+				t.Errorf("Expected %f %q, got %s %f %q", tt.wantScore, tt.wantReason, result.Name, result.Score, result.Reason)
+			}
+		})
+	}
+}
+
+func TestExecJudge_TimeoutBeyondRunnerDefault(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a command for longer than the default judge timeout")
+	}
+	logger := zerolog.Nop()
+
+	// The runner must give the command its own timeout, not DefaultJudgeTimeout
+	judge := NewExecJudge("slow", []string{"sh", "-c", `sleep 16; echo '{"score": 1.0, "reason": "done"}'`}, 30*time.Second, &logger)
+	runner := NewJudgeRunner([]Judge{judge}, &logger)
+
+	results := runner.Run(context.Background(), models.EvaluationContext{Query: "q", Answer: "a"})
+	if len(results) != 1 || results[0].Score != 1.0 || results[0].Reason != "done" {
+		t.Errorf("Expected the command to complete after %s, got %+v", DefaultJudgeTimeout, results)
+	}
+	if timeout := judgeTimeout(judge, models.EvaluationContext{}); timeout != 30*time.Second+execWaitDelay {
+		t.Errorf("Expected a runner timeout of 31s, got %s", timeout)
+	}
+}
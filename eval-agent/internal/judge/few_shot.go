@@ -0,0 +1,209 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// ExampleSelector picks the few-shot examples rendered into a judge prompt as .Examples
+type ExampleSelector interface {
+	Select(ctx context.Context, evalCtx models.EvaluationContext) []config.FewShotExample
+}
+
+// NewExampleSelector creates the selector for the configured strategy.
+// The embedding client is only required for embedding similarity.
+func NewExampleSelector(cfg *config.FewShotConfig, embedder embedding.Client, logger *zerolog.Logger) (ExampleSelector, error) {
+	switch cfg.Strategy {
+	case config.FewShotStatic, "":
+		return &staticSelector{examples: firstK(cfg.Examples, cfg.K)}, nil
+	case config.FewShotRandom:
+		return &randomSelector{examples: cfg.Examples, k: cfg.K}, nil
+	case config.FewShotSimilar:
+		if cfg.Similarity == config.SimilarityEmbedding {
+			if embedder == nil {
+				return nil, fmt.Errorf("embedding similarity requires an embedding client")
+			}
+			return &embeddingSelector{examples: cfg.Examples, k: cfg.K, embedder: embedder, logger: logger}, nil
+		}
+		return newLexicalSelector(cfg.Examples, cfg.K), nil
+	default:
+		return nil, fmt.Errorf("unknown few-shot strategy: %s", cfg.Strategy)
+	}
+}
+
+// staticSelector always returns the same examples
+type staticSelector struct {
+	examples []config.FewShotExample
+}
+
+func (s *staticSelector) Select(ctx context.Context, evalCtx models.EvaluationContext) []config.FewShotExample {
+	return s.examples
+}
+
+// randomSelector returns k examples sampled without replacement for every request
+type randomSelector struct {
+	examples []config.FewShotExample
+	k        int
+}
+
+func (s *randomSelector) Select(ctx context.Context, evalCtx models.EvaluationContext) []config.FewShotExample {
+	if s.k >= len(s.examples) {
+		return s.examples
+	}
+
+	selected := make([]config.FewShotExample, 0, s.k)
+	for _, i := range rand.Perm(len(s.examples))[:s.k] {
+		selected = append(selected, s.examples[i])
+	}
+	return selected
+}
+
+// lexicalSelector returns the k examples whose query and answer share the most
+// words with the request (Jaccard similarity)
+type lexicalSelector struct {
+	examples []config.FewShotExample
+	tokens   []map[string]bool
+	k        int
+}
+
+func newLexicalSelector(examples []config.FewShotExample, k int) *lexicalSelector {
+	tokens := make([]map[string]bool, len(examples))
+	for i, example := range examples {
+		tokens[i] = tokenSet(example.Query + " " + example.Answer)
+	}
+	return &lexicalSelector{examples: examples, tokens: tokens, k: k}
+}
+
+func (s *lexicalSelector) Select(ctx context.Context, evalCtx models.EvaluationContext) []config.FewShotExample {
+	requestTokens := tokenSet(evalCtx.Query + " " + evalCtx.Answer)
+
+	scores := make([]float64, len(s.examples))
+	for i, exampleTokens := range s.tokens {
+		scores[i] = jaccard(requestTokens, exampleTokens)
+	}
+
+	return topK(s.examples, scores, s.k)
+}
+
+// embeddingSelector returns the k examples whose query is closest to the request
+// query by cosine similarity. Example embeddings are computed once, on first use.
+type embeddingSelector struct {
+	examples []config.FewShotExample
+	k        int
+	embedder embedding.Client
+	logger   *zerolog.Logger
+
+	mu         sync.Mutex
+	embeddings [][]float64
+}
+
+func (s *embeddingSelector) Select(ctx context.Context, evalCtx models.EvaluationContext) []config.FewShotExample {
+	exampleEmbeddings, err := s.exampleEmbeddings(ctx)
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to embed few-shot examples, using first k")
+		return firstK(s.examples, s.k)
+	}
+
+	vectors, err := s.embedder.Embed(ctx, []string{evalCtx.Query})
+	if err != nil || len(vectors) != 1 {
+		s.logger.Warn().Err(err).Msg("failed to embed query, using first k few-shot examples")
+		return firstK(s.examples, s.k)
+	}
+
+	scores := make([]float64, len(s.examples))
+	for i, exampleEmbedding := range exampleEmbeddings {
+		scores[i] = embedding.CosineSimilarity(vectors[0], exampleEmbedding)
+	}
+
+	return topK(s.examples, scores, s.k)
+}
+
+func (s *embeddingSelector) exampleEmbeddings(ctx context.Context) ([][]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.embeddings != nil {
+		return s.embeddings, nil
+	}
+
+	queries := make([]string, len(s.examples))
+	for i, example := range s.examples {
+		queries[i] = example.Query
+	}
+
+	embeddings, err := s.embedder.Embed(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) != len(s.examples) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(s.examples), len(embeddings))
+	}
+
+	s.embeddings = embeddings
+	return embeddings, nil
+}
+
+// topK returns the k examples with the highest scores, keeping configuration order on ties
+func topK(examples []config.FewShotExample, scores []float64, k int) []config.FewShotExample {
+	indexes := make([]int, len(examples))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return scores[indexes[i]] > scores[indexes[j]]
+	})
+
+	if k > len(indexes) {
+		k = len(indexes)
+	}
+
+	selected := make([]config.FewShotExample, k)
+	for i := range selected {
+		selected[i] = examples[indexes[i]]
+	}
+	return selected
+}
+
+func firstK(examples []config.FewShotExample, k int) []config.FewShotExample {
+	if k <= 0 || k >= len(examples) {
+		return examples
+	}
+	return examples[:k]
+}
+
+func tokenSet(s string) map[string]bool {
+	tokens := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		tokens[word] = true
+	}
+	return tokens
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for token := range a {
+		if b[token] {
+			intersection++
+		}
+	}
+
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
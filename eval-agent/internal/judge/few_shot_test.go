@@ -0,0 +1,185 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+var fewShotExamples = []config.FewShotExample{
+	{Query: "What is the capital of France?", Answer: "Paris", Score: 1.0, Reason: "correct"},
+	{Query: "How do I reverse a list in Python?", Answer: "Use list.reverse()", Score: 1.0, Reason: "correct"},
+	{Query: "What is the boiling point of water?", Answer: "100 degrees", Score: 0.9, Reason: "mostly"},
+}
+
+// MockEmbeddingClient embeds texts as fixed vectors keyed by text
+type MockEmbeddingClient struct {
+	Vectors map[string][]float64
+	Err     error
+	Calls   int
+}
+
+func (m *MockEmbeddingClient) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	m.Calls++
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = m.Vectors[text]
+	}
+	return vectors, nil
+}
+
+func TestExampleSelector_Static(t *testing.T) {
+	logger := zerolog.Nop()
+
+	selector, err := NewExampleSelector(&config.FewShotConfig{Strategy: config.FewShotStatic, K: 2, Examples: fewShotExamples}, nil, &logger)
+	if err != nil {
+		t.Fatalf("NewExampleSelector failed: %v", err)
+	}
+
+	selected := selector.Select(context.Background(), models.EvaluationContext{})
+	if len(selected) != 2 || selected[0].Query != fewShotExamples[0].Query {
+		t.Errorf("Expected first 2 examples, got %+v", selected)
+	}
+}
+
+func TestExampleSelector_Random(t *testing.T) {
+	logger := zerolog.Nop()
+
+	selector, err := NewExampleSelector(&config.FewShotConfig{Strategy: config.FewShotRandom, K: 2, Examples: fewShotExamples}, nil, &logger)
+	if err != nil {
+		t.Fatalf("NewExampleSelector failed: %v", err)
+	}
+
+	selected := selector.Select(context.Background(), models.EvaluationContext{})
+	if len(selected) != 2 {
+		t.Fatalf("Expected 2 examples, got %d", len(selected))
+	}
+	if selected[0].Query == selected[1].Query {
+		t.Error("Expected examples sampled without replacement")
+	}
+}
+
+func TestExampleSelector_Lexical(t *testing.T) {
+	logger := zerolog.Nop()
+
+	selector, err := NewExampleSelector(&config.FewShotConfig{
+		Strategy:   config.FewShotSimilar,
+		Similarity: config.SimilarityLexical,
+		K:          1,
+		Examples:   fewShotExamples,
+	}, nil, &logger)
+	if err != nil {
+		t.Fatalf("NewExampleSelector failed: %v", err)
+	}
+
+	selected := selector.Select(context.Background(), models.EvaluationContext{
+		Query:  "How can I reverse a Python list?",
+		Answer: "Call reverse() on the list",
+	})
+	if len(selected) != 1 || selected[0].Query != "How do I reverse a list in Python?" {
+		t.Errorf("Expected the Python example, got %+v", selected)
+	}
+}
+
+func TestExampleSelector_Embedding(t *testing.T) {
+	logger := zerolog.Nop()
+	embedder := &MockEmbeddingClient{Vectors: map[string][]float64{
+		fewShotExamples[0].Query: {1, 0, 0},
+		fewShotExamples[1].Query: {0, 1, 0},
+		fewShotExamples[2].Query: {0, 0, 1},
+		"When does water boil?":  {0.1, 0, 0.9},
+	}}
+
+	selector, err := NewExampleSelector(&config.FewShotConfig{
+		Strategy:   config.FewShotSimilar,
+		Similarity: config.SimilarityEmbedding,
+		K:          1,
+		Examples:   fewShotExamples,
+	}, embedder, &logger)
+	if err != nil {
+		t.Fatalf("NewExampleSelector failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		selected := selector.Select(context.Background(), models.EvaluationContext{Query: "When does water boil?"})
+		if len(selected) != 1 || selected[0].Query != fewShotExamples[2].Query {
+			t.Errorf("Expected the boiling point example, got %+v", selected)
+		}
+	}
+
+	// Examples embedded once, query embedded per request
+	if embedder.Calls != 3 {
+		t.Errorf("Expected 3 embedding calls, got %d", embedder.Calls)
+	}
+}
+
+func TestExampleSelector_EmbeddingFailureFallsBack(t *testing.T) {
+	logger := zerolog.Nop()
+	embedder := &MockEmbeddingClient{Err: errors.New("throttled")}
+
+	selector, _ := NewExampleSelector(&config.FewShotConfig{
+		Strategy:   config.FewShotSimilar,
+		Similarity: config.SimilarityEmbedding,
+		K:          2,
+		Examples:   fewShotExamples,
+	}, embedder, &logger)
+
+	selected := selector.Select(context.Background(), models.EvaluationContext{Query: "anything"})
+	if len(selected) != 2 || selected[0].Query != fewShotExamples[0].Query {
+		t.Errorf("Expected first 2 examples on failure, got %+v", selected)
+	}
+}
+
+func TestExampleSelector_EmbeddingRequiresClient(t *testing.T) {
+	logger := zerolog.Nop()
+
+	_, err := NewExampleSelector(&config.FewShotConfig{
+		Strategy:   config.FewShotSimilar,
+		Similarity: config.SimilarityEmbedding,
+		K:          1,
+		Examples:   fewShotExamples,
+	}, nil, &logger)
+	if err == nil {
+		t.Error("Expected error without embedding client")
+	}
+}
+
+func TestLLMJudge_Evaluate_RendersExamples(t *testing.T) {
+	logger := zerolog.Nop()
+
+	cfg := config.JudgeConfiguration{
+		Name:   "relevance",
+		Prompt: "{{range .Examples}}[{{.Query}} => {{.Score}}]{{end}} Query: {{.Query}}",
+		Model:  &config.ModelConfig{MaxTokens: 256},
+		FewShot: &config.FewShotConfig{
+			Strategy: config.FewShotStatic,
+			K:        1,
+			Examples: fewShotExamples,
+		},
+	}
+
+	mockClient := &MockLLMClient{
+		ResponseToReturn: &llm.LLMResponse{Content: `{"score": 0.9, "reason": "ok"}`},
+	}
+
+	judge, err := NewLLMJudge(cfg, mockClient, &logger)
+	if err != nil {
+		t.Fatalf("NewLLMJudge failed: %v", err)
+	}
+
+	judge.Evaluate(context.Background(), models.EvaluationContext{Query: "What is Go?", Answer: "A language"})
+
+	expected := "[What is the capital of France? => 1] Query: What is Go?"
+	if !strings.Contains(mockClient.LastRequest.Prompt, expected) {
+		t.Errorf("Expected prompt to contain %q, got %q", expected, mockClient.LastRequest.Prompt)
+	}
+}
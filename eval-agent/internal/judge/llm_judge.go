@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
@@ -21,16 +24,45 @@ type LLMJudge struct {
 	promptTemplate  *template.Template
 	modelConfig     config.ModelConfig
 	requiresContext bool
+	mode            string
+	verdict         string // instruction appended to reasoning prompts without a verdict block
+	perChunk        bool
+	perStatement    bool
+	perClaim        bool
+	claimsTemplate  *template.Template
+	aggregation     string
+	maxClaims       int
+	concurrency     int
+	timeout         time.Duration
+	examples        ExampleSelector
+	fingerprint     models.JudgeFingerprint
+	pricing         *config.PricingConfig
 	llmClient       llm.LLMClient
 	logger          *zerolog.Logger
 }
 
+// judgeOptions holds the optional dependencies the pool passes to judges
+type judgeOptions struct {
+	partials map[string]string
+	embedder embedding.Client
+	pricing  *config.PricingConfig
+}
+
 func NewLLMJudge(
 	judgeCfg config.JudgeConfiguration,
 	llmClient llm.LLMClient,
 	logger *zerolog.Logger,
 ) (*LLMJudge, error) {
-	tmpl, err := template.New(judgeCfg.Name).Parse(judgeCfg.Prompt)
+	return newLLMJudge(judgeCfg, llmClient, judgeOptions{}, logger)
+}
+
+func newLLMJudge(
+	judgeCfg config.JudgeConfiguration,
+	llmClient llm.LLMClient,
+	opts judgeOptions,
+	logger *zerolog.Logger,
+) (*LLMJudge, error) {
+	tmpl, err := config.ParsePrompt(judgeCfg.Name, judgeCfg.Prompt, config.PartialsForMode(opts.partials, judgeCfg.Mode))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse prompt template for judge %s: %w", judgeCfg.Name, err)
 	}
@@ -39,13 +71,74 @@ func NewLLMJudge(
 		return nil, fmt.Errorf("judge %s has nil model config (should be populated by config loader)", judgeCfg.Name)
 	}
 
+	mode := judgeCfg.Mode
+	if mode == "" {
+		mode = config.JudgeModeStructured
+	}
+
+	var verdictInstruction string
+	if mode == config.JudgeModeReasoning {
+		verdictInstruction, err = config.VerdictInstruction(opts.partials)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render verdict instruction for judge %s: %w", judgeCfg.Name, err)
+		}
+	}
+
+	concurrency := judgeCfg.Concurrency
+	if concurrency == 0 {
+		concurrency = config.DefaultUnitConcurrency
+	}
+
+	maxClaims := judgeCfg.MaxClaims
+	if maxClaims == 0 {
+		maxClaims = config.DefaultMaxClaims
+	}
+
+	var claimsTmpl *template.Template
+	if judgeCfg.PerClaim {
+		claimsTmpl, err = config.ParsePrompt(judgeCfg.Name+"-claims", judgeCfg.ClaimsPrompt, opts.partials)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse claims prompt template for judge %s: %w", judgeCfg.Name, err)
+		}
+	}
+
+	var examples ExampleSelector
+	if judgeCfg.FewShot != nil {
+		examples, err = NewExampleSelector(judgeCfg.FewShot, opts.embedder, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create few-shot selector for judge %s: %w", judgeCfg.Name, err)
+		}
+	}
+
 	return &LLMJudge{
 		name:            judgeCfg.Name,
 		promptTemplate:  tmpl,
 		modelConfig:     *judgeCfg.Model,
 		requiresContext: judgeCfg.RequiresContext,
-		llmClient:       llmClient,
-		logger:          logger,
+		mode:            mode,
+		verdict:         verdictInstruction,
+		perChunk:        judgeCfg.PerChunk,
+		perStatement:    judgeCfg.PerStatement,
+		perClaim:        judgeCfg.PerClaim,
+		claimsTemplate:  claimsTmpl,
+		aggregation:     judgeCfg.ChunkAggregation,
+		maxClaims:       maxClaims,
+		concurrency:     concurrency,
+		timeout:         judgeCfg.Timeout,
+		examples:        examples,
+		fingerprint: models.JudgeFingerprint{
+			PromptHash:   judgeCfg.PromptHash(opts.partials),
+			Mode:         judgeCfg.Mode,
+			PerChunk:     judgeCfg.PerChunk,
+			PerStatement: judgeCfg.PerStatement,
+			PerClaim:     judgeCfg.PerClaim,
+			Aggregation:  judgeCfg.ChunkAggregation,
+			MaxTokens:    judgeCfg.Model.MaxTokens,
+			Temperature:  judgeCfg.Model.Temperature,
+		},
+		pricing:   opts.pricing,
+		llmClient: llmClient,
+		logger:    logger,
 	}, nil
 }
 
@@ -53,13 +146,15 @@ func NewLLMJudge(
 func (j *LLMJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContext) models.StageResult {
 	now := time.Now()
 
+	fingerprint := j.fingerprint
 	result := models.StageResult{
-		Name:  fmt.Sprintf("%s-judge", j.name),
-		Score: 0.0,
+		Name:        fmt.Sprintf("%s-judge", j.name),
+		Score:       0.0,
+		Fingerprint: &fingerprint,
 	}
 
 	// Check if context is required but missing
-	if j.requiresContext && evalCtx.Context == "" {
+	if (j.requiresContext || j.perChunk || j.perStatement || j.perClaim) && evalCtx.Context == "" && len(evalCtx.Contexts) == 0 {
 		j.logger.Warn().
 			Str("judge", j.name).
 			Msg("judge requires context but none provided")
@@ -68,90 +163,496 @@ func (j *LLMJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContex
 		return result
 	}
 
+	if j.perChunk {
+		j.evaluateChunks(ctx, evalCtx, &result)
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	if j.perStatement {
+		j.evaluateStatements(ctx, evalCtx, &result)
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	if j.perClaim {
+		j.evaluateClaims(ctx, evalCtx, &result)
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	verdict := j.judge(ctx, config.PromptData{EvaluationContext: evalCtx})
+	fingerprint.ModelID = verdict.modelID
+	result.Usage = verdict.usage
+	result.Reason = verdict.reason
+	result.Rationale = verdict.rationale
+	if verdict.ok {
+		result.Score = verdict.score
+	}
+	result.Duration = time.Since(now)
+
+	if verdict.ok {
+		j.logger.Info().
+			Str("judge", j.name).
+			Float64("score", result.Score).
+			Dur("duration", result.Duration).
+			Msg("judge completed")
+	}
+
+	return result
+}
+
+// judgement is the outcome of one judge prompt
+type judgement struct {
+	ok        bool
+	score     float64
+	reason    string
+	rationale string
+	modelID   string
+	usage     *models.TokenUsage
+}
+
+// judge renders the prompt, calls the model and parses its verdict
+func (j *LLMJudge) judge(ctx context.Context, data config.PromptData) judgement {
+	var verdict judgement
+
 	// Build prompt from template
-	prompt, err := j.buildPrompt(evalCtx)
+	prompt, err := j.buildPrompt(ctx, data)
 	if err != nil {
 		j.logger.Error().
 			Err(err).
 			Str("judge", j.name).
 			Msg("failed to build prompt from template")
-		result.Reason = fmt.Sprintf("Failed to build prompt: %v", err)
-		result.Duration = time.Since(now)
-		return result
+		verdict.reason = fmt.Sprintf("Failed to build prompt: %v", err)
+		return verdict
 	}
 
-	// Call LLM
-	var resp *llm.LLMResponse
-	if j.modelConfig.Retry {
-		resp, err = j.llmClient.InvokeModelWithRetry(ctx, llm.LLMRequest{
-			Prompt:      prompt,
-			MaxTokens:   j.modelConfig.MaxTokens,
-			Temperature: j.modelConfig.Temperature,
-		})
-	} else {
-		resp, err = j.llmClient.InvokeModel(ctx, llm.LLMRequest{
-			Prompt:      prompt,
-			MaxTokens:   j.modelConfig.MaxTokens,
-			Temperature: j.modelConfig.Temperature,
-		})
+	var schema *llm.OutputSchema
+	if j.mode == config.JudgeModeStructured {
+		schema = ResponseSchema
 	}
 
+	// Call LLM
+	resp, err := j.invoke(ctx, prompt, schema)
 	if err != nil {
 		j.logger.Error().
 			Err(err).
 			Str("judge", j.name).
 			Msg("LLM call failed")
-		result.Reason = "Failed to call LLM"
-		result.Duration = time.Since(now)
-		return result
+		verdict.reason = "Failed to call LLM"
+		return verdict
 	}
+	verdict.modelID = resp.ModelID
+	verdict.usage = j.usage(resp)
 
-	// Parse LLM response (strip markdown code blocks if present)
-	content := stripMarkdownCodeBlock(resp.Content)
-	var llmResponse judgeResponse
-	if err := json.Unmarshal([]byte(content), &llmResponse); err != nil {
-		j.logger.Error().
-			Err(err).
+	// Validate the response. Invalid output gets one repair attempt that sends the
+	// error back to the model; a truncated response is reported as such.
+	llmResponse, rationale, reason, parseErr := j.parse(resp)
+	if parseErr != nil && !errors.Is(parseErr, errTruncated) {
+		j.logger.Warn().
+			Err(parseErr).
 			Str("judge", j.name).
 			Str("content", resp.Content).
-			Msg("failed to deserialize LLM response")
-		result.Reason = "Failed to deserialize LLM response"
-		result.Duration = time.Since(now)
-		return result
+			Msg("invalid LLM response, requesting repair")
+
+		repaired, err := j.invoke(ctx, repairPrompt(prompt, resp.Content, parseErr, schema), schema)
+		if err != nil {
+			j.logger.Error().
+				Err(err).
+				Str("judge", j.name).
+				Msg("LLM repair call failed")
+		} else {
+			verdict.modelID = repaired.ModelID
+			verdict.usage.Add(*j.usage(repaired))
+			resp = repaired
+			llmResponse, rationale, reason, parseErr = j.parse(repaired)
+		}
 	}
 
-	// Validate response
-	if llmResponse.Score == 0.0 && llmResponse.Reason == "" {
+	verdict.rationale = rationale
+	if parseErr != nil {
 		j.logger.Error().
+			Err(parseErr).
 			Str("judge", j.name).
-			Msg("LLM returned empty score and reason")
-		result.Reason = "Invalid LLM response: missing score and reason"
-		result.Duration = time.Since(now)
-		return result
+			Str("stop_reason", resp.StopReason).
+			Str("content", resp.Content).
+			Msg("invalid LLM response")
+		verdict.reason = reason
+		return verdict
 	}
 
-	if llmResponse.Score < 0.0 || llmResponse.Score > 1.0 {
-		j.logger.Error().
+	verdict.ok = true
+	verdict.score = llmResponse.Score
+	verdict.reason = llmResponse.Reason
+	return verdict
+}
+
+// minRelevantScore is the score at which a chunk counts as relevant and a
+// statement as supported
+const minRelevantScore = 0.5
+
+// Timeout returns the configured timeout of the judge. Without one, per-unit
+// judges get DefaultJudgeTimeout per wave of concurrent unit calls, so that large
+// contexts, references and answers are not cut short. Per-claim judges also get
+// one for the decomposition, and are sized for max_claims claims.
+func (j *LLMJudge) Timeout(evalCtx models.EvaluationContext) time.Duration {
+	if j.timeout > 0 {
+		return j.timeout
+	}
+
+	units, calls := 1, 0
+	switch {
+	case j.perChunk:
+		units = len(evalCtx.Chunks())
+	case j.perStatement:
+		units = len(models.SplitSentences(evalCtx.Reference))
+	case j.perClaim:
+		units, calls = j.maxClaims, 1
+	}
+	waves := max((units+j.concurrency-1)/j.concurrency, 1)
+	return time.Duration(calls+waves) * DefaultJudgeTimeout
+}
+
+// forEach calls fn for every index below n, with at most the configured
+// concurrency of calls running at once, and reports each completed unit to the
+// unit progress function of ctx. Once ctx is done, the remaining calls are not
+// started.
+func (j *LLMJudge) forEach(ctx context.Context, unit string, n int, fn func(i int)) {
+	sem := make(chan struct{}, j.concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	progress := unitProgressFromContext(ctx)
+	var mu sync.Mutex
+	done := 0
+
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+			if progress != nil {
+				mu.Lock()
+				done++
+				progress(j.name, unit, done, n)
+				mu.Unlock()
+			}
+		}(i)
+	}
+}
+
+// judgeEach judges the prompts concurrently and adds their usage to the result
+func (j *LLMJudge) judgeEach(ctx context.Context, unit string, prompts []config.PromptData, result *models.StageResult) []judgement {
+	verdicts := make([]judgement, len(prompts))
+	for i := range verdicts {
+		verdicts[i].reason = "Not judged before the evaluation was canceled"
+	}
+
+	j.forEach(ctx, unit, len(prompts), func(i int) {
+		verdicts[i] = j.judge(ctx, prompts[i])
+	})
+
+	result.Usage = &models.TokenUsage{}
+	for _, verdict := range verdicts {
+		j.addCall(result, verdict)
+	}
+	return verdicts
+}
+
+// evaluateChunks judges every retrieved chunk and scores the stage with the mean
+// chunk score, or the average precision of the relevant chunks. A chunk that
+// cannot be judged fails the stage.
+func (j *LLMJudge) evaluateChunks(ctx context.Context, evalCtx models.EvaluationContext, result *models.StageResult) {
+	chunks := evalCtx.Chunks()
+	if len(chunks) == 0 {
+		result.Reason = "Context required but not provided"
+		return
+	}
+
+	prompts := make([]config.PromptData, len(chunks))
+	for i := range chunks {
+		prompts[i] = config.PromptData{EvaluationContext: evalCtx, Chunk: &chunks[i], Rank: i + 1}
+	}
+	verdicts := j.judgeEach(ctx, "chunk", prompts, result)
+
+	var failed []string
+	for i, verdict := range verdicts {
+		if !verdict.ok {
+			failed = append(failed, fmt.Sprintf("chunk %d: %s", i+1, verdict.reason))
+			continue
+		}
+		result.ChunkScores = append(result.ChunkScores, models.ChunkScore{
+			ID:     chunks[i].ID,
+			Rank:   i + 1,
+			Score:  verdict.score,
+			Reason: verdict.reason,
+		})
+	}
+
+	if len(failed) > 0 {
+		result.Reason = fmt.Sprintf("Failed to judge %d of %d chunks (%s)", len(failed), len(chunks), strings.Join(failed, "; "))
+		return
+	}
+
+	if j.aggregation == config.ChunkAggregationAveragePrecision {
+		precision, relevant := averagePrecision(result.ChunkScores)
+		result.Score = precision
+		result.Reason = fmt.Sprintf("Average precision %.2f, %d of %d chunks relevant", precision, relevant, len(chunks))
+	} else {
+		total := 0.0
+		for _, chunk := range result.ChunkScores {
+			total += chunk.Score
+		}
+		result.Score = total / float64(len(chunks))
+		result.Reason = fmt.Sprintf("Mean score %.2f over %d chunks", result.Score, len(chunks))
+	}
+
+	j.logger.Info().
+		Str("judge", j.name).
+		Int("chunks", len(chunks)).
+		Float64("score", result.Score).
+		Msg("per-chunk judge completed")
+}
+
+// averagePrecision returns the mean of precision@k over the ranks k of the
+// relevant chunks, so relevant chunks ranked below irrelevant ones lower the
+// score, and the number of relevant chunks. The scores are in rank order.
+func averagePrecision(scores []models.ChunkScore) (float64, int) {
+	relevant := 0
+	total := 0.0
+	for k, chunk := range scores {
+		if chunk.Score < minRelevantScore {
+			continue
+		}
+		relevant++
+		total += float64(relevant) / float64(k+1)
+	}
+
+	if relevant == 0 {
+		return 0, 0
+	}
+	return total / float64(relevant), relevant
+}
+
+// evaluateStatements judges every statement of the reference answer against the
+// context and scores the stage with the share of supported statements. A
+// statement that cannot be judged fails the stage.
+func (j *LLMJudge) evaluateStatements(ctx context.Context, evalCtx models.EvaluationContext, result *models.StageResult) {
+	statements := models.SplitSentences(evalCtx.Reference)
+	if len(statements) == 0 {
+		j.logger.Warn().
 			Str("judge", j.name).
-			Float64("score", llmResponse.Score).
-			Msg("LLM returned invalid score")
-		result.Reason = fmt.Sprintf("Invalid LLM response: score %f out of range [0.0, 1.0]", llmResponse.Score)
-		result.Duration = time.Since(now)
-		return result
+			Msg("judge requires a reference answer but none provided")
+		result.Reason = "Reference answer required but not provided"
+		return
 	}
 
-	// Success
-	result.Score = llmResponse.Score
-	result.Reason = llmResponse.Reason
-	result.Duration = time.Since(now)
+	prompts := make([]config.PromptData, len(statements))
+	for i, statement := range statements {
+		prompts[i] = config.PromptData{EvaluationContext: evalCtx, Statement: statement}
+	}
+	verdicts := j.judgeEach(ctx, "statement", prompts, result)
+
+	var failed []string
+	supported := 0
+	for i, verdict := range verdicts {
+		if !verdict.ok {
+			failed = append(failed, fmt.Sprintf("statement %d: %s", i+1, verdict.reason))
+			continue
+		}
+		statement := models.StatementScore{
+			Statement: statements[i],
+			Supported: verdict.score >= minRelevantScore,
+			Score:     verdict.score,
+			Reason:    verdict.reason,
+		}
+		if statement.Supported {
+			supported++
+		}
+		result.Statements = append(result.Statements, statement)
+	}
+
+	if len(failed) > 0 {
+		result.Reason = fmt.Sprintf("Failed to judge %d of %d statements (%s)", len(failed), len(statements), strings.Join(failed, "; "))
+		return
+	}
+
+	result.Score = float64(supported) / float64(len(statements))
+	result.Reason = fmt.Sprintf("%d of %d reference statements supported by the context", supported, len(statements))
 
 	j.logger.Info().
 		Str("judge", j.name).
+		Int("statements", len(statements)).
 		Float64("score", result.Score).
-		Dur("duration", result.Duration).
-		Msg("judge completed")
+		Msg("per-statement judge completed")
+}
 
-	return result
+// evaluateClaims decomposes the answer into atomic claims and verifies the first
+// max_claims claims against the context. The stage score is the share of
+// supported claims; contradicted and unverifiable claims are listed in the
+// reason. A claim that cannot be verified fails the stage.
+func (j *LLMJudge) evaluateClaims(ctx context.Context, evalCtx models.EvaluationContext, result *models.StageResult) {
+	result.Usage = &models.TokenUsage{}
+
+	var decomposed claimsResponse
+	call := j.structured(ctx, j.claimsTemplate, config.PromptData{EvaluationContext: evalCtx}, ClaimsSchema, &decomposed)
+	j.addCall(result, call)
+	if !call.ok {
+		result.Reason = fmt.Sprintf("Failed to decompose the answer into claims: %s", call.reason)
+		return
+	}
+
+	var claims []string
+	for _, claim := range decomposed.Claims {
+		if claim = strings.TrimSpace(claim); claim != "" {
+			claims = append(claims, claim)
+		}
+	}
+	if len(claims) == 0 {
+		result.Score = 1.0
+		result.Reason = "Answer makes no verifiable claims"
+		return
+	}
+
+	skipped := 0
+	if len(claims) > j.maxClaims {
+		skipped = len(claims) - j.maxClaims
+		claims = claims[:j.maxClaims]
+		j.logger.Warn().
+			Str("judge", j.name).
+			Int("skipped", skipped).
+			Int("max_claims", j.maxClaims).
+			Msg("answer has more claims than max_claims, skipping the rest")
+	}
+
+	verdicts := make([]claimResponse, len(claims))
+	calls := make([]judgement, len(claims))
+	for i := range calls {
+		calls[i].reason = "Not verified before the evaluation was canceled"
+	}
+	j.forEach(ctx, "claim", len(claims), func(i int) {
+		data := config.PromptData{EvaluationContext: evalCtx, Claim: claims[i]}
+		calls[i] = j.structured(ctx, j.promptTemplate, data, ClaimVerdictSchema, &verdicts[i])
+	})
+
+	var failed, unsupported []string
+	supported := 0
+	for i, call := range calls {
+		j.addCall(result, call)
+		if !call.ok {
+			failed = append(failed, fmt.Sprintf("claim %d: %s", i+1, call.reason))
+			continue
+		}
+		result.Claims = append(result.Claims, models.ClaimVerdict{
+			Claim:   claims[i],
+			Verdict: verdicts[i].Verdict,
+			Reason:  verdicts[i].Reason,
+		})
+		if verdicts[i].Verdict == models.ClaimSupported {
+			supported++
+		} else {
+			unsupported = append(unsupported, fmt.Sprintf("%q (%s)", claims[i], verdicts[i].Verdict))
+		}
+	}
+
+	if len(failed) > 0 {
+		result.Reason = fmt.Sprintf("Failed to verify %d of %d claims (%s)", len(failed), len(claims), strings.Join(failed, "; "))
+		return
+	}
+
+	result.Score = float64(supported) / float64(len(claims))
+	result.Reason = fmt.Sprintf("%d of %d claims supported by the context", supported, len(claims))
+	if len(unsupported) > 0 {
+		result.Reason += "; unsupported: " + strings.Join(unsupported, ", ")
+	}
+	if skipped > 0 {
+		result.Reason += fmt.Sprintf("; %d further claims not verified (max_claims %d)", skipped, j.maxClaims)
+	}
+
+	j.logger.Info().
+		Str("judge", j.name).
+		Int("claims", len(claims)).
+		Float64("score", result.Score).
+		Msg("per-claim judge completed")
+}
+
+// structured renders the template, calls the model for output matching the schema
+// and decodes it into out. Invalid output gets one repair attempt.
+func (j *LLMJudge) structured(ctx context.Context, tmpl *template.Template, data config.PromptData, schema *llm.OutputSchema, out any) judgement {
+	var call judgement
+	data.Mode = j.mode
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		j.logger.Error().
+			Err(err).
+			Str("judge", j.name).
+			Msg("failed to build prompt from template")
+		call.reason = fmt.Sprintf("Failed to build prompt: template execution failed: %v", err)
+		return call
+	}
+	prompt := buf.String()
+
+	resp, err := j.invoke(ctx, prompt, schema)
+	if err != nil {
+		j.logger.Error().
+			Err(err).
+			Str("judge", j.name).
+			Msg("LLM call failed")
+		call.reason = "Failed to call LLM"
+		return call
+	}
+	call.modelID = resp.ModelID
+	call.usage = j.usage(resp)
+
+	if llm.IsTruncated(resp.StopReason) {
+		call.reason = fmt.Sprintf("LLM response truncated at max_tokens (%d)", j.modelConfig.MaxTokens)
+		return call
+	}
+
+	reason, parseErr := parseStructured(resp.Content, llmResponse, schema, out)
+	if parseErr != nil {
+		j.logger.Warn().
+			Err(parseErr).
+			Str("judge", j.name).
+			Str("content", resp.Content).
+			Msg("invalid LLM response, requesting repair")
+
+		repaired, err := j.invoke(ctx, repairPrompt(prompt, resp.Content, parseErr, schema), schema)
+		if err != nil {
+			j.logger.Error().
+				Err(err).
+				Str("judge", j.name).
+				Msg("LLM repair call failed")
+			call.reason = reason
+			return call
+		}
+		call.modelID = repaired.ModelID
+		call.usage.Add(*j.usage(repaired))
+		if reason, parseErr = parseStructured(repaired.Content, llmResponse, schema, out); parseErr != nil {
+			call.reason = reason
+			return call
+		}
+	}
+
+	call.ok = true
+	return call
+}
+
+// addCall adds the usage and model of a call to the result
+func (j *LLMJudge) addCall(result *models.StageResult, call judgement) {
+	if call.usage != nil {
+		result.Usage.Add(*call.usage)
+	}
+	if call.modelID != "" {
+		result.Fingerprint.ModelID = call.modelID
+	}
 }
 
 // Name returns the judge's name
@@ -159,12 +660,151 @@ func (j *LLMJudge) Name() string {
 	return j.name
 }
 
-// buildPrompt executes the template with the evaluation context
-func (j *LLMJudge) buildPrompt(evalCtx models.EvaluationContext) (string, error) {
+// invoke calls the model for a judge response, as structured output when a schema is given
+func (j *LLMJudge) invoke(ctx context.Context, prompt string, schema *llm.OutputSchema) (*llm.LLMResponse, error) {
+	request := llm.LLMRequest{
+		Prompt:      prompt,
+		MaxTokens:   j.modelConfig.MaxTokens,
+		Temperature: j.modelConfig.Temperature,
+	}
+	if schema != nil {
+		request.JSONOutput = true
+		request.Schema = schema
+	}
+
+	if j.modelConfig.Retry {
+		return j.llmClient.InvokeModelWithRetry(ctx, request)
+	}
+	return j.llmClient.InvokeModel(ctx, request)
+}
+
+// parse extracts the verdict of a response, and for reasoning judges the rationale
+// written before it. On failure it returns the stage reason and the error.
+func (j *LLMJudge) parse(resp *llm.LLMResponse) (judgeResponse, string, string, error) {
+	if llm.IsTruncated(resp.StopReason) {
+		return judgeResponse{}, "", fmt.Sprintf("LLM response truncated at max_tokens (%d)", j.modelConfig.MaxTokens), errTruncated
+	}
+
+	if j.mode != config.JudgeModeReasoning {
+		response, reason, err := parseResponse(resp.Content, llmResponse)
+		return response, "", reason, err
+	}
+
+	rationale, verdict, found := splitVerdict(resp.Content)
+	if !found {
+		return judgeResponse{}, rationale, "Invalid LLM response: verdict block not found", fmt.Errorf("response does not end with a %s block", verdictOpen+verdictClose)
+	}
+
+	response, reason, err := parseResponse(verdict, llmResponse)
+	return response, rationale, reason, err
+}
+
+// outputSubject names the parsed output in the stage reasons and in the
+// validation errors
+type outputSubject struct {
+	name  string // e.g. "LLM response", in the stage reasons
+	field string // e.g. "response", for the output itself in validation errors
+}
+
+var (
+	llmResponse   = outputSubject{name: "LLM response", field: "response"}
+	commandOutput = outputSubject{name: "judge command output", field: "output"}
+)
+
+// parseResponse validates the output against ResponseSchema. On failure it
+// returns the stage reason and the error to send back to the model.
+func parseResponse(content string, subject outputSubject) (judgeResponse, string, error) {
+	var response judgeResponse
+	if reason, err := parseStructured(content, subject, ResponseSchema, &response); err != nil {
+		return response, reason, err
+	}
+
+	if response.Score == 0.0 && response.Reason == "" {
+		return response, fmt.Sprintf("Invalid %s: missing score and reason", subject.name), fmt.Errorf("score and reason are empty")
+	}
+
+	return response, "", nil
+}
+
+// parseStructured validates the output against the schema and decodes it into
+// out. On failure it returns the stage reason and the error to send back to the
+// model.
+func parseStructured(content string, subject outputSubject, schema *llm.OutputSchema, out any) (string, error) {
+	// Providers without native structured output may wrap JSON in markdown
+	content = stripMarkdownCodeBlock(content)
+
+	var value any
+	if err := json.Unmarshal([]byte(content), &value); err != nil {
+		return fmt.Sprintf("Failed to deserialize %s", subject.name), fmt.Errorf("%s is not valid JSON: %w", subject.field, err)
+	}
+
+	if err := llm.ValidateJSONAs(schema.Schema, value, subject.field); err != nil {
+		return fmt.Sprintf("Invalid %s: %v", subject.name, err), err
+	}
+
+	if err := json.Unmarshal([]byte(content), out); err != nil {
+		return fmt.Sprintf("Failed to deserialize %s", subject.name), err
+	}
+
+	return "", nil
+}
+
+// repairPrompt asks the model to correct an invalid response: structured output
+// to match the schema, free-form output to end with the verdict block
+func repairPrompt(prompt string, content string, parseErr error, schema *llm.OutputSchema) string {
+	instruction := "Respond again, ending with the verdict block as instructed."
+	if schema != nil {
+		schemaJSON, _ := json.Marshal(schema.Schema)
+		instruction = fmt.Sprintf("Respond again with only a JSON object matching this schema:\n%s", schemaJSON)
+	}
+
+	return fmt.Sprintf(`%s
+
+Your previous response could not be used:
+%s
+
+Error: %v
+
+%s`, prompt, content, parseErr, instruction)
+}
+
+// usage returns the token usage of a response, priced when the model is in the
+// price table and flagged as unpriced otherwise
+func (j *LLMJudge) usage(resp *llm.LLMResponse) *models.TokenUsage {
+	usage := &models.TokenUsage{
+		InputTokens:  resp.Usage.InputTokens,
+		OutputTokens: resp.Usage.OutputTokens,
+	}
+
+	cost, ok := j.pricing.Cost(resp.ModelID, usage.InputTokens, usage.OutputTokens)
+	if !ok && usage.InputTokens+usage.OutputTokens > 0 {
+		j.logger.Debug().
+			Str("judge", j.name).
+			Str("model_id", resp.ModelID).
+			Msg("model missing from price table, cost not reported")
+		usage.Unpriced = true
+	}
+	usage.CostUSD = cost
+
+	return usage
+}
+
+// buildPrompt executes the template with the prompt data and the selected few-shot examples
+func (j *LLMJudge) buildPrompt(ctx context.Context, data config.PromptData) (string, error) {
+	if j.examples != nil {
+		data.Examples = j.examples.Select(ctx, data.EvaluationContext)
+	}
+
+	data.Mode = j.mode
+
 	var buf bytes.Buffer
-	if err := j.promptTemplate.Execute(&buf, evalCtx); err != nil {
+	if err := j.promptTemplate.Execute(&buf, data); err != nil {
 		return "", fmt.Errorf("template execution failed: %w", err)
 	}
+	// Prompts that render the reasoning output partial already ask for the block
+	if j.mode == config.JudgeModeReasoning && !strings.Contains(buf.String(), verdictOpen) {
+		buf.WriteString("\n\n" + j.verdict)
+	}
 	return buf.String(), nil
 }
 
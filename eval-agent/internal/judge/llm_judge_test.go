@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
@@ -495,3 +499,762 @@ func TestLLMJudge_Evaluate_MarkdownWrappedJSON(t *testing.T) {
 		t.Errorf("Expected reason='Good answer', got '%s'", result.Reason)
 	}
 }
+
+func TestLLMJudge_Evaluate_Fingerprint(t *testing.T) {
+	logger := zerolog.Nop()
+
+	cfg := config.JudgeConfiguration{
+		Name:   "relevance",
+		Prompt: "Query: {{.Query}}\nAnswer: {{.Answer}}",
+		Model: &config.ModelConfig{
+			MaxTokens:   256,
+			Temperature: 0.2,
+		},
+	}
+
+	mockClient := &MockLLMClient{
+		ResponseToReturn: &llm.LLMResponse{
+			Content: `{"score": 0.85, "reason": "Good match"}`,
+			ModelID: "claude-test",
+		},
+	}
+
+	judge, err := NewLLMJudge(cfg, mockClient, &logger)
+	if err != nil {
+		t.Fatalf("NewLLMJudge failed: %v", err)
+	}
+
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Query: "What is AI?", Answer: "AI"})
+
+	if result.Fingerprint == nil {
+		t.Fatal("Expected fingerprint on stage result")
+	}
+	want := models.JudgeFingerprint{
+		PromptHash:  cfg.PromptHash(nil),
+		ModelID:     "claude-test",
+		MaxTokens:   256,
+		Temperature: 0.2,
+	}
+	if *result.Fingerprint != want {
+		t.Errorf("Expected fingerprint %+v, got %+v", want, *result.Fingerprint)
+	}
+
+	// The fingerprint is reported even when the LLM call fails
+	mockClient.ErrorToReturn = errors.New("throttled")
+	result = judge.Evaluate(context.Background(), models.EvaluationContext{Query: "What is AI?", Answer: "AI"})
+	if result.Fingerprint == nil || result.Fingerprint.PromptHash != want.PromptHash || result.Fingerprint.ModelID != "" {
+		t.Errorf("Expected prompt fingerprint without model on failure, got %+v", result.Fingerprint)
+	}
+
+	changed := cfg
+	changed.Prompt = "Q: {{.Query}}\nA: {{.Answer}}"
+	if changed.PromptHash(nil) == want.PromptHash {
+		t.Error("Expected prompt hash to change with the prompt")
+	}
+}
+
+func TestLLMJudge_Evaluate_Usage(t *testing.T) {
+	logger := zerolog.Nop()
+
+	cfg := config.JudgeConfiguration{
+		Name:   "relevance",
+		Prompt: "Query: {{.Query}}\nAnswer: {{.Answer}}",
+		Model:  &config.ModelConfig{MaxTokens: 256},
+	}
+	pricing := &config.PricingConfig{Models: map[string]config.ModelPrice{
+		"claude-test": {InputPerMillion: 3, OutputPerMillion: 15},
+	}}
+
+	mockClient := &MockLLMClient{
+		ResponseToReturn: &llm.LLMResponse{
+			Content: `{"score": 0.85, "reason": "Good match"}`,
+			ModelID: "claude-test-v1",
+			Usage:   llm.Usage{InputTokens: 1000, OutputTokens: 100},
+		},
+	}
+
+	judge, err := newLLMJudge(cfg, mockClient, judgeOptions{pricing: pricing}, &logger)
+	if err != nil {
+		t.Fatalf("newLLMJudge failed: %v", err)
+	}
+
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Query: "What is AI?", Answer: "AI"})
+	if result.Usage == nil {
+		t.Fatal("Expected usage on stage result")
+	}
+	if result.Usage.InputTokens != 1000 || result.Usage.OutputTokens != 100 {
+		t.Errorf("Expected 1000/100 tokens, got %+v", *result.Usage)
+	}
+	if math.Abs(result.Usage.CostUSD-0.0045) > 1e-12 {
+		t.Errorf("Expected cost 0.0045, got %v", result.Usage.CostUSD)
+	}
+
+	// Unpriced models still report tokens
+	mockClient.ResponseToReturn.ModelID = "llama3.1:8b"
+	result = judge.Evaluate(context.Background(), models.EvaluationContext{Query: "What is AI?", Answer: "AI"})
+	if result.Usage == nil || result.Usage.InputTokens != 1000 || result.Usage.CostUSD != 0 || !result.Usage.Unpriced {
+		t.Errorf("Expected unpriced tokens without cost for unpriced model, got %+v", result.Usage)
+	}
+
+	// No usage when the LLM call fails
+	mockClient.ErrorToReturn = errors.New("throttled")
+	result = judge.Evaluate(context.Background(), models.EvaluationContext{Query: "What is AI?", Answer: "AI"})
+	if result.Usage != nil {
+		t.Errorf("Expected no usage on failure, got %+v", result.Usage)
+	}
+}
+
+// sequenceLLMClient returns its responses in order and records the requests
+type sequenceLLMClient struct {
+	responses []*llm.LLMResponse
+	requests  []llm.LLMRequest
+}
+
+func (m *sequenceLLMClient) InvokeModel(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	m.requests = append(m.requests, request)
+	if len(m.requests) > len(m.responses) {
+		return nil, errors.New("unexpected call")
+	}
+	return m.responses[len(m.requests)-1], nil
+}
+
+func (m *sequenceLLMClient) InvokeModelWithRetry(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	return m.InvokeModel(ctx, request)
+}
+
+func TestLLMJudge_Evaluate_RepairsInvalidResponse(t *testing.T) {
+	logger := zerolog.Nop()
+
+	cfg := config.JudgeConfiguration{
+		Name:   "relevance",
+		Prompt: "Score: {{.Answer}}",
+		Model:  &config.ModelConfig{MaxTokens: 256},
+	}
+
+	client := &sequenceLLMClient{responses: []*llm.LLMResponse{
+		{Content: `{"score": 8, "reason": "Mostly relevant"}`, Usage: llm.Usage{InputTokens: 100, OutputTokens: 10}},
+		{Content: `{"score": 0.8, "reason": "Mostly relevant"}`, Usage: llm.Usage{InputTokens: 200, OutputTokens: 10}},
+	}}
+
+	judge, err := NewLLMJudge(cfg, client, &logger)
+	if err != nil {
+		t.Fatalf("NewLLMJudge failed: %v", err)
+	}
+
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if result.Score != 0.8 || result.Reason != "Mostly relevant" {
+		t.Errorf("Expected repaired response, got %f %q", result.Score, result.Reason)
+	}
+	if len(client.requests) != 2 {
+		t.Fatalf("Expected one repair call, got %d calls", len(client.requests))
+	}
+	if client.requests[0].Schema != ResponseSchema {
+		t.Error("Expected judge to request structured output")
+	}
+
+	repair := client.requests[1].Prompt
+	if !strings.HasPrefix(repair, "Score: test") || !strings.Contains(repair, `{"score": 8`) || !strings.Contains(repair, "score: 8 is out of range [0, 1]") {
+		t.Errorf("Expected repair prompt with the previous response and the error, got %q", repair)
+	}
+	if result.Usage == nil || result.Usage.InputTokens != 300 || result.Usage.OutputTokens != 20 {
+		t.Errorf("Expected usage of both calls, got %+v", result.Usage)
+	}
+}
+
+func TestLLMJudge_Evaluate_RepairFails(t *testing.T) {
+	logger := zerolog.Nop()
+
+	cfg := config.JudgeConfiguration{
+		Name:   "relevance",
+		Prompt: "Score: {{.Answer}}",
+		Model:  &config.ModelConfig{MaxTokens: 256},
+	}
+
+	client := &sequenceLLMClient{responses: []*llm.LLMResponse{
+		{Content: `The answer is relevant.`},
+		{Content: `{"score": 0.9}`},
+	}}
+
+	judge, _ := NewLLMJudge(cfg, client, &logger)
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if result.Score != 0.0 || result.Reason != "Invalid LLM response: reason: missing required field" {
+		t.Errorf("Expected schema error of the repaired response, got %f %q", result.Score, result.Reason)
+	}
+	if !strings.Contains(client.requests[1].Prompt, "response is not valid JSON") {
+		t.Errorf("Expected parse error in repair prompt, got %q", client.requests[1].Prompt)
+	}
+}
+
+func TestLLMJudge_Evaluate_ReasoningMode(t *testing.T) {
+	logger := zerolog.Nop()
+
+	cfg := config.JudgeConfiguration{
+		Name:   "faithfulness",
+		Prompt: "Score: {{.Answer}}",
+		Mode:   config.JudgeModeReasoning,
+		Model:  &config.ModelConfig{MaxTokens: 1024},
+	}
+
+	client := &sequenceLLMClient{responses: []*llm.LLMResponse{
+		{Content: "The answer restates the context.\nNo claim is unsupported.\n<verdict>\n{\"score\": 0.9, \"reason\": \"Grounded\"}\n</verdict>"},
+	}}
+
+	judge, _ := NewLLMJudge(cfg, client, &logger)
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if result.Score != 0.9 || result.Reason != "Grounded" {
+		t.Errorf("Expected verdict 0.9 Grounded, got %f %q", result.Score, result.Reason)
+	}
+	if result.Rationale != "The answer restates the context.\nNo claim is unsupported." {
+		t.Errorf("Expected rationale before the verdict block, got %q", result.Rationale)
+	}
+
+	request := client.requests[0]
+	if request.Schema != nil || request.JSONOutput {
+		t.Error("Expected free-form request for reasoning judge")
+	}
+	if !strings.Contains(request.Prompt, "<verdict>") {
+		t.Errorf("Expected verdict instruction in prompt, got %q", request.Prompt)
+	}
+	if result.Fingerprint == nil || result.Fingerprint.Mode != config.JudgeModeReasoning {
+		t.Errorf("Expected reasoning mode in fingerprint, got %+v", result.Fingerprint)
+	}
+}
+
+func TestLLMJudge_Evaluate_ReasoningOutputPartial(t *testing.T) {
+	logger := zerolog.Nop()
+	partials := map[string]string{
+		config.OutputPartial:          `Respond ONLY in raw JSON: {"score": <float>, "reason": "<{{.}}>"}`,
+		config.ReasoningOutputPartial: "Reason first, then answer in <verdict>{\"score\": <float>, \"reason\": \"<{{.}}>\"}</verdict>",
+	}
+
+	tests := []struct {
+		mode       string
+		wantPrompt string
+		skipPrompt string
+	}{
+		{config.JudgeModeStructured, "structured: Respond ONLY in raw JSON", "<verdict>"},
+		{config.JudgeModeReasoning, "reasoning: Reason first", "Respond ONLY in raw JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := config.JudgeConfiguration{
+				Name:   "faithfulness",
+				Prompt: `{{.Mode}}: {{template "json_output" "grounding"}}`,
+				Mode:   tt.mode,
+				Model:  &config.ModelConfig{MaxTokens: 1024},
+			}
+			judge, err := newLLMJudge(cfg, &MockLLMClient{}, judgeOptions{partials: partials}, &logger)
+			if err != nil {
+				t.Fatalf("newLLMJudge failed: %v", err)
+			}
+
+			prompt, err := judge.buildPrompt(context.Background(), config.PromptData{})
+			if err != nil {
+				t.Fatalf("buildPrompt failed: %v", err)
+			}
+			if !strings.HasPrefix(prompt, tt.wantPrompt) || strings.Contains(prompt, tt.skipPrompt) {
+				t.Errorf("Expected a prompt starting with %q without %q, got %q", tt.wantPrompt, tt.skipPrompt, prompt)
+			}
+			if strings.Count(prompt, "<verdict>") > 1 {
+				t.Errorf("Expected the verdict block to be requested once, got %q", prompt)
+			}
+		})
+	}
+}
+
+func TestLLMJudge_Evaluate_ReasoningMissingVerdict(t *testing.T) {
+	logger := zerolog.Nop()
+
+	cfg := config.JudgeConfiguration{
+		Name:   "faithfulness",
+		Prompt: "Score: {{.Answer}}",
+		Mode:   config.JudgeModeReasoning,
+		Model:  &config.ModelConfig{MaxTokens: 1024},
+	}
+
+	client := &sequenceLLMClient{responses: []*llm.LLMResponse{
+		{Content: "The answer looks fine."},
+		{Content: "Still thinking."},
+	}}
+
+	judge, _ := NewLLMJudge(cfg, client, &logger)
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if result.Score != 0.0 || result.Reason != "Invalid LLM response: verdict block not found" {
+		t.Errorf("Expected missing verdict error, got %f %q", result.Score, result.Reason)
+	}
+	if result.Rationale != "Still thinking." {
+		t.Errorf("Expected rationale of the repaired response, got %q", result.Rationale)
+	}
+	if !strings.Contains(client.requests[1].Prompt, "ending with the verdict block") {
+		t.Errorf("Expected verdict repair instruction, got %q", client.requests[1].Prompt)
+	}
+}
+
+func TestLLMJudge_Evaluate_Truncated(t *testing.T) {
+	logger := zerolog.Nop()
+
+	for _, stopReason := range []string{"max_tokens", "length"} {
+		t.Run(stopReason, func(t *testing.T) {
+			cfg := config.JudgeConfiguration{
+				Name:   "faithfulness",
+				Prompt: "Score: {{.Answer}}",
+				Mode:   config.JudgeModeReasoning,
+				Model:  &config.ModelConfig{MaxTokens: 64},
+			}
+
+			client := &sequenceLLMClient{responses: []*llm.LLMResponse{
+				{Content: "Let me think about", StopReason: stopReason},
+			}}
+
+			judge, _ := NewLLMJudge(cfg, client, &logger)
+			result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+			if result.Reason != "LLM response truncated at max_tokens (64)" {
+				t.Errorf("Expected truncation reason, got %q", result.Reason)
+			}
+			if len(client.requests) != 1 {
+				t.Errorf("Expected no repair for truncated response, got %d calls", len(client.requests))
+			}
+		})
+	}
+}
+
+// promptLLMClient answers with the response of the first key found in the prompt,
+// and is safe for concurrent use
+type promptLLMClient struct {
+	mu        sync.Mutex
+	responses map[string]string
+	prompts   []string
+}
+
+func (m *promptLLMClient) InvokeModel(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prompts = append(m.prompts, request.Prompt)
+	for key, content := range m.responses {
+		if strings.Contains(request.Prompt, key) {
+			return &llm.LLMResponse{Content: content, ModelID: "test-model", Usage: llm.Usage{InputTokens: 10, OutputTokens: 5}}, nil
+		}
+	}
+	return nil, errors.New("unexpected prompt")
+}
+
+func (m *promptLLMClient) InvokeModelWithRetry(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	return m.InvokeModel(ctx, request)
+}
+
+func TestLLMJudge_Evaluate_PerChunk(t *testing.T) {
+	logger := zerolog.Nop()
+	client := &promptLLMClient{responses: map[string]string{
+		"Rolling updates": `{"score": 1.0, "reason": "relevant"}`,
+		"Cafeteria menu":  `{"score": 0.0, "reason": "unrelated"}`,
+	}}
+
+	cfg := config.JudgeConfiguration{
+		Name:     "chunk-relevance",
+		PerChunk: true,
+		Prompt:   "Query: {{.Query}}\nChunk {{.Rank}} ({{.Chunk.ID}}): {{.Chunk.Content}}",
+		Model:    &config.ModelConfig{MaxTokens: 256},
+	}
+	judge, err := NewLLMJudge(cfg, client, &logger)
+	if err != nil {
+		t.Fatalf("NewLLMJudge failed: %v", err)
+	}
+
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{
+		Query:  "How do rolling updates work?",
+		Answer: "They replace pods gradually.",
+		Contexts: []models.ContextChunk{
+			{ID: "doc-1", Content: "Rolling updates replace pods gradually."},
+			{ID: "doc-2", Content: "Cafeteria menu for Monday."},
+		},
+	})
+
+	if result.Score != 0.5 {
+		t.Errorf("Expected mean score 0.5, got %f (%s)", result.Score, result.Reason)
+	}
+	if len(result.ChunkScores) != 2 || result.ChunkScores[1].ID != "doc-2" || result.ChunkScores[1].Rank != 2 || result.ChunkScores[1].Score != 0 {
+		t.Errorf("Unexpected chunk scores %+v", result.ChunkScores)
+	}
+	if result.Usage == nil || result.Usage.InputTokens != 20 {
+		t.Errorf("Expected usage summed over chunks, got %+v", result.Usage)
+	}
+	if !result.Fingerprint.PerChunk || result.Fingerprint.ModelID != "test-model" {
+		t.Errorf("Unexpected fingerprint %+v", result.Fingerprint)
+	}
+	if len(client.prompts) != 2 {
+		t.Errorf("Expected one call per chunk, got %d", len(client.prompts))
+	}
+}
+
+func TestLLMJudge_Evaluate_PerChunkFailure(t *testing.T) {
+	logger := zerolog.Nop()
+	client := &promptLLMClient{responses: map[string]string{"first": `{"score": 1.0, "reason": "relevant"}`}}
+
+	cfg := config.JudgeConfiguration{
+		Name:     "chunk-relevance",
+		PerChunk: true,
+		Prompt:   "{{.Chunk.Content}}",
+		Model:    &config.ModelConfig{MaxTokens: 256},
+	}
+	judge, _ := NewLLMJudge(cfg, client, &logger)
+
+	// Chunks parsed from the context string
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Context: "first chunk\n\nsecond chunk", Answer: "a"})
+	if result.Score != 0 || !strings.Contains(result.Reason, "Failed to judge 1 of 2 chunks (chunk 2: Failed to call LLM)") {
+		t.Errorf("Expected the failed chunk to fail the stage, got %f %q", result.Score, result.Reason)
+	}
+
+	result = judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "a"})
+	if result.Reason != "Context required but not provided" {
+		t.Errorf("Expected missing context, got %q", result.Reason)
+	}
+}
+
+func TestLLMJudge_Evaluate_PerChunkProgress(t *testing.T) {
+	logger := zerolog.Nop()
+	cfg := config.JudgeConfiguration{
+		Name:     "chunk-relevance",
+		PerChunk: true,
+		Prompt:   "{{.Chunk.Content}}",
+		Model:    &config.ModelConfig{MaxTokens: 256},
+	}
+	judge, _ := NewLLMJudge(cfg, &concurrencyLLMClient{}, &logger)
+
+	var done []int
+	ctx := WithUnitProgress(context.Background(), func(judgeName string, unit string, n int, total int) {
+		if judgeName != "chunk-relevance" || unit != "chunk" || total != 3 {
+			t.Errorf("Unexpected progress %s %s %d/%d", judgeName, unit, n, total)
+		}
+		done = append(done, n)
+	})
+	judge.Evaluate(ctx, models.EvaluationContext{Context: "first\n\nsecond\n\nthird", Answer: "a"})
+
+	if len(done) != 3 || done[0] != 1 || done[1] != 2 || done[2] != 3 {
+		t.Errorf("Expected progress 1, 2, 3, got %v", done)
+	}
+}
+
+func TestAveragePrecision(t *testing.T) {
+	tests := []struct {
+		name         string
+		scores       []float64
+		wantScore    float64
+		wantRelevant int
+	}{
+		{"all relevant", []float64{1, 1, 1}, 1.0, 3},
+		{"relevant ranked first", []float64{1, 0.8, 0, 0}, 1.0, 2},
+		{"relevant ranked last", []float64{0, 0.2, 1}, 1.0 / 3, 1},
+		{"interleaved", []float64{1, 0, 1}, (1.0 + 2.0/3) / 2, 2},
+		{"none relevant", []float64{0, 0.4}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores := make([]models.ChunkScore, len(tt.scores))
+			for i, score := range tt.scores {
+				scores[i] = models.ChunkScore{Rank: i + 1, Score: score}
+			}
+
+			score, relevant := averagePrecision(scores)
+			if math.Abs(score-tt.wantScore) > 1e-9 || relevant != tt.wantRelevant {
+				t.Errorf("averagePrecision() = %f, %d, want %f, %d", score, relevant, tt.wantScore, tt.wantRelevant)
+			}
+		})
+	}
+}
+
+func TestLLMJudge_Evaluate_ContextPrecision(t *testing.T) {
+	logger := zerolog.Nop()
+	client := &promptLLMClient{responses: map[string]string{
+		"Rolling updates": `{"score": 1.0, "reason": "relevant"}`,
+		"Cafeteria menu":  `{"score": 0.0, "reason": "unrelated"}`,
+	}}
+
+	cfg := config.JudgeConfiguration{
+		Name:             "context-precision",
+		PerChunk:         true,
+		ChunkAggregation: config.ChunkAggregationAveragePrecision,
+		Prompt:           "Query: {{.Query}}\nChunk: {{.Chunk.Content}}",
+		Model:            &config.ModelConfig{MaxTokens: 256},
+	}
+	judge, err := NewLLMJudge(cfg, client, &logger)
+	if err != nil {
+		t.Fatalf("NewLLMJudge failed: %v", err)
+	}
+
+	// The relevant chunk is ranked second
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{
+		Query:  "How do rolling updates work?",
+		Answer: "They replace pods gradually.",
+		Contexts: []models.ContextChunk{
+			{ID: "doc-2", Content: "Cafeteria menu for Monday."},
+			{ID: "doc-1", Content: "Rolling updates replace pods gradually."},
+		},
+	})
+
+	if result.Score != 0.5 || result.Reason != "Average precision 0.50, 1 of 2 chunks relevant" {
+		t.Errorf("Expected average precision 0.5, got %f (%s)", result.Score, result.Reason)
+	}
+	if result.Fingerprint.Aggregation != config.ChunkAggregationAveragePrecision {
+		t.Errorf("Expected the aggregation in the fingerprint, got %+v", result.Fingerprint)
+	}
+}
+
+func TestLLMJudge_Evaluate_ContextRecall(t *testing.T) {
+	logger := zerolog.Nop()
+	client := &promptLLMClient{responses: map[string]string{
+		"Statement: Rolling updates": `{"score": 1.0, "reason": "stated in chunk 1"}`,
+		"Statement: Use rollout":     `{"score": 0.9, "reason": "stated in chunk 2"}`,
+		"Statement: Consider":        `{"score": 0.0, "reason": "not in the context"}`,
+	}}
+
+	cfg := config.JudgeConfiguration{
+		Name:         "context-recall",
+		PerStatement: true,
+		Prompt:       "Context: {{.Context}}\nStatement: {{.Statement}}",
+		Model:        &config.ModelConfig{MaxTokens: 256},
+	}
+	judge, err := NewLLMJudge(cfg, client, &logger)
+	if err != nil {
+		t.Fatalf("NewLLMJudge failed: %v", err)
+	}
+
+	evalCtx := models.EvaluationContext{
+		Query:     "How do I update and roll back a deployment?",
+		Context:   "[1]\nRolling updates are tuned with maxSurge.\n\n[2]\nThe rollout undo command reverts a deployment.",
+		Answer:    "Use rolling updates and rollout undo.",
+		Reference: "Rolling updates are tuned with maxSurge. Use rollout undo to revert. Consider blue-green deployments.",
+	}
+
+	result := judge.Evaluate(context.Background(), evalCtx)
+	if math.Abs(result.Score-2.0/3) > 1e-9 || result.Reason != "2 of 3 reference statements supported by the context" {
+		t.Errorf("Expected recall 2/3, got %f (%s)", result.Score, result.Reason)
+	}
+	if len(result.Statements) != 3 || result.Statements[2].Supported || result.Statements[2].Statement != "Consider blue-green deployments." {
+		t.Errorf("Unexpected statements %+v", result.Statements)
+	}
+	if result.Usage == nil || result.Usage.InputTokens != 30 {
+		t.Errorf("Expected usage summed over statements, got %+v", result.Usage)
+	}
+
+	evalCtx.Reference = ""
+	result = judge.Evaluate(context.Background(), evalCtx)
+	if result.Score != 0 || result.Reason != "Reference answer required but not provided" {
+		t.Errorf("Expected missing reference, got %f %q", result.Score, result.Reason)
+	}
+}
+
+func TestLLMJudge_Evaluate_Claims(t *testing.T) {
+	logger := zerolog.Nop()
+	client := &promptLLMClient{responses: map[string]string{
+		"Decompose":           `{"claims": ["Paris is the capital of France.", "Paris has 10 million inhabitants.", "The Seine flows through Paris."]}`,
+		"Claim: Paris is the": `{"verdict": "supported", "reason": "stated in the context"}`,
+		"Claim: Paris has":    `{"verdict": "contradicted", "reason": "the context says 2.1 million"}`,
+		"Claim: The Seine":    `{"verdict": "supported", "reason": "stated in the context"}`,
+	}}
+
+	cfg := config.JudgeConfiguration{
+		Name:         "claim-faithfulness",
+		PerClaim:     true,
+		ClaimsPrompt: "Decompose the answer into claims: {{.Answer}}",
+		Prompt:       "Context: {{.Context}}\nClaim: {{.Claim}}",
+		Model:        &config.ModelConfig{MaxTokens: 256},
+	}
+	judge, err := NewLLMJudge(cfg, client, &logger)
+	if err != nil {
+		t.Fatalf("NewLLMJudge failed: %v", err)
+	}
+
+	evalCtx := models.EvaluationContext{
+		Query:   "Tell me about Paris",
+		Context: "Paris, the capital of France, has 2.1 million inhabitants. The Seine flows through it.",
+		Answer:  "Paris is the capital of France, with 10 million inhabitants, on the Seine.",
+	}
+
+	result := judge.Evaluate(context.Background(), evalCtx)
+	if math.Abs(result.Score-2.0/3) > 1e-9 {
+		t.Errorf("Expected score 2/3, got %f (%s)", result.Score, result.Reason)
+	}
+	if !strings.Contains(result.Reason, `unsupported: "Paris has 10 million inhabitants." (contradicted)`) {
+		t.Errorf("Expected the contradicted claim in the reason, got %q", result.Reason)
+	}
+	if len(result.Claims) != 3 || result.Claims[1].Verdict != models.ClaimContradicted || result.Claims[1].Claim != "Paris has 10 million inhabitants." {
+		t.Errorf("Unexpected claims %+v", result.Claims)
+	}
+	if result.Usage == nil || result.Usage.InputTokens != 40 {
+		t.Errorf("Expected usage of the decomposition and the 3 verifications, got %+v", result.Usage)
+	}
+	if !result.Fingerprint.PerClaim {
+		t.Errorf("Expected per_claim in the fingerprint, got %+v", result.Fingerprint)
+	}
+}
+
+func TestLLMJudge_Evaluate_MaxClaims(t *testing.T) {
+	logger := zerolog.Nop()
+	client := &promptLLMClient{responses: map[string]string{
+		"Decompose":           `{"claims": ["Paris is the capital of France.", "Paris has 10 million inhabitants.", "The Seine flows through Paris."]}`,
+		"Claim: Paris is the": `{"verdict": "supported", "reason": "stated in the context"}`,
+		"Claim: Paris has":    `{"verdict": "contradicted", "reason": "the context says 2.1 million"}`,
+	}}
+
+	cfg := config.JudgeConfiguration{
+		Name:         "claim-faithfulness",
+		PerClaim:     true,
+		MaxClaims:    2,
+		ClaimsPrompt: "Decompose the answer into claims: {{.Answer}}",
+		Prompt:       "Claim: {{.Claim}}",
+		Model:        &config.ModelConfig{MaxTokens: 256},
+	}
+	judge, _ := NewLLMJudge(cfg, client, &logger)
+	evalCtx := models.EvaluationContext{Context: "Paris facts.", Answer: "Paris is the capital of France, with 10 million inhabitants, on the Seine."}
+
+	result := judge.Evaluate(context.Background(), evalCtx)
+	if result.Score != 0.5 || len(result.Claims) != 2 {
+		t.Errorf("Expected the first 2 claims verified, got %f %+v", result.Score, result.Claims)
+	}
+	if !strings.HasSuffix(result.Reason, "; 1 further claims not verified (max_claims 2)") {
+		t.Errorf("Expected the skipped claim in the reason, got %q", result.Reason)
+	}
+	if len(client.prompts) != 3 {
+		t.Errorf("Expected the decomposition and 2 verifications, got %d calls", len(client.prompts))
+	}
+	if timeout := judge.Timeout(evalCtx); timeout != 2*DefaultJudgeTimeout {
+		t.Errorf("Expected the decomposition and one wave of verifications, got %s", timeout)
+	}
+}
+
+func TestLLMJudge_Evaluate_ClaimsFailures(t *testing.T) {
+	logger := zerolog.Nop()
+	cfg := config.JudgeConfiguration{
+		Name:         "claim-faithfulness",
+		PerClaim:     true,
+		ClaimsPrompt: "Decompose: {{.Answer}}",
+		Prompt:       "Claim: {{.Claim}}",
+		Model:        &config.ModelConfig{MaxTokens: 256},
+	}
+	evalCtx := models.EvaluationContext{Context: "Some context.", Answer: "Some answer."}
+
+	tests := []struct {
+		name       string
+		responses  map[string]string
+		wantScore  float64
+		wantReason string
+	}{
+		{
+			name:       "no claims",
+			responses:  map[string]string{"Decompose": `{"claims": []}`},
+			wantScore:  1.0,
+			wantReason: "Answer makes no verifiable claims",
+		},
+		{
+			name:       "invalid decomposition",
+			responses:  map[string]string{"Decompose": `{"claims": "one"}`},
+			wantReason: "Failed to decompose the answer into claims: Invalid LLM response",
+		},
+		{
+			name:       "invalid verdict",
+			responses:  map[string]string{"Decompose": `{"claims": ["a claim"]}`, "Claim:": `{"verdict": "maybe", "reason": "unsure"}`},
+			wantReason: "Failed to verify 1 of 1 claims (claim 1: Invalid LLM response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			judge, err := NewLLMJudge(cfg, &promptLLMClient{responses: tt.responses}, &logger)
+			if err != nil {
+				t.Fatalf("NewLLMJudge failed: %v", err)
+			}
+
+			result := judge.Evaluate(context.Background(), evalCtx)
+			if result.Score != tt.wantScore || !strings.HasPrefix(result.Reason, tt.wantReason) {
+				t.Errorf("Expected %f %q, got %f %q", tt.wantScore, tt.wantReason, result.Score, result.Reason)
+			}
+		})
+	}
+}
+
+// concurrencyLLMClient records the highest number of concurrent calls
+type concurrencyLLMClient struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (m *concurrencyLLMClient) InvokeModel(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	m.mu.Lock()
+	m.running++
+	m.peak = max(m.peak, m.running)
+	m.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	m.mu.Lock()
+	m.running--
+	m.mu.Unlock()
+	return &llm.LLMResponse{Content: `{"score": 1.0, "reason": "relevant"}`}, nil
+}
+
+func (m *concurrencyLLMClient) InvokeModelWithRetry(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	return m.InvokeModel(ctx, request)
+}
+
+func TestLLMJudge_Evaluate_PerChunkConcurrency(t *testing.T) {
+	logger := zerolog.Nop()
+	client := &concurrencyLLMClient{}
+
+	cfg := config.JudgeConfiguration{
+		Name:        "chunk-relevance",
+		PerChunk:    true,
+		Concurrency: 2,
+		Prompt:      "{{.Chunk.Content}}",
+		Model:       &config.ModelConfig{MaxTokens: 256},
+	}
+	judge, _ := NewLLMJudge(cfg, client, &logger)
+
+	contexts := make([]models.ContextChunk, 5)
+	for i := range contexts {
+		contexts[i] = models.ContextChunk{Content: fmt.Sprintf("chunk %d", i)}
+	}
+	evalCtx := models.EvaluationContext{Answer: "a", Contexts: contexts}
+
+	result := judge.Evaluate(context.Background(), evalCtx)
+	if result.Score != 1.0 || len(result.ChunkScores) != 5 {
+		t.Errorf("Expected all chunks judged, got %f %q", result.Score, result.Reason)
+	}
+	if client.peak != 2 {
+		t.Errorf("Expected at most 2 concurrent calls, got %d", client.peak)
+	}
+	if timeout := judge.Timeout(evalCtx); timeout != 3*DefaultJudgeTimeout {
+		t.Errorf("Expected the timeout scaled to 3 waves, got %s", timeout)
+	}
+
+	cfg.Timeout = time.Minute
+	judge, _ = NewLLMJudge(cfg, client, &logger)
+	if timeout := judge.Timeout(evalCtx); timeout != time.Minute {
+		t.Errorf("Expected the configured timeout, got %s", timeout)
+	}
+}
+
+func TestLLMJudge_Evaluate_PerChunkCanceled(t *testing.T) {
+	logger := zerolog.Nop()
+	cfg := config.JudgeConfiguration{
+		Name:     "chunk-relevance",
+		PerChunk: true,
+		Prompt:   "{{.Chunk.Content}}",
+		Model:    &config.ModelConfig{MaxTokens: 256},
+	}
+	judge, _ := NewLLMJudge(cfg, &concurrencyLLMClient{}, &logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := judge.Evaluate(ctx, models.EvaluationContext{Answer: "a", Context: "first chunk\n\nsecond chunk"})
+	if result.Score != 0 || !strings.Contains(result.Reason, "Not judged before the evaluation was canceled") {
+		t.Errorf("Expected the chunks not to be judged, got %f %q", result.Score, result.Reason)
+	}
+}
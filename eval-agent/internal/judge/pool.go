@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
 	"github.com/rs/zerolog"
 )
@@ -11,6 +12,9 @@ import (
 // JudgePool builds and manages a collection of judges from configuration
 type JudgePool struct {
 	llmClient llm.LLMClient
+	registry  *llm.Registry
+	embedder  embedding.Client
+	pricing   *config.PricingConfig
 	logger    *zerolog.Logger
 }
 
@@ -22,6 +26,31 @@ func NewJudgePool(llmClient llm.LLMClient, logger *zerolog.Logger) *JudgePool {
 	}
 }
 
+// EmbeddingClient returns the embedding client of the pool, or nil if none is set
+func (p *JudgePool) EmbeddingClient() embedding.Client {
+	return p.embedder
+}
+
+// WithEmbeddingClient sets the embedding client used by judges selecting
+// few-shot examples by embedding similarity
+func (p *JudgePool) WithEmbeddingClient(embedder embedding.Client) *JudgePool {
+	p.embedder = embedder
+	return p
+}
+
+// WithRegistry resolves each judge's provider, model and fallbacks through the
+// registry. Without a registry every judge uses the pool's LLM client.
+func (p *JudgePool) WithRegistry(registry *llm.Registry) *JudgePool {
+	p.registry = registry
+	return p
+}
+
+// WithPricing sets the price table judges use to report the cost of their LLM calls
+func (p *JudgePool) WithPricing(pricing *config.PricingConfig) *JudgePool {
+	p.pricing = pricing
+	return p
+}
+
 func (p *JudgePool) BuildFromConfig(cfg *config.JudgesConfig) ([]Judge, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("judges config is nil")
@@ -38,8 +67,31 @@ func (p *JudgePool) BuildFromConfig(cfg *config.JudgesConfig) ([]Judge, error) {
 			continue
 		}
 
+		if !judgeCfg.IsLLM() {
+			judge, err := defaultRegistry.Build(judgeCfg, Dependencies{Embedder: p.embedder, Logger: p.logger})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create judge %s: %w", judgeCfg.Name, err)
+			}
+			judges = append(judges, judge)
+
+			p.logger.Info().
+				Str("judge", judgeCfg.Name).
+				Str("type", judgeCfg.Type).
+				Msg("judge created successfully")
+			continue
+		}
+
+		llmClient, err := p.clientFor(judgeCfg.Model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create LLM client for judge %s: %w", judgeCfg.Name, err)
+		}
+
 		// Create LLM judge
-		judge, err := NewLLMJudge(judgeCfg, p.llmClient, p.logger)
+		judge, err := newLLMJudge(judgeCfg, llmClient, judgeOptions{
+			partials: cfg.Judges.Partials,
+			embedder: p.embedder,
+			pricing:  p.pricing,
+		}, p.logger)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create judge %s: %w", judgeCfg.Name, err)
 		}
@@ -48,6 +100,9 @@ func (p *JudgePool) BuildFromConfig(cfg *config.JudgesConfig) ([]Judge, error) {
 
 		p.logger.Info().
 			Str("judge", judgeCfg.Name).
+			Str("provider", judgeCfg.Model.Provider).
+			Str("model_id", judgeCfg.Model.ModelID).
+			Int("fallbacks", len(judgeCfg.Model.Fallbacks)).
 			Int("max_tokens", judgeCfg.Model.MaxTokens).
 			Float64("temperature", judgeCfg.Model.Temperature).
 			Bool("retry", judgeCfg.Model.Retry).
@@ -65,3 +120,37 @@ func (p *JudgePool) BuildFromConfig(cfg *config.JudgesConfig) ([]Judge, error) {
 
 	return judges, nil
 }
+
+// clientFor resolves the judge's model and its fallback chain
+func (p *JudgePool) clientFor(model *config.ModelConfig) (llm.LLMClient, error) {
+	if p.registry == nil || model == nil {
+		return p.llmClient, nil
+	}
+
+	refs := append([]config.ModelRef{{Provider: model.Provider, ModelID: model.ModelID}}, model.Fallbacks...)
+
+	chain := make([]llm.NamedClient, 0, len(refs))
+	for _, ref := range refs {
+		client, err := p.registry.Client(ref.Provider, ref.ModelID)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, llm.NamedClient{Name: modelName(ref), Client: client})
+	}
+
+	if len(chain) == 1 {
+		return chain[0].Client, nil
+	}
+	return llm.NewFallbackClient(chain, p.logger), nil
+}
+
+func modelName(ref config.ModelRef) string {
+	name := ref.Provider
+	if name == "" {
+		name = "default"
+	}
+	if ref.ModelID != "" {
+		name += "/" + ref.ModelID
+	}
+	return name
+}
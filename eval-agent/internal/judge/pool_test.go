@@ -1,9 +1,14 @@
 package judge
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 )
 
@@ -199,3 +204,105 @@ func TestJudgePool_BuildFromConfig_InvalidJudge(t *testing.T) {
 		t.Errorf("Expected error to mention 'bad-judge', got: %v", err)
 	}
 }
+
+func TestJudgePool_BuildFromConfig_WithRegistry(t *testing.T) {
+	logger := zerolog.Nop()
+
+	sonnet := &MockLLMClient{ErrorToReturn: &llm.ProviderError{StatusCode: 429, Transient: true, Err: errors.New("ThrottlingException")}}
+	gpt := &MockLLMClient{ResponseToReturn: &llm.LLMResponse{Content: `{"score": 0.9, "reason": "ok"}`, ModelID: "gpt-4o"}}
+	haiku := &MockLLMClient{ResponseToReturn: &llm.LLMResponse{Content: `{"score": 0.5, "reason": "ok"}`, ModelID: "haiku"}}
+
+	registry := llm.NewRegistry("bedrock")
+	registry.Register("bedrock", "sonnet", sonnet)
+	registry.Register("openai", "gpt-4o", gpt)
+	registry.Register("bedrock", "", haiku)
+
+	cfg := &config.JudgesConfig{
+		Judges: config.Judges{
+			Evaluators: []config.JudgeConfiguration{
+				{
+					Name:    "faithfulness",
+					Enabled: true,
+					Prompt:  "Score: {{.Answer}}",
+					Model: &config.ModelConfig{
+						Provider:  "bedrock",
+						ModelID:   "sonnet",
+						MaxTokens: 256,
+						Fallbacks: []config.ModelRef{{Provider: "openai", ModelID: "gpt-4o"}},
+					},
+				},
+				{
+					Name:    "coherence",
+					Enabled: true,
+					Prompt:  "Score: {{.Answer}}",
+					Model:   &config.ModelConfig{MaxTokens: 256},
+				},
+			},
+		},
+	}
+
+	judges, err := NewJudgePool(haiku, &logger).WithRegistry(registry).BuildFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("BuildFromConfig failed: %v", err)
+	}
+
+	evalCtx := models.EvaluationContext{Query: "q", Answer: "a"}
+
+	faithfulness := judges[0].Evaluate(context.Background(), evalCtx)
+	if !sonnet.WasCalled || faithfulness.Score != 0.9 || faithfulness.Fingerprint.ModelID != "gpt-4o" {
+		t.Errorf("Expected throttled sonnet to fall back to gpt-4o, got %+v", faithfulness)
+	}
+
+	coherence := judges[1].Evaluate(context.Background(), evalCtx)
+	if coherence.Score != 0.5 || coherence.Fingerprint.ModelID != "haiku" {
+		t.Errorf("Expected coherence on the default model, got %+v", coherence)
+	}
+
+	cfg.Judges.Evaluators[1].Model.Provider = "vertex"
+	if _, err := NewJudgePool(haiku, &logger).WithRegistry(registry).BuildFromConfig(cfg); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+}
+
+func TestJudgePool_BuildFromConfig_CodeJudges(t *testing.T) {
+	logger := zerolog.Nop()
+	mockClient := &MockLLMClient{}
+
+	cfg := &config.JudgesConfig{
+		Judges: config.Judges{
+			Evaluators: []config.JudgeConfiguration{
+				{
+					Name:    "mentions-product",
+					Type:    "contains",
+					Enabled: true,
+					Params:  map[string]any{"values": []any{"Acme"}},
+				},
+				{
+					Name:    "relevance",
+					Enabled: true,
+					Prompt:  "Score: {{.Answer}}",
+					Model:   &config.ModelConfig{MaxTokens: 256},
+				},
+			},
+		},
+	}
+
+	judges, err := NewJudgePool(mockClient, &logger).BuildFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("BuildFromConfig failed: %v", err)
+	}
+	if len(judges) != 2 {
+		t.Fatalf("Expected 2 judges, got %d", len(judges))
+	}
+	if _, ok := judges[0].(*ContainsJudge); !ok {
+		t.Errorf("Expected a contains judge, got %T", judges[0])
+	}
+	if _, ok := judges[1].(*LLMJudge); !ok {
+		t.Errorf("Expected an LLM judge, got %T", judges[1])
+	}
+
+	cfg.Judges.Evaluators[0].Type = "sql"
+	if _, err := NewJudgePool(mockClient, &logger).BuildFromConfig(cfg); err == nil || !strings.Contains(err.Error(), "unknown judge type: sql") {
+		t.Errorf("Expected unknown type error, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package judge
+
+import (
+	"context"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// ProgressFunc is called by a runner each time one of its judges completes, with
+// the number of judges completed so far and the number of judges run. Calls are
+// sequential and done increases by one per call.
+type ProgressFunc func(result models.StageResult, done int, total int)
+
+type progressKey struct{}
+
+// WithProgress returns a context reporting the progress of the judge runs made
+// with it to fn
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressFromContext returns the progress function of ctx, or nil if none
+func progressFromContext(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// UnitProgressFunc is called by a per-unit judge (per chunk, per statement or
+// per claim) each time one of its units is judged, with the kind of unit, the
+// number of units judged so far and the number of units to judge. Calls are
+// sequential and done increases by one per call.
+type UnitProgressFunc func(judgeName string, unit string, done int, total int)
+
+type unitProgressKey struct{}
+
+// WithUnitProgress returns a context reporting the progress of the per-unit
+// judges evaluated with it to fn
+func WithUnitProgress(ctx context.Context, fn UnitProgressFunc) context.Context {
+	return context.WithValue(ctx, unitProgressKey{}, fn)
+}
+
+// unitProgressFromContext returns the unit progress function of ctx, or nil if none
+func unitProgressFromContext(ctx context.Context) UnitProgressFunc {
+	fn, _ := ctx.Value(unitProgressKey{}).(UnitProgressFunc)
+	return fn
+}
@@ -0,0 +1,61 @@
+package judge
+
+import (
+	"fmt"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/registry"
+)
+
+// Params are the type specific parameters of a code judge configuration
+type Params = config.Params
+
+// Dependencies are the shared clients available to judge factories
+type Dependencies = registry.Dependencies
+
+// Factory creates a code judge with the configured name from its parameters
+type Factory func(name string, params Params, deps Dependencies) (Judge, error)
+
+// Registry maps code judge type names to factories. LLM judges (type llm) are
+// built by the pool and are not part of the registry.
+type Registry struct {
+	*registry.Registry[Factory]
+}
+
+func NewRegistry() *Registry {
+	return &Registry{registry.New[Factory]()}
+}
+
+// Build creates the code judge of the configuration
+func (r *Registry) Build(judgeCfg config.JudgeConfiguration, deps Dependencies) (Judge, error) {
+	factory, ok := r.Lookup(judgeCfg.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown judge type: %s", judgeCfg.Type)
+	}
+
+	return factory(judgeCfg.Name, Params(judgeCfg.Params), deps)
+}
+
+// defaultRegistry holds the built-in code judges and those added with Register
+var defaultRegistry = newBuiltinRegistry()
+
+// Register adds a judge type to the default registry, typically from an init function
+func Register(typeName string, factory Factory) {
+	defaultRegistry.Register(typeName, factory)
+}
+
+// Types returns the code judge types of the default registry
+func Types() []string {
+	return defaultRegistry.Types()
+}
+
+func newBuiltinRegistry() *Registry {
+	r := NewRegistry()
+	r.Register("regex", newRegexJudgeFromParams)
+	r.Register("json_schema", newJSONSchemaJudgeFromParams)
+	r.Register("contains", newContainsJudgeFromParams(false))
+	r.Register("not_contains", newContainsJudgeFromParams(true))
+	r.Register("numeric", newNumericJudgeFromParams)
+	r.Register("exec", newExecJudgeFromParams)
+	return r
+}
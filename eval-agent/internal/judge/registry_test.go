@@ -0,0 +1,91 @@
+package judge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// constantJudge scores every evaluation the same
+type constantJudge struct {
+	name  string
+	score float64
+}
+
+func (j *constantJudge) Name() string {
+	return j.name
+}
+
+func (j *constantJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContext) models.StageResult {
+	return models.StageResult{Name: j.name + "-judge", Score: j.score}
+}
+
+func TestRegistry_Build(t *testing.T) {
+	logger := zerolog.Nop()
+
+	registry := newBuiltinRegistry()
+	registry.Register("constant", func(name string, params Params, deps Dependencies) (Judge, error) {
+		score, err := params.Float("score", 1.0)
+		if err != nil {
+			return nil, err
+		}
+		return &constantJudge{name: name, score: score}, nil
+	})
+
+	judge, err := registry.Build(config.JudgeConfiguration{Name: "always", Type: "constant", Params: map[string]any{"score": 0.25}}, Dependencies{Logger: &logger})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if judge.Name() != "always" || judge.Evaluate(context.Background(), models.EvaluationContext{}).Score != 0.25 {
+		t.Errorf("Expected constant judge built with its name and params, got %+v", judge)
+	}
+
+	tests := []struct {
+		name    string
+		judge   config.JudgeConfiguration
+		wantErr string
+	}{
+		{"unknown type", config.JudgeConfiguration{Name: "x", Type: "sql"}, "unknown judge type: sql"},
+		{"unknown param", config.JudgeConfiguration{Name: "x", Type: "regex", Params: map[string]any{"pattern": "a", "flags": "i"}}, "unknown param flags"},
+		{"missing pattern", config.JudgeConfiguration{Name: "x", Type: "regex"}, "param pattern is required"},
+		{"invalid pattern", config.JudgeConfiguration{Name: "x", Type: "regex", Params: map[string]any{"pattern": "("}}, "param pattern is invalid"},
+		{"missing schema", config.JudgeConfiguration{Name: "x", Type: "json_schema"}, "param schema is required"},
+		{"unsupported schema keyword", config.JudgeConfiguration{Name: "x", Type: "json_schema", Params: map[string]any{"schema": map[string]any{
+			"type": "object", "properties": map[string]any{"id": map[string]any{"type": "string", "pattern": "^[a-z]+$"}},
+		}}}, "schema.properties.id: unsupported keyword pattern"},
+		{"missing values", config.JudgeConfiguration{Name: "x", Type: "contains"}, "param values is required"},
+		{"numeric pattern without group", config.JudgeConfiguration{Name: "x", Type: "numeric", Params: map[string]any{"pattern": `\d+`}}, "capture group"},
+		{"missing command", config.JudgeConfiguration{Name: "x", Type: "exec"}, "param command is required"},
+		{"invalid timeout", config.JudgeConfiguration{Name: "x", Type: "exec", Params: map[string]any{"command": []any{"true"}, "timeout": "soon"}}, "param timeout is invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := registry.Build(tt.judge, Dependencies{Logger: &logger})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %q error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRegister_DefaultRegistry(t *testing.T) {
+	Register("test-constant", func(name string, params Params, deps Dependencies) (Judge, error) {
+		return &constantJudge{name: name, score: 1.0}, nil
+	})
+
+	types := Types()
+	for _, typeName := range []string{"contains", "exec", "json_schema", "not_contains", "numeric", "regex", "test-constant"} {
+		found := false
+		for _, registered := range types {
+			found = found || registered == typeName
+		}
+		if !found {
+			t.Errorf("Expected type %s in %v", typeName, types)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package judge
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// ConfigVersion identifies the judges configuration a JudgeSet was built from
+type ConfigVersion struct {
+	Version  int       `json:"version" description:"Reload counter, starting at 1 for the configuration loaded at startup"`
+	Hash     string    `json:"hash" description:"SHA-256 of the resolved judges configuration"`
+	LoadedAt time.Time `json:"loaded_at" description:"Time the configuration was activated"`
+}
+
+// JudgeSet is the immutable set of judges built from one judges configuration.
+// The runner and the factory share the same judge instances.
+type JudgeSet struct {
+	Runner  *JudgeRunner
+	Factory *JudgeFactory
+	Config  *config.JudgesConfig
+	Version ConfigVersion
+}
+
+// NewJudgeSet builds the runner and factory for the judges of a configuration
+func NewJudgeSet(judges []Judge, cfg *config.JudgesConfig, version int, logger *zerolog.Logger) (*JudgeSet, error) {
+	hash, err := cfg.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	return &JudgeSet{
+		Runner:  NewJudgeRunner(judges, logger),
+		Factory: NewJudgeFactory(judges, logger),
+		Config:  cfg,
+		Version: ConfigVersion{
+			Version:  version,
+			Hash:     hash,
+			LoadedAt: time.Now(),
+		},
+	}, nil
+}
+
+// ReloadableJudges serves evaluations from the active JudgeSet. Swapping the set
+// is atomic: an evaluation runs entirely against the set that was active when it
+// started, so in-flight requests are never mixed across configurations.
+type ReloadableJudges struct {
+	active atomic.Pointer[JudgeSet]
+}
+
+func NewReloadableJudges(set *JudgeSet) *ReloadableJudges {
+	r := &ReloadableJudges{}
+	r.active.Store(set)
+	return r
+}
+
+// Active returns the judge set currently serving evaluations
+func (r *ReloadableJudges) Active() *JudgeSet {
+	return r.active.Load()
+}
+
+// Swap activates a new judge set and returns the previous one
+func (r *ReloadableJudges) Swap(set *JudgeSet) *JudgeSet {
+	return r.active.Swap(set)
+}
+
+func (r *ReloadableJudges) Run(ctx context.Context, evaluationContext models.EvaluationContext) []models.StageResult {
+	results, _ := r.RunVersioned(ctx, evaluationContext)
+	return results
+}
+
+// RunVersioned runs all judges of the active set and returns the hash of the
+// configuration they were built from
+func (r *ReloadableJudges) RunVersioned(ctx context.Context, evaluationContext models.EvaluationContext) ([]models.StageResult, string) {
+	set := r.Active()
+	return set.Runner.Run(ctx, evaluationContext), set.Version.Hash
+}
+
+// Select returns a runner for the named judges of the active set together with
+// the hash of the configuration they were built from
+func (r *ReloadableJudges) Select(names []string) (*JudgeRunner, string, error) {
+	set := r.Active()
+	runner, err := set.Runner.Select(names)
+	return runner, set.Version.Hash, err
+}
+
+func (r *ReloadableJudges) Get(judgeName string) (Judge, error) {
+	judge, _, err := r.GetVersioned(judgeName)
+	return judge, err
+}
+
+// GetVersioned returns the named judge of the active set together with the hash
+// of the configuration it was built from
+func (r *ReloadableJudges) GetVersioned(judgeName string) (Judge, string, error) {
+	set := r.Active()
+	judge, err := set.Factory.Get(judgeName)
+	return judge, set.Version.Hash, err
+}
+
+// Catalog describes the judges of the active configuration
+func (r *ReloadableJudges) Catalog() Catalog {
+	set := r.Active()
+	return NewCatalog(set.Config, set.Version)
+}
+
+// ConfigHash returns the hash of the active configuration
+func (r *ReloadableJudges) ConfigHash() string {
+	return r.Active().Version.Hash
+}
@@ -0,0 +1,103 @@
+package judge
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+type staticJudge struct {
+	name  string
+	score float64
+}
+
+func (j *staticJudge) Name() string { return j.name }
+
+func (j *staticJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContext) models.StageResult {
+	return models.StageResult{Name: j.name, Score: j.score}
+}
+
+func newTestJudgeSet(t *testing.T, prompt string, version int, judges ...Judge) *JudgeSet {
+	t.Helper()
+	logger := zerolog.Nop()
+
+	cfg := &config.JudgesConfig{
+		Judges: config.Judges{
+			Evaluators: []config.JudgeConfiguration{{Name: "relevance", Enabled: true, Prompt: prompt}},
+		},
+	}
+
+	set, err := NewJudgeSet(judges, cfg, version, &logger)
+	if err != nil {
+		t.Fatalf("NewJudgeSet failed: %v", err)
+	}
+	return set
+}
+
+func TestReloadableJudges_Swap(t *testing.T) {
+	first := newTestJudgeSet(t, "v1 {{.Answer}}", 1, &staticJudge{name: "relevance-judge", score: 0.2})
+	second := newTestJudgeSet(t, "v2 {{.Answer}}", 2, &staticJudge{name: "relevance-judge", score: 0.9})
+
+	if first.Version.Hash == second.Version.Hash {
+		t.Fatal("Expected different hashes for different prompts")
+	}
+
+	judges := NewReloadableJudges(first)
+	evalCtx := models.EvaluationContext{RequestID: "test-001", Query: "q", Answer: "a", CreatedAt: time.Now()}
+
+	results, hash := judges.RunVersioned(context.Background(), evalCtx)
+	if len(results) != 1 || results[0].Score != 0.2 || hash != first.Version.Hash {
+		t.Errorf("Expected first set results, got %+v (hash %s)", results, hash)
+	}
+
+	if previous := judges.Swap(second); previous != first {
+		t.Error("Expected Swap to return the previous set")
+	}
+
+	results, hash = judges.RunVersioned(context.Background(), evalCtx)
+	if len(results) != 1 || results[0].Score != 0.9 || hash != second.Version.Hash {
+		t.Errorf("Expected second set results, got %+v (hash %s)", results, hash)
+	}
+
+	judge, hash, err := judges.GetVersioned("relevance-judge")
+	if err != nil {
+		t.Fatalf("GetVersioned failed: %v", err)
+	}
+	if judge.Evaluate(context.Background(), evalCtx).Score != 0.9 || hash != second.Version.Hash {
+		t.Error("Expected GetVersioned to serve the active set")
+	}
+
+	if _, err := judges.Get("unknown"); err == nil {
+		t.Error("Expected error for unknown judge")
+	}
+}
+
+func TestReloadableJudges_Select(t *testing.T) {
+	set := newTestJudgeSet(t, "v1 {{.Answer}}", 1,
+		&staticJudge{name: "relevance", score: 0.2},
+		&staticJudge{name: "coherence", score: 0.9},
+	)
+	judges := NewReloadableJudges(set)
+	evalCtx := models.EvaluationContext{RequestID: "test-001", Query: "q", Answer: "a", CreatedAt: time.Now()}
+
+	runner, hash, err := judges.Select([]string{"coherence"})
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if hash != set.Version.Hash {
+		t.Errorf("Expected hash %s, got %s", set.Version.Hash, hash)
+	}
+	results := runner.Run(context.Background(), evalCtx)
+	if len(results) != 1 || results[0].Name != "coherence" {
+		t.Errorf("Expected only the coherence judge to run, got %+v", results)
+	}
+
+	if _, _, err := judges.Select([]string{"coherence", "unknown"}); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("Expected error naming the unknown judge, got %v", err)
+	}
+}
@@ -2,13 +2,32 @@ package judge
 
 import (
 	"context"
-	"sync"
+	"fmt"
 	"time"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 )
 
+// DefaultJudgeTimeout bounds the evaluation of judges without a timeout of their own
+const DefaultJudgeTimeout = 15 * time.Second
+
+// timeoutJudge is implemented by judges whose timeout depends on their
+// configuration or on the evaluated interaction
+type timeoutJudge interface {
+	Timeout(evalCtx models.EvaluationContext) time.Duration
+}
+
+// judgeTimeout returns the timeout of the judge for the interaction
+func judgeTimeout(j Judge, evalCtx models.EvaluationContext) time.Duration {
+	if tj, ok := j.(timeoutJudge); ok {
+		if timeout := tj.Timeout(evalCtx); timeout > 0 {
+			return timeout
+		}
+	}
+	return DefaultJudgeTimeout
+}
+
 type JudgeRunner struct {
 	Judges []Judge
 	logger *zerolog.Logger
@@ -21,19 +40,34 @@ func NewJudgeRunner(judges []Judge, logger *zerolog.Logger) *JudgeRunner {
 	}
 }
 
+// Select returns a runner for the named judges. It fails on a name that is not
+// one of the runner's judges.
+func (c *JudgeRunner) Select(names []string) (*JudgeRunner, error) {
+	byName := make(map[string]Judge, len(c.Judges))
+	for _, j := range c.Judges {
+		byName[j.Name()] = j
+	}
+
+	judges := make([]Judge, 0, len(names))
+	for _, name := range names {
+		j, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("judge not found: %s", name)
+		}
+		judges = append(judges, j)
+	}
+	return NewJudgeRunner(judges, c.logger), nil
+}
+
 func (c *JudgeRunner) Run(ctx context.Context, evaluationContext models.EvaluationContext) []models.StageResult {
 	results := make(chan models.StageResult, len(c.Judges))
-	var wg sync.WaitGroup
-
-	judgeTimeout := 15 * time.Second
 
 	for _, judge := range c.Judges {
-		wg.Add(1)
 		go func(j Judge) {
-			defer wg.Done()
+			timeout := judgeTimeout(j, evaluationContext)
 
 			// Create a context with timeout to block the queue
-			judgeCtx, cancel := context.WithTimeout(ctx, judgeTimeout)
+			judgeCtx, cancel := context.WithTimeout(ctx, timeout)
 			defer cancel()
 
 			// run the judge with timeout
@@ -43,15 +77,18 @@ func (c *JudgeRunner) Run(ctx context.Context, evaluationContext models.Evaluati
 			if judgeCtx.Err() == context.DeadlineExceeded {
 				c.logger.Warn().
 					Str("judge_name", evalResult.Name).
-					Dur("timeout", judgeTimeout).
+					Dur("timeout", timeout).
 					Msg("Judge evaluation timed out")
 
-				// Return a failed result instead of blocking
+				// Return a failed result instead of blocking. The calls completed
+				// before the timeout were paid for, so their usage is kept.
 				evalResult = models.StageResult{
-					Name:     evalResult.Name,
-					Score:    0.0,
-					Reason:   "evaluation timed out after " + judgeTimeout.String(),
-					Duration: judgeTimeout,
+					Name:        evalResult.Name,
+					Score:       0.0,
+					Reason:      "evaluation timed out after " + timeout.String(),
+					Duration:    timeout,
+					Fingerprint: evalResult.Fingerprint,
+					Usage:       evalResult.Usage,
 				}
 			}
 
@@ -59,12 +96,16 @@ func (c *JudgeRunner) Run(ctx context.Context, evaluationContext models.Evaluati
 		}(judge)
 	}
 
-	wg.Wait()
-	close(results)
-
+	// Collect the results as the judges complete, so that progress is reported
+	// while the slower judges are still running
+	progress := progressFromContext(ctx)
 	var stageResults []models.StageResult
-	for result := range results {
+	for range c.Judges {
+		result := <-results
 		stageResults = append(stageResults, result)
+		if progress != nil {
+			progress(result, len(stageResults), len(c.Judges))
+		}
 	}
 	c.logger.Debug().Int("judgeCount", len(stageResults)).Msg("all judges completed")
 
@@ -0,0 +1,48 @@
+package judge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func TestJudgeRunner_ReportsProgress(t *testing.T) {
+	logger := zerolog.Nop()
+	runner := NewJudgeRunner([]Judge{
+		&staticJudge{name: "relevance", score: 0.9},
+		&staticJudge{name: "coherence", score: 0.8},
+		&staticJudge{name: "faithfulness", score: 0.7},
+	}, &logger)
+
+	var done []int
+	completed := make(map[string]bool)
+	ctx := WithProgress(context.Background(), func(result models.StageResult, n int, total int) {
+		if total != 3 {
+			t.Errorf("Expected a total of 3 judges, got %d", total)
+		}
+		done = append(done, n)
+		completed[result.Name] = true
+	})
+
+	results := runner.Run(ctx, models.EvaluationContext{})
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if len(done) != 3 || done[0] != 1 || done[1] != 2 || done[2] != 3 {
+		t.Errorf("Expected progress 1, 2, 3, got %v", done)
+	}
+	if len(completed) != 3 {
+		t.Errorf("Expected progress for every judge, got %v", completed)
+	}
+}
+
+func TestJudgeRunner_WithoutProgress(t *testing.T) {
+	logger := zerolog.Nop()
+	runner := NewJudgeRunner([]Judge{&staticJudge{name: "relevance", score: 0.9}}, &logger)
+
+	if results := runner.Run(context.Background(), models.EvaluationContext{}); len(results) != 1 {
+		t.Errorf("Expected 1 result, got %d", len(results))
+	}
+}
@@ -1,6 +1,114 @@
 package judge
 
+import (
+	"errors"
+	"strings"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
 type judgeResponse struct {
 	Score  float64 `json:"score"`
 	Reason string  `json:"reason"`
 }
+
+// ResponseSchema is the structured output requested from LLM judges. On Bedrock
+// it is the input schema of a forced tool call, on OpenAI a json_schema response format.
+var ResponseSchema = &llm.OutputSchema{
+	Name:        "submit_evaluation",
+	Description: "Submit the evaluation score and the reason for it",
+	Schema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"score": map[string]any{
+				"type":        "number",
+				"minimum":     0.0,
+				"maximum":     1.0,
+				"description": "Score between 0.0 and 1.0",
+			},
+			"reason": map[string]any{
+				"type":        "string",
+				"description": "Short explanation of the score",
+			},
+		},
+		"required":             []any{"score", "reason"},
+		"additionalProperties": false,
+	},
+}
+
+type claimsResponse struct {
+	Claims []string `json:"claims"`
+}
+
+// ClaimsSchema is the structured output of the decomposition step of per-claim judges
+var ClaimsSchema = &llm.OutputSchema{
+	Name:        "submit_claims",
+	Description: "Submit the atomic claims made by the answer",
+	Schema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"claims": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Self-contained factual claims, one statement each",
+			},
+		},
+		"required":             []any{"claims"},
+		"additionalProperties": false,
+	},
+}
+
+type claimResponse struct {
+	Verdict string `json:"verdict"`
+	Reason  string `json:"reason"`
+}
+
+// ClaimVerdictSchema is the structured output of the verification step of per-claim judges
+var ClaimVerdictSchema = &llm.OutputSchema{
+	Name:        "submit_claim_verdict",
+	Description: "Submit whether the context supports the claim and the reason for it",
+	Schema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"verdict": map[string]any{
+				"type":        "string",
+				"enum":        []any{models.ClaimSupported, models.ClaimContradicted, models.ClaimNotFound},
+				"description": "supported, contradicted or not_found in the context",
+			},
+			"reason": map[string]any{
+				"type":        "string",
+				"description": "Short explanation of the verdict",
+			},
+		},
+		"required":             []any{"verdict", "reason"},
+		"additionalProperties": false,
+	},
+}
+
+// Reasoning judges write free-form reasoning and end with the verdict block, as
+// asked by the reasoning output partial
+const (
+	verdictOpen  = "<verdict>"
+	verdictClose = "</verdict>"
+)
+
+// errTruncated is returned for responses cut off at the max tokens limit
+var errTruncated = errors.New("response truncated at max_tokens")
+
+// splitVerdict returns the reasoning before the last verdict block and the block
+// content. Without a complete block the whole content is returned as reasoning.
+func splitVerdict(content string) (string, string, bool) {
+	start := strings.LastIndex(content, verdictOpen)
+	if start == -1 {
+		return strings.TrimSpace(content), "", false
+	}
+
+	rest := content[start+len(verdictOpen):]
+	end := strings.Index(rest, verdictClose)
+	if end == -1 {
+		return strings.TrimSpace(content), "", false
+	}
+
+	return strings.TrimSpace(content[:start]), strings.TrimSpace(rest[:end]), true
+}
@@ -4,10 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math"
-	"math/rand"
-	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
@@ -15,10 +11,25 @@ import (
 )
 
 type claudeMessageRequest struct {
-	AnthropicVersion string          `json:"anthropic_version"`
-	MaxTokens        int             `json:"max_tokens"`
-	Temperature      float64         `json:"temperature"`
-	Messages         []claudeMessage `json:"messages"`
+	AnthropicVersion string            `json:"anthropic_version"`
+	MaxTokens        int               `json:"max_tokens"`
+	Temperature      float64           `json:"temperature"`
+	Messages         []claudeMessage   `json:"messages"`
+	Tools            []claudeTool      `json:"tools,omitempty"`
+	ToolChoice       *claudeToolChoice `json:"tool_choice,omitempty"`
+}
+
+// claudeTool is a tool whose input schema is the requested output schema. Forcing
+// Claude to call it makes the tool input the structured response.
+type claudeTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type claudeToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
 }
 
 type claudeMessage struct {
@@ -28,10 +39,15 @@ type claudeMessage struct {
 
 type claudeMessageResponse struct {
 	Content []struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Input json.RawMessage `json:"input"` // tool_use blocks
 	} `json:"content"`
 	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
 }
 
 var anthropicVersion = "bedrock-2023-05-31"
@@ -49,6 +65,15 @@ func (c *Client) InvokeModel(ctx context.Context, request llm.LLMRequest) (*llm.
 		},
 	}
 
+	if request.Schema != nil {
+		payload.Tools = []claudeTool{{
+			Name:        request.Schema.Name,
+			Description: request.Schema.Description,
+			InputSchema: request.Schema.Schema,
+		}}
+		payload.ToolChoice = &claudeToolChoice{Type: "tool", Name: request.Schema.Name}
+	}
+
 	byes, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to serialize claude request. Error: %w", err)
@@ -62,7 +87,7 @@ func (c *Client) InvokeModel(ctx context.Context, request llm.LLMRequest) (*llm.
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("Unable to invoke claude model. Error: %w", err)
+		return nil, classifyError(fmt.Errorf("Unable to invoke claude model. Error: %w", err))
 	}
 
 	var response claudeMessageResponse
@@ -70,88 +95,36 @@ func (c *Client) InvokeModel(ctx context.Context, request llm.LLMRequest) (*llm.
 		return nil, fmt.Errorf("Failed to unmarshal bedrock response. Error: %w", err)
 	}
 
-	// Extract the response
-	var content string
-	if len(response.Content) > 0 {
-		content = response.Content[0].Text
-	}
-
 	return &llm.LLMResponse{
-		Content:    content,
+		Content:    responseContent(response),
 		StopReason: response.StopReason,
+		ModelID:    c.ModelID,
+		Usage: llm.Usage{
+			InputTokens:  response.Usage.InputTokens,
+			OutputTokens: response.Usage.OutputTokens,
+		},
 	}, nil
 }
 
-func (c *Client) InvokeModelWithRetry(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
-	var lastErr error
-
-	for attempt := 0; attempt < c.MaxRetries; attempt++ {
-		response, err := c.InvokeModel(ctx, request)
-		if err == nil {
-			return response, nil
-		}
-
-		lastErr = err
-
-		// Check if error is retryable
-		if !isRetryableError(err) {
-			return nil, fmt.Errorf("non-retryable error: %w", err)
-		}
-
-		delay := calculateBackoff(attempt, c.InitialDelay, c.MaxDelay)
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(delay):
-			continue
+// responseContent returns the structured output of a tool_use block, or the first text block
+func responseContent(response claudeMessageResponse) string {
+	for _, block := range response.Content {
+		if block.Type == "tool_use" {
+			return string(block.Input)
 		}
 	}
-
-	return nil, fmt.Errorf("max retries %d exceeded: %w", c.MaxRetries, lastErr)
-}
-
-func isRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errStr := err.Error()
-
-	// 1. Throttling errors
-	if strings.Contains(errStr, "ThrottlingException") ||
-		strings.Contains(errStr, "TooManyRequestsException") ||
-		strings.Contains(errStr, "Rate exceeded") {
-		return true
-	}
-
-	// 2. Service errors (5xx)
-	if strings.Contains(errStr, "InternalServerException") ||
-		strings.Contains(errStr, "ServiceUnavailableException") ||
-		strings.Contains(errStr, "500") ||
-		strings.Contains(errStr, "503") {
-		return true
-	}
-
-	// 3. Network errors
-	if strings.Contains(errStr, "connection reset") ||
-		strings.Contains(errStr, "EOF") ||
-		strings.Contains(errStr, "timeout") {
-		return true
+	if len(response.Content) > 0 {
+		return response.Content[0].Text
 	}
-
-	// Non-retryable errors (4xx client errors, validation errors, etc.)
-	return false
+	return ""
 }
 
-func calculateBackoff(attempt int, initialDelay, maxDelay time.Duration) time.Duration {
-	backoff := float64(initialDelay) + math.Pow(2, float64(attempt))
-
-	if backoff > float64(maxDelay) {
-		backoff = float64(maxDelay)
-	}
-
-	jitter := backoff * 0.2 * (2*rand.Float64() - 1) // Random value between -20% and +20%
-	backoff += jitter
-
-	return time.Duration(backoff)
+func (c *Client) InvokeModelWithRetry(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	return llm.Retry(ctx, llm.RetryPolicy{
+		MaxRetries:   c.MaxRetries,
+		InitialDelay: c.InitialDelay,
+		MaxDelay:     c.MaxDelay,
+	}, func() (*llm.LLMResponse, error) {
+		return c.InvokeModel(ctx, request)
+	})
 }
@@ -0,0 +1,47 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResponseContent(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "tool use",
+			body: `{"content": [{"type": "tool_use", "id": "toolu_1", "name": "submit_evaluation", "input": {"score": 0.9, "reason": "ok"}}], "stop_reason": "tool_use"}`,
+			want: `{"score": 0.9, "reason": "ok"}`,
+		},
+		{
+			name: "text before tool use",
+			body: `{"content": [{"type": "text", "text": "Scoring now."}, {"type": "tool_use", "input": {"score": 1}}], "stop_reason": "tool_use"}`,
+			want: `{"score": 1}`,
+		},
+		{
+			name: "text",
+			body: `{"content": [{"type": "text", "text": "{\"score\": 0.5}"}], "stop_reason": "end_turn"}`,
+			want: `{"score": 0.5}`,
+		},
+		{
+			name: "empty",
+			body: `{"content": [], "stop_reason": "end_turn"}`,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response claudeMessageResponse
+			if err := json.Unmarshal([]byte(tt.body), &response); err != nil {
+				t.Fatalf("invalid test body: %v", err)
+			}
+			if got := responseContent(response); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
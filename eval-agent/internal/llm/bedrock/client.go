@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 )
@@ -23,7 +24,11 @@ func NewClient(ctx context.Context, region string, modelID string) (*Client, err
 		return nil, fmt.Errorf("Unable to load AWS config: %w", err)
 	}
 
-	bedrockClient := bedrockruntime.NewFromConfig(cfg)
+	// Transient errors are retried by InvokeModelWithRetry or llm.GuardedClient,
+	// so every attempt goes through the rate limiter and circuit breaker
+	bedrockClient := bedrockruntime.NewFromConfig(cfg, func(o *bedrockruntime.Options) {
+		o.Retryer = aws.NopRetryer{}
+	})
 
 	return &Client{
 		Client:       bedrockClient,
@@ -0,0 +1,95 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+)
+
+// DefaultEmbeddingModelID is the Titan model used when no embedding model is configured
+const DefaultEmbeddingModelID = "amazon.titan-embed-text-v2:0"
+
+// EmbeddingClient computes text embeddings with an Amazon Titan embedding model
+type EmbeddingClient struct {
+	Client     *bedrockruntime.Client
+	ModelID    string
+	Dimensions int // 256, 512 or 1024 for Titan v2 (0 = model default)
+	Retry      llm.RetryPolicy
+}
+
+type titanEmbeddingRequest struct {
+	InputText  string `json:"inputText"`
+	Dimensions int    `json:"dimensions,omitempty"`
+}
+
+type titanEmbeddingResponse struct {
+	Embedding []float64 `json:"embedding"`
+}
+
+func NewEmbeddingClient(ctx context.Context, region string, modelID string, dimensions int) (*EmbeddingClient, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to load AWS config: %w", err)
+	}
+
+	if modelID == "" {
+		modelID = DefaultEmbeddingModelID
+	}
+
+	return &EmbeddingClient{
+		Client: bedrockruntime.NewFromConfig(cfg, func(o *bedrockruntime.Options) {
+			o.Retryer = aws.NopRetryer{}
+		}),
+		ModelID:    modelID,
+		Dimensions: dimensions,
+		Retry:      llm.DefaultRetryPolicy,
+	}, nil
+}
+
+// Embed returns one embedding per text. Titan embeds a single text per call, so
+// the texts are sent one by one.
+func (c *EmbeddingClient) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vector, err := llm.Retry(ctx, c.Retry, func() ([]float64, error) {
+			return c.embed(ctx, text)
+		})
+		if err != nil {
+			return nil, err
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+func (c *EmbeddingClient) embed(ctx context.Context, text string) ([]float64, error) {
+	body, err := json.Marshal(titanEmbeddingRequest{InputText: text, Dimensions: c.Dimensions})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to serialize titan request. Error: %w", err)
+	}
+
+	output, err := c.Client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+		ModelId:     &c.ModelID,
+		Body:        body,
+		Accept:      aws.String("application/json"),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return nil, classifyError(fmt.Errorf("Unable to invoke titan embedding model. Error: %w", err))
+	}
+
+	var response titanEmbeddingResponse
+	if err := json.Unmarshal(output.Body, &response); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal titan response. Error: %w", err)
+	}
+	if len(response.Embedding) == 0 {
+		return nil, fmt.Errorf("titan response contains no embedding")
+	}
+
+	return response.Embedding, nil
+}
@@ -0,0 +1,47 @@
+package bedrock
+
+import (
+	"context"
+	"errors"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+)
+
+// classifyError wraps an InvokeModel error in an llm.ProviderError, classified
+// from the typed Bedrock exceptions and the HTTP status code
+func classifyError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	providerErr := &llm.ProviderError{Provider: "bedrock", Err: err}
+
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		providerErr.StatusCode = respErr.HTTPStatusCode()
+	}
+
+	var (
+		throttling   *types.ThrottlingException
+		unavailable  *types.ServiceUnavailableException
+		internal     *types.InternalServerException
+		notReady     *types.ModelNotReadyException
+		modelTimeout *types.ModelTimeoutException
+	)
+	switch {
+	case errors.As(err, &throttling),
+		errors.As(err, &unavailable),
+		errors.As(err, &internal),
+		errors.As(err, &notReady),
+		errors.As(err, &modelTimeout):
+		providerErr.Transient = true
+	case providerErr.StatusCode != 0:
+		providerErr.Transient = llm.IsTransientStatus(providerErr.StatusCode)
+	default:
+		providerErr.Transient = llm.IsNetworkError(err)
+	}
+
+	return providerErr
+}
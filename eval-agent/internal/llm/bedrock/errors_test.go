@@ -0,0 +1,52 @@
+package bedrock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+)
+
+func TestClassifyError(t *testing.T) {
+	message := func(s string) *string { return &s }
+
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"throttling", &types.ThrottlingException{Message: message("Rate exceeded")}, true},
+		{"service unavailable", &types.ServiceUnavailableException{}, true},
+		{"internal server", &types.InternalServerException{}, true},
+		{"model not ready", &types.ModelNotReadyException{}, true},
+		{"model timeout", &types.ModelTimeoutException{}, true},
+		{"validation", &types.ValidationException{Message: message("max_tokens too large")}, false},
+		{"access denied", &types.AccessDeniedException{}, false},
+		{"quota exceeded", &types.ServiceQuotaExceededException{}, false},
+		{"unknown", errors.New("something else"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(fmt.Errorf("Unable to invoke claude model. Error: %w", tt.err))
+
+			var providerErr *llm.ProviderError
+			if !errors.As(err, &providerErr) {
+				t.Fatalf("expected provider error, got %T", err)
+			}
+			if providerErr.Transient != tt.transient || llm.IsTransientError(err) != tt.transient {
+				t.Errorf("expected transient=%v, got %v", tt.transient, providerErr.Transient)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("expected the SDK error to stay reachable with errors.As/Is")
+			}
+		})
+	}
+
+	if err := classifyError(context.Canceled); err != context.Canceled {
+		t.Errorf("expected context errors to pass through, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrCircuitOpen is returned without calling the model while its circuit is open.
+// It is transient, so a FallbackClient moves on to the next model.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitBreakerSettings configures a CircuitBreaker. A zero threshold disables it.
+type CircuitBreakerSettings struct {
+	FailureThreshold int           // Consecutive transient failures that open the circuit
+	OpenTimeout      time.Duration // Time the circuit stays open before a trial call
+}
+
+type circuitState string
+
+const (
+	circuitClosed   circuitState = "closed"
+	circuitOpen     circuitState = "open"
+	circuitHalfOpen circuitState = "half-open"
+)
+
+// CircuitBreaker fails calls to a model fast during an outage. It opens after
+// FailureThreshold consecutive transient failures, rejects calls for OpenTimeout,
+// then lets a single trial call through: success closes the circuit, a failure
+// opens it again. Non-transient errors (e.g. validation) show the model is up and
+// count as success.
+type CircuitBreaker struct {
+	name     string
+	settings CircuitBreakerSettings
+	logger   *zerolog.Logger
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewCircuitBreaker(name string, settings CircuitBreakerSettings, logger *zerolog.Logger) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:     name,
+		settings: settings,
+		logger:   logger,
+		now:      time.Now,
+		state:    circuitClosed,
+	}
+}
+
+// Allow returns ErrCircuitOpen if the call must not be made
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == circuitOpen && b.now().Sub(b.openedAt) >= b.settings.OpenTimeout {
+		b.transition(circuitHalfOpen)
+	}
+
+	switch b.state {
+	case circuitOpen:
+		return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+	case circuitHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call
+func (b *CircuitBreaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// A cancelled call says nothing about the model
+	if errors.Is(err, context.Canceled) {
+		b.probing = false
+		return
+	}
+
+	if !IsTransientError(err) {
+		b.failures = 0
+		b.probing = false
+		if b.state != circuitClosed {
+			b.transition(circuitClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.settings.FailureThreshold {
+		b.probing = false
+		b.openedAt = b.now()
+		if b.state != circuitOpen {
+			b.logger.Warn().
+				Err(err).
+				Str("model", b.name).
+				Int("failures", b.failures).
+				Dur("open_timeout", b.settings.OpenTimeout).
+				Msg("circuit breaker opened, failing calls fast")
+			b.transition(circuitOpen)
+		}
+	}
+}
+
+// State returns the circuit state: closed, open or half-open
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.state)
+}
+
+func (b *CircuitBreaker) transition(state circuitState) {
+	if state == circuitClosed {
+		b.logger.Info().Str("model", b.name).Msg("circuit breaker closed")
+	}
+	b.state = state
+}
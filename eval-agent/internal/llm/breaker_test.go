@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	logger := zerolog.Nop()
+	now := time.Unix(1700000000, 0)
+
+	breaker := NewCircuitBreaker("bedrock/sonnet", CircuitBreakerSettings{FailureThreshold: 2, OpenTimeout: time.Minute}, &logger)
+	breaker.now = func() time.Time { return now }
+
+	throttled := &ProviderError{StatusCode: 429, Transient: true, Err: errors.New("ThrottlingException")}
+	invalid := &ProviderError{StatusCode: 400, Err: errors.New("ValidationException")}
+
+	// Non-transient errors and cancellations do not count
+	breaker.Record(throttled)
+	breaker.Record(invalid)
+	breaker.Record(throttled)
+	breaker.Record(context.Canceled)
+	if breaker.State() != "closed" {
+		t.Fatalf("expected closed circuit, got %s", breaker.State())
+	}
+
+	breaker.Record(throttled)
+	if breaker.State() != "open" {
+		t.Fatalf("expected open circuit after 2 consecutive failures, got %s", breaker.State())
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected open circuit error, got %v", err)
+	}
+
+	// After the timeout a single trial call goes through
+	now = now.Add(time.Minute)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("expected trial call to be allowed, got %v", err)
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected concurrent calls to be rejected during the trial, got %v", err)
+	}
+
+	// A failed trial opens the circuit again
+	breaker.Record(throttled)
+	if breaker.State() != "open" {
+		t.Fatalf("expected failed trial to reopen the circuit, got %s", breaker.State())
+	}
+
+	now = now.Add(time.Minute)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("expected trial call to be allowed, got %v", err)
+	}
+	breaker.Record(nil)
+	if breaker.State() != "closed" {
+		t.Errorf("expected successful trial to close the circuit, got %s", breaker.State())
+	}
+	if err := breaker.Allow(); err != nil {
+		t.Errorf("expected closed circuit to allow calls, got %v", err)
+	}
+}
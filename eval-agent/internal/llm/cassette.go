@@ -0,0 +1,190 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// CassetteMode selects how a CassetteClient uses its cassette
+type CassetteMode string
+
+const (
+	// CassetteRecord calls the model for every request and records the responses
+	CassetteRecord CassetteMode = "record"
+	// CassetteReplay serves responses from the cassette only and fails on a miss
+	CassetteReplay CassetteMode = "replay"
+	// CassetteCache serves recorded responses and records the ones it has to request
+	CassetteCache CassetteMode = "cache"
+)
+
+// ErrCassetteMiss is returned in replay mode for requests missing from the cassette
+var ErrCassetteMiss = errors.New("request not found in cassette")
+
+// ParseCassetteMode validates a mode name
+func ParseCassetteMode(mode string) (CassetteMode, error) {
+	switch CassetteMode(mode) {
+	case CassetteRecord, CassetteReplay, CassetteCache:
+		return CassetteMode(mode), nil
+	default:
+		return "", fmt.Errorf("invalid cassette mode: %s (must be record, replay or cache)", mode)
+	}
+}
+
+// cassetteEntry is one line of a cassette file
+type cassetteEntry struct {
+	Key      string      `json:"key"`
+	Scope    string      `json:"scope"`
+	Request  LLMRequest  `json:"request"`
+	Response LLMResponse `json:"response"`
+}
+
+// Cassette stores LLM responses in a JSONL file, keyed by a hash of the model scope,
+// the prompt and the request parameters. Entries are appended as they are recorded;
+// when a key appears more than once the last entry wins.
+type Cassette struct {
+	mu      sync.Mutex
+	file    *os.File
+	entries map[string]LLMResponse
+}
+
+// OpenCassette loads the cassette file, creating it if it does not exist
+func OpenCassette(path string) (*Cassette, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette %s: %w", path, err)
+	}
+
+	entries := make(map[string]LLMResponse)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry cassetteEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("cassette %s line %d: parse error: %w", path, lineNum, err)
+		}
+		entries[entry.Key] = entry.Response
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read cassette %s: %w", path, err)
+	}
+
+	return &Cassette{
+		file:    file,
+		entries: entries,
+	}, nil
+}
+
+// Len returns the number of recorded requests
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *Cassette) Close() error {
+	return c.file.Close()
+}
+
+func (c *Cassette) get(key string) (LLMResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resp, ok := c.entries[key]
+	return resp, ok
+}
+
+func (c *Cassette) put(key string, scope string, request LLMRequest, response LLMResponse) error {
+	line, err := json.Marshal(cassetteEntry{Key: key, Scope: scope, Request: request, Response: response})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette entry: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write cassette entry: %w", err)
+	}
+	c.entries[key] = response
+	return nil
+}
+
+// CassetteClient records and replays the responses of an LLM client. The scope
+// (e.g. "bedrock/<model id>") is part of the key, so one cassette can be shared
+// by the clients of several models.
+type CassetteClient struct {
+	client   LLMClient
+	cassette *Cassette
+	mode     CassetteMode
+	scope    string
+}
+
+// NewCassetteClient wraps the client. In replay mode the client is never called and may be nil.
+func NewCassetteClient(client LLMClient, cassette *Cassette, mode CassetteMode, scope string) *CassetteClient {
+	return &CassetteClient{
+		client:   client,
+		cassette: cassette,
+		mode:     mode,
+		scope:    scope,
+	}
+}
+
+func (c *CassetteClient) InvokeModel(ctx context.Context, request LLMRequest) (*LLMResponse, error) {
+	return c.invoke(request, func() (*LLMResponse, error) {
+		return c.client.InvokeModel(ctx, request)
+	})
+}
+
+func (c *CassetteClient) InvokeModelWithRetry(ctx context.Context, request LLMRequest) (*LLMResponse, error) {
+	return c.invoke(request, func() (*LLMResponse, error) {
+		return c.client.InvokeModelWithRetry(ctx, request)
+	})
+}
+
+func (c *CassetteClient) invoke(request LLMRequest, call func() (*LLMResponse, error)) (*LLMResponse, error) {
+	key := c.key(request)
+
+	if c.mode != CassetteRecord {
+		if resp, ok := c.cassette.get(key); ok {
+			return &resp, nil
+		}
+		if c.mode == CassetteReplay {
+			return nil, fmt.Errorf("%w: %s (key %s)", ErrCassetteMiss, c.scope, key)
+		}
+	}
+
+	resp, err := call()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.cassette.put(key, c.scope, request, *resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// key hashes everything that determines the response: the model scope, the prompt and the parameters
+func (c *CassetteClient) key(request LLMRequest) string {
+	data, _ := json.Marshal(struct {
+		Scope   string     `json:"scope"`
+		Request LLMRequest `json:"request"`
+	}{c.scope, request})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestCassetteClient_RecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "judges.cassette.jsonl")
+	request := LLMRequest{Prompt: "Score the answer", MaxTokens: 256, JSONOutput: true}
+
+	cassette, err := OpenCassette(path)
+	if err != nil {
+		t.Fatalf("OpenCassette failed: %v", err)
+	}
+
+	model := &stubClient{name: "sonnet"}
+	recorder := NewCassetteClient(model, cassette, CassetteRecord, "bedrock/sonnet")
+	recorded, err := recorder.InvokeModelWithRetry(context.Background(), request)
+	if err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	if model.calls != 1 || cassette.Len() != 1 {
+		t.Fatalf("expected one call and one entry, got %d calls, %d entries", model.calls, cassette.Len())
+	}
+	cassette.Close()
+
+	// Replay from the file without a model client
+	cassette, err = OpenCassette(path)
+	if err != nil {
+		t.Fatalf("OpenCassette failed: %v", err)
+	}
+	defer cassette.Close()
+
+	replayer := NewCassetteClient(nil, cassette, CassetteReplay, "bedrock/sonnet")
+	replayed, err := replayer.InvokeModel(context.Background(), request)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if *replayed != *recorded {
+		t.Errorf("expected replayed response %+v, got %+v", recorded, replayed)
+	}
+
+	// Any parameter or scope change is a miss
+	changed := request
+	changed.Temperature = 0.5
+	if _, err := replayer.InvokeModel(context.Background(), changed); !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("expected cassette miss for changed parameters, got %v", err)
+	}
+	other := NewCassetteClient(nil, cassette, CassetteReplay, "openai/gpt-4o")
+	if _, err := other.InvokeModel(context.Background(), request); !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("expected cassette miss for another model, got %v", err)
+	}
+}
+
+func TestCassetteClient_Cache(t *testing.T) {
+	cassette, err := OpenCassette(filepath.Join(t.TempDir(), "cache.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenCassette failed: %v", err)
+	}
+	defer cassette.Close()
+
+	model := &stubClient{name: "haiku"}
+	client := NewCassetteClient(model, cassette, CassetteCache, "bedrock/haiku")
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.InvokeModel(context.Background(), LLMRequest{Prompt: "same prompt"}); err != nil {
+			t.Fatalf("InvokeModel failed: %v", err)
+		}
+	}
+	if model.calls != 1 {
+		t.Errorf("expected identical requests to be served from the cache, got %d calls", model.calls)
+	}
+
+	// Failed calls are not recorded
+	model.err = errors.New("ThrottlingException")
+	if _, err := client.InvokeModel(context.Background(), LLMRequest{Prompt: "other prompt"}); err == nil {
+		t.Error("expected model error")
+	}
+	if cassette.Len() != 1 {
+		t.Errorf("expected failed call not to be recorded, got %d entries", cassette.Len())
+	}
+}
+
+func TestParseCassetteMode(t *testing.T) {
+	for _, mode := range []string{"record", "replay", "cache"} {
+		if _, err := ParseCassetteMode(mode); err != nil {
+			t.Errorf("expected %s to be valid: %v", mode, err)
+		}
+	}
+	if _, err := ParseCassetteMode("rewind"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
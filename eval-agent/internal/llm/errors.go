@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// ProviderError is an error returned by a provider API, classified by the provider
+// client from the typed SDK error
+type ProviderError struct {
+	Provider   string
+	StatusCode int  // HTTP status code, 0 when the call got no response
+	Transient  bool // Throttling, service unavailable or network error: may succeed later or on another model
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// IsTransientStatus reports whether an HTTP status code is worth retrying:
+// request timeout, throttling and server errors
+func IsTransientStatus(statusCode int) bool {
+	return statusCode == 408 || statusCode == 429 || statusCode >= 500
+}
+
+// IsNetworkError reports whether the call failed before a response was received
+func IsNetworkError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// IsTransientError reports whether a failed call may succeed on another model or
+// later: throttling, 5xx service errors, network errors, timeouts and open circuits
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.Transient
+	}
+
+	return IsNetworkError(err)
+}
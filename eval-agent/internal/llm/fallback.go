@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+// NamedClient is a client in a fallback chain, named for logs and errors
+type NamedClient struct {
+	Name   string
+	Client LLMClient
+}
+
+// FallbackClient tries its clients in order. The next client is only tried when
+// the previous one failed with a transient error (throttling, service unavailable,
+// network); other errors are returned as is.
+type FallbackClient struct {
+	clients []NamedClient
+	logger  *zerolog.Logger
+}
+
+func NewFallbackClient(clients []NamedClient, logger *zerolog.Logger) *FallbackClient {
+	return &FallbackClient{
+		clients: clients,
+		logger:  logger,
+	}
+}
+
+func (c *FallbackClient) InvokeModel(ctx context.Context, request LLMRequest) (*LLMResponse, error) {
+	return c.invoke(ctx, func(client LLMClient) (*LLMResponse, error) {
+		return client.InvokeModel(ctx, request)
+	})
+}
+
+func (c *FallbackClient) InvokeModelWithRetry(ctx context.Context, request LLMRequest) (*LLMResponse, error) {
+	return c.invoke(ctx, func(client LLMClient) (*LLMResponse, error) {
+		return client.InvokeModelWithRetry(ctx, request)
+	})
+}
+
+func (c *FallbackClient) invoke(ctx context.Context, call func(LLMClient) (*LLMResponse, error)) (*LLMResponse, error) {
+	var lastErr error
+
+	for i, named := range c.clients {
+		resp, err := call(named.Client)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = fmt.Errorf("%s: %w", named.Name, err)
+
+		if ctx.Err() != nil || !IsTransientError(err) || i == len(c.clients)-1 {
+			break
+		}
+
+		c.logger.Warn().
+			Err(err).
+			Str("model", named.Name).
+			Str("fallback", c.clients[i+1].Name).
+			Msg("LLM call failed, falling back to next model")
+	}
+
+	return nil, lastErr
+}
@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestFallbackClient(t *testing.T) {
+	logger := zerolog.Nop()
+
+	tests := []struct {
+		name          string
+		primaryErr    error
+		expectModel   string
+		expectErr     bool
+		fallbackCalls int
+	}{
+		{name: "primary succeeds", expectModel: "sonnet", fallbackCalls: 0},
+		{name: "throttled primary falls back", primaryErr: &ProviderError{Provider: "bedrock", StatusCode: 429, Transient: true, Err: errors.New("ThrottlingException: Rate exceeded")}, expectModel: "gpt-4o", fallbackCalls: 1},
+		{name: "unavailable primary falls back", primaryErr: &ProviderError{Provider: "bedrock", StatusCode: 503, Transient: true, Err: errors.New("ServiceUnavailableException")}, expectModel: "gpt-4o", fallbackCalls: 1},
+		{name: "validation error is returned", primaryErr: &ProviderError{Provider: "bedrock", StatusCode: 400, Err: errors.New("ValidationException: bad request")}, expectErr: true, fallbackCalls: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &stubClient{name: "sonnet", err: tt.primaryErr}
+			fallback := &stubClient{name: "gpt-4o"}
+
+			client := NewFallbackClient([]NamedClient{
+				{Name: "bedrock/sonnet", Client: primary},
+				{Name: "openai/gpt-4o", Client: fallback},
+			}, &logger)
+
+			resp, err := client.InvokeModelWithRetry(context.Background(), LLMRequest{Prompt: "p"})
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if resp.ModelID != tt.expectModel {
+					t.Errorf("Expected response from %s, got %s", tt.expectModel, resp.ModelID)
+				}
+			}
+			if fallback.calls != tt.fallbackCalls {
+				t.Errorf("Expected %d fallback calls, got %d", tt.fallbackCalls, fallback.calls)
+			}
+		})
+	}
+}
+
+func TestFallbackClient_AllFail(t *testing.T) {
+	logger := zerolog.Nop()
+	client := NewFallbackClient([]NamedClient{
+		{Name: "bedrock/sonnet", Client: &stubClient{err: &ProviderError{StatusCode: 429, Transient: true, Err: errors.New("ThrottlingException")}}},
+		{Name: "openai/gpt-4o", Client: &stubClient{err: &ProviderError{StatusCode: 429, Transient: true, Err: errors.New("429 Too Many Requests")}}},
+	}, &logger)
+
+	_, err := client.InvokeModel(context.Background(), LLMRequest{Prompt: "p"})
+	if err == nil || err.Error() != "openai/gpt-4o: 429 Too Many Requests" {
+		t.Errorf("Expected last error from the end of the chain, got %v", err)
+	}
+}
@@ -14,6 +14,14 @@ type Client struct {
 	MaxRetries   int
 	InitialDelay time.Duration
 	MaxDelay     time.Duration
+
+	// JSONMode sends response_format json_object for requests expecting JSON output
+	JSONMode bool
+	// StructuredOutput sends response_format json_schema for requests with an output schema
+	StructuredOutput bool
+	// LegacyMaxTokens sends max_tokens instead of max_completion_tokens, for
+	// OpenAI-compatible servers that do not support the newer parameter
+	LegacyMaxTokens bool
 }
 
 func NewClient(apiKey string, model string) (*Client, error) {
@@ -26,14 +34,49 @@ func NewClient(apiKey string, model string) (*Client, error) {
 
 	openaiClient := openai.NewClient(
 		option.WithAPIKey(apiKey),
-		option.WithMaxRetries(3),
+		option.WithMaxRetries(0), // Retried by InvokeModelWithRetry
 	)
 
 	return &Client{
-		Client:       openaiClient,
-		ModelID:      model,
-		MaxRetries:   3,
-		InitialDelay: 100 * time.Millisecond,
-		MaxDelay:     12 * time.Second,
+		Client:           openaiClient,
+		ModelID:          model,
+		MaxRetries:       3,
+		InitialDelay:     100 * time.Millisecond,
+		MaxDelay:         12 * time.Second,
+		StructuredOutput: true,
+	}, nil
+}
+
+// NewCompatibleClient creates a client for an OpenAI-compatible endpoint such as a
+// locally hosted llama.cpp server, vLLM or Ollama (e.g. http://localhost:11434/v1).
+// The API key is optional for servers that do not check it. jsonMode enables both
+// response_format json_object and json_schema, which llama.cpp, vLLM and Ollama support.
+func NewCompatibleClient(baseURL string, apiKey string, model string, jsonMode bool) (*Client, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("OpenAI-compatible base URL is required")
+	}
+	if model == "" {
+		return nil, fmt.Errorf("OpenAI-compatible model ID is required")
+	}
+
+	opts := []option.RequestOption{
+		option.WithBaseURL(baseURL),
+		option.WithMaxRetries(0), // Retried by InvokeModelWithRetry
+	}
+	if apiKey != "" {
+		opts = append(opts, option.WithAPIKey(apiKey))
+	} else {
+		opts = append(opts, option.WithHeaderDel("authorization"))
+	}
+
+	return &Client{
+		Client:           openai.NewClient(opts...),
+		ModelID:          model,
+		MaxRetries:       3,
+		InitialDelay:     100 * time.Millisecond,
+		MaxDelay:         12 * time.Second,
+		JSONMode:         jsonMode,
+		StructuredOutput: jsonMode,
+		LegacyMaxTokens:  true,
 	}, nil
 }
@@ -0,0 +1,79 @@
+package gpt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+)
+
+// DefaultEmbeddingModelID is the model used when no embedding model is configured
+const DefaultEmbeddingModelID = "text-embedding-3-small"
+
+// EmbeddingClient computes text embeddings with the OpenAI embeddings API
+type EmbeddingClient struct {
+	Client     openai.Client
+	ModelID    string
+	Dimensions int // Supported by text-embedding-3 models (0 = model default)
+	Retry      llm.RetryPolicy
+}
+
+func NewEmbeddingClient(apiKey string, model string, dimensions int) (*EmbeddingClient, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("OpenAI API key is required")
+	}
+	if model == "" {
+		model = DefaultEmbeddingModelID
+	}
+
+	return &EmbeddingClient{
+		Client: openai.NewClient(
+			option.WithAPIKey(apiKey),
+			option.WithMaxRetries(0), // Retried by Embed
+		),
+		ModelID:    model,
+		Dimensions: dimensions,
+		Retry:      llm.DefaultRetryPolicy,
+	}, nil
+}
+
+// Embed returns one embedding per text, computed in a single request
+func (c *EmbeddingClient) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	params := openai.EmbeddingNewParams{
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: texts},
+		Model: openai.EmbeddingModel(c.ModelID),
+	}
+	if c.Dimensions > 0 {
+		params.Dimensions = openai.Int(int64(c.Dimensions))
+	}
+
+	response, err := llm.Retry(ctx, c.Retry, func() (*openai.CreateEmbeddingResponse, error) {
+		response, err := c.Client.Embeddings.New(ctx, params)
+		if err != nil {
+			return nil, classifyError(fmt.Errorf("failed to create embeddings: %w", err))
+		}
+		return response, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(response.Data))
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, data := range response.Data {
+		if data.Index < 0 || int(data.Index) >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", data.Index)
+		}
+		vectors[data.Index] = data.Embedding
+	}
+	return vectors, nil
+}
@@ -0,0 +1,29 @@
+package gpt
+
+import (
+	"context"
+	"errors"
+
+	"github.com/openai/openai-go"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+)
+
+// classifyError wraps a chat completion error in an llm.ProviderError, classified
+// from the HTTP status code of the typed API error
+func classifyError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	providerErr := &llm.ProviderError{Provider: "openai", Err: err}
+
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		providerErr.StatusCode = apiErr.StatusCode
+		providerErr.Transient = llm.IsTransientStatus(apiErr.StatusCode)
+	} else {
+		providerErr.Transient = llm.IsNetworkError(err)
+	}
+
+	return providerErr
+}
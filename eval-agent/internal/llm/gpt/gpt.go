@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/shared"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
 )
 
@@ -14,15 +15,37 @@ func (c *Client) InvokeModel(ctx context.Context, request llm.LLMRequest) (*llm.
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.UserMessage(request.Prompt),
 		},
-		MaxCompletionTokens: openai.Int(int64(request.MaxTokens)),
-		Temperature:         openai.Float(request.Temperature),
-		Model:               openai.ChatModel(c.ModelID),
+		Temperature: openai.Float(request.Temperature),
+		Model:       openai.ChatModel(c.ModelID),
+	}
+
+	if c.LegacyMaxTokens {
+		message.MaxTokens = openai.Int(int64(request.MaxTokens))
+	} else {
+		message.MaxCompletionTokens = openai.Int(int64(request.MaxTokens))
+	}
+
+	switch {
+	case c.StructuredOutput && request.Schema != nil:
+		jsonSchema := shared.ResponseFormatJSONSchemaJSONSchemaParam{
+			Name:   request.Schema.Name,
+			Schema: request.Schema.Schema,
+		}
+		if request.Schema.Description != "" {
+			jsonSchema.Description = openai.String(request.Schema.Description)
+		}
+		message.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{JSONSchema: jsonSchema},
+		}
+	case c.JSONMode && (request.JSONOutput || request.Schema != nil):
+		message.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONObject: &shared.ResponseFormatJSONObjectParam{},
+		}
 	}
 
 	output, err := c.Client.Chat.Completions.New(ctx, message)
 	if err != nil {
-		return nil, fmt.Errorf("unable to invoke gpt model. Error: %w", err)
-
+		return nil, classifyError(fmt.Errorf("unable to invoke gpt model. Error: %w", err))
 	}
 
 	if len(output.Choices) == 0 {
@@ -33,9 +56,20 @@ func (c *Client) InvokeModel(ctx context.Context, request llm.LLMRequest) (*llm.
 	return &llm.LLMResponse{
 		Content:    response.Message.Content,
 		StopReason: fmt.Sprint(response.FinishReason),
+		ModelID:    output.Model,
+		Usage: llm.Usage{
+			InputTokens:  int(output.Usage.PromptTokens),
+			OutputTokens: int(output.Usage.CompletionTokens),
+		},
 	}, nil
 }
 
 func (c *Client) InvokeModelWithRetry(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
-	return c.InvokeModel(ctx, request)
+	return llm.Retry(ctx, llm.RetryPolicy{
+		MaxRetries:   c.MaxRetries,
+		InitialDelay: c.InitialDelay,
+		MaxDelay:     c.MaxDelay,
+	}, func() (*llm.LLMResponse, error) {
+		return c.InvokeModel(ctx, request)
+	})
 }
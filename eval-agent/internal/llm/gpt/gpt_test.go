@@ -0,0 +1,253 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+)
+
+// newCompatibleServer is a stand-in for a local OpenAI-compatible server
+func newCompatibleServer(t *testing.T, requests *[]map[string]any, headers *[]http.Header) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		*requests = append(*requests, body)
+		*headers = append(*headers, r.Header.Clone())
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": "chatcmpl-1",
+			"object": "chat.completion",
+			"created": 1700000000,
+			"model": "llama3.1:8b",
+			"choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "{\"score\": 0.9, \"reason\": \"ok\"}"}}],
+			"usage": {"prompt_tokens": 120, "completion_tokens": 18, "total_tokens": 138}
+		}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCompatibleClient_InvokeModel(t *testing.T) {
+	var requests []map[string]any
+	var headers []http.Header
+	server := newCompatibleServer(t, &requests, &headers)
+
+	client, err := NewCompatibleClient(server.URL+"/v1", "", "llama3.1:8b", true)
+	if err != nil {
+		t.Fatalf("NewCompatibleClient failed: %v", err)
+	}
+
+	resp, err := client.InvokeModel(context.Background(), llm.LLMRequest{
+		Prompt:      "Score the answer",
+		MaxTokens:   256,
+		Temperature: 0.0,
+		JSONOutput:  true,
+	})
+	if err != nil {
+		t.Fatalf("InvokeModel failed: %v", err)
+	}
+
+	if resp.Content != `{"score": 0.9, "reason": "ok"}` || resp.StopReason != "stop" || resp.ModelID != "llama3.1:8b" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if resp.Usage != (llm.Usage{InputTokens: 120, OutputTokens: 18}) {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+
+	request := requests[0]
+	if request["model"] != "llama3.1:8b" {
+		t.Errorf("expected model llama3.1:8b, got %v", request["model"])
+	}
+	if request["max_tokens"] != float64(256) || request["max_completion_tokens"] != nil {
+		t.Errorf("expected legacy max_tokens, got %v / %v", request["max_tokens"], request["max_completion_tokens"])
+	}
+	format, _ := request["response_format"].(map[string]any)
+	if format["type"] != "json_object" {
+		t.Errorf("expected json_object response format, got %v", request["response_format"])
+	}
+	if auth := headers[0].Get("Authorization"); auth != "" {
+		t.Errorf("expected no Authorization header without API key, got %q", auth)
+	}
+
+	// Requests not expecting JSON are sent without response format
+	if _, err := client.InvokeModel(context.Background(), llm.LLMRequest{Prompt: "Rewrite the prompt", MaxTokens: 64}); err != nil {
+		t.Fatalf("InvokeModel failed: %v", err)
+	}
+	if _, ok := requests[1]["response_format"]; ok {
+		t.Error("expected no response format for free-form requests")
+	}
+}
+
+func TestCompatibleClient_JSONModeDisabled(t *testing.T) {
+	var requests []map[string]any
+	var headers []http.Header
+	server := newCompatibleServer(t, &requests, &headers)
+
+	client, err := NewCompatibleClient(server.URL+"/v1", "secret", "llama3.1:8b", false)
+	if err != nil {
+		t.Fatalf("NewCompatibleClient failed: %v", err)
+	}
+
+	if _, err := client.InvokeModel(context.Background(), llm.LLMRequest{Prompt: "p", MaxTokens: 64, JSONOutput: true}); err != nil {
+		t.Fatalf("InvokeModel failed: %v", err)
+	}
+
+	if _, ok := requests[0]["response_format"]; ok {
+		t.Error("expected no response format when JSON mode is disabled")
+	}
+	if auth := headers[0].Get("Authorization"); auth != "Bearer secret" {
+		t.Errorf("expected bearer API key, got %q", auth)
+	}
+}
+
+func TestNewCompatibleClient_Validation(t *testing.T) {
+	if _, err := NewCompatibleClient("", "", "llama3.1:8b", true); err == nil {
+		t.Error("expected error without base URL")
+	}
+	if _, err := NewCompatibleClient("http://localhost:8080/v1", "", "", true); err == nil {
+		t.Error("expected error without model")
+	}
+}
+
+func TestClient_InvokeModelWithRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Content-Type", "application/json")
+		switch attempts {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": {"message": "Rate limit reached", "type": "requests", "code": "rate_limit_exceeded"}}`))
+		default:
+			w.Write([]byte(`{"id": "chatcmpl-2", "object": "chat.completion", "created": 1700000000, "model": "gpt-4o",
+				"choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "ok"}}]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewCompatibleClient(server.URL+"/v1", "key", "gpt-4o", false)
+	if err != nil {
+		t.Fatalf("NewCompatibleClient failed: %v", err)
+	}
+	client.InitialDelay = time.Millisecond
+
+	// Without retry the throttling error is returned, typed
+	_, err = client.InvokeModel(context.Background(), llm.LLMRequest{Prompt: "p", MaxTokens: 10})
+	var providerErr *llm.ProviderError
+	if !errors.As(err, &providerErr) || providerErr.StatusCode != http.StatusTooManyRequests || !providerErr.Transient {
+		t.Fatalf("expected transient 429 provider error, got %v", err)
+	}
+
+	attempts = 0
+	resp, err := client.InvokeModelWithRetry(context.Background(), llm.LLMRequest{Prompt: "p", MaxTokens: 10})
+	if err != nil {
+		t.Fatalf("InvokeModelWithRetry failed: %v", err)
+	}
+	if resp.Content != "ok" || attempts != 2 {
+		t.Errorf("expected success on the second attempt, got %q after %d attempts", resp.Content, attempts)
+	}
+}
+
+func TestCompatibleClient_StructuredOutput(t *testing.T) {
+	var requests []map[string]any
+	var headers []http.Header
+	server := newCompatibleServer(t, &requests, &headers)
+
+	client, err := NewCompatibleClient(server.URL+"/v1", "", "llama3.1:8b", true)
+	if err != nil {
+		t.Fatalf("NewCompatibleClient failed: %v", err)
+	}
+
+	schema := &llm.OutputSchema{
+		Name: "submit_evaluation",
+		Schema: map[string]any{
+			"type":     "object",
+			"required": []any{"score"},
+		},
+	}
+	if _, err := client.InvokeModel(context.Background(), llm.LLMRequest{Prompt: "p", MaxTokens: 64, JSONOutput: true, Schema: schema}); err != nil {
+		t.Fatalf("InvokeModel failed: %v", err)
+	}
+
+	format, _ := requests[0]["response_format"].(map[string]any)
+	jsonSchema, _ := format["json_schema"].(map[string]any)
+	if format["type"] != "json_schema" || jsonSchema["name"] != "submit_evaluation" || jsonSchema["schema"] == nil {
+		t.Errorf("expected json_schema response format, got %v", requests[0]["response_format"])
+	}
+
+	// Without structured output support the schema falls back to JSON mode
+	client.StructuredOutput = false
+	if _, err := client.InvokeModel(context.Background(), llm.LLMRequest{Prompt: "p", MaxTokens: 64, Schema: schema}); err != nil {
+		t.Fatalf("InvokeModel failed: %v", err)
+	}
+	format, _ = requests[1]["response_format"].(map[string]any)
+	if format["type"] != "json_object" {
+		t.Errorf("expected json_object fallback, got %v", requests[1]["response_format"])
+	}
+}
+
+func TestEmbeddingClient_Embed(t *testing.T) {
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		requests = append(requests, body)
+
+		// Embeddings may be returned out of order, they are matched by index
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"object": "list",
+			"model": "text-embedding-3-small",
+			"data": [
+				{"object": "embedding", "index": 1, "embedding": [0.0, 1.0]},
+				{"object": "embedding", "index": 0, "embedding": [1.0, 0.0]}
+			],
+			"usage": {"prompt_tokens": 8, "total_tokens": 8}
+		}`))
+	}))
+	defer server.Close()
+
+	client, err := NewEmbeddingClient("key", "", 2)
+	if err != nil {
+		t.Fatalf("NewEmbeddingClient failed: %v", err)
+	}
+	client.Client = openai.NewClient(option.WithBaseURL(server.URL+"/v1"), option.WithAPIKey("key"))
+
+	vectors, err := client.Embed(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	if len(vectors) != 2 || vectors[0][0] != 1.0 || vectors[1][1] != 1.0 {
+		t.Errorf("Expected embeddings in input order, got %v", vectors)
+	}
+	if requests[0]["model"] != DefaultEmbeddingModelID || requests[0]["dimensions"] != 2.0 {
+		t.Errorf("Expected default model and dimensions in request, got %v", requests[0])
+	}
+}
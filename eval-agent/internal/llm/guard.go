@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+)
+
+// GuardedClient protects a model shared by all judges and workers: calls wait for
+// the rate limiter, fail fast while the circuit breaker is open, and are retried
+// with backoff in InvokeModelWithRetry. The limiter and breaker are optional.
+type GuardedClient struct {
+	client  LLMClient
+	limiter *RateLimiter
+	breaker *CircuitBreaker
+	retry   RetryPolicy
+}
+
+func NewGuardedClient(client LLMClient, limiter *RateLimiter, breaker *CircuitBreaker, retry RetryPolicy) *GuardedClient {
+	return &GuardedClient{
+		client:  client,
+		limiter: limiter,
+		breaker: breaker,
+		retry:   retry,
+	}
+}
+
+func (c *GuardedClient) InvokeModel(ctx context.Context, request LLMRequest) (*LLMResponse, error) {
+	estimate := EstimateTokens(request)
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx, estimate); err != nil {
+			return nil, err
+		}
+	}
+
+	// A call rejected by an open circuit is not made, so its reservation is given
+	// back instead of throttling the calls of the other judges
+	if c.breaker != nil {
+		if err := c.breaker.Allow(); err != nil {
+			if c.limiter != nil {
+				c.limiter.Cancel(estimate)
+			}
+			return nil, err
+		}
+	}
+
+	resp, err := c.client.InvokeModel(ctx, request)
+
+	if c.breaker != nil {
+		c.breaker.Record(err)
+	}
+	if c.limiter != nil && resp != nil {
+		if used := resp.Usage.InputTokens + resp.Usage.OutputTokens; used > 0 {
+			c.limiter.Adjust(used - estimate)
+		}
+	}
+
+	return resp, err
+}
+
+// InvokeModelWithRetry retries through the limiter and breaker, so every attempt
+// counts against the limits and an opening circuit stops the retries
+func (c *GuardedClient) InvokeModelWithRetry(ctx context.Context, request LLMRequest) (*LLMResponse, error) {
+	return Retry(ctx, c.retry, func() (*LLMResponse, error) {
+		return c.InvokeModel(ctx, request)
+	})
+}
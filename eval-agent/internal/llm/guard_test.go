@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestGuardedClient_CircuitStopsRetries(t *testing.T) {
+	logger := zerolog.Nop()
+
+	primary := &stubClient{name: "sonnet", err: &ProviderError{StatusCode: 503, Transient: true, Err: errors.New("ServiceUnavailableException")}}
+	breaker := NewCircuitBreaker("bedrock/sonnet", CircuitBreakerSettings{FailureThreshold: 2, OpenTimeout: time.Minute}, &logger)
+	guarded := NewGuardedClient(primary, nil, breaker, testRetryPolicy)
+
+	_, err := guarded.InvokeModelWithRetry(context.Background(), LLMRequest{Prompt: "p"})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected circuit to open during retries, got %v", err)
+	}
+	if primary.calls != 2 {
+		t.Errorf("expected 2 calls before the circuit opened, got %d", primary.calls)
+	}
+
+	// An open circuit fails fast and falls back to the next model
+	fallback := NewFallbackClient([]NamedClient{
+		{Name: "bedrock/sonnet", Client: guarded},
+		{Name: "openai/gpt-4o", Client: &stubClient{name: "gpt-4o"}},
+	}, &logger)
+
+	resp, err := fallback.InvokeModel(context.Background(), LLMRequest{Prompt: "p"})
+	if err != nil || resp.ModelID != "gpt-4o" {
+		t.Fatalf("expected fallback response, got %v %v", resp, err)
+	}
+	if primary.calls != 2 {
+		t.Errorf("expected no call to the model while the circuit is open, got %d calls", primary.calls)
+	}
+}
+
+type usageClient struct {
+	usage Usage
+}
+
+func (c *usageClient) InvokeModel(ctx context.Context, request LLMRequest) (*LLMResponse, error) {
+	return &LLMResponse{Content: "ok", Usage: c.usage}, nil
+}
+
+func (c *usageClient) InvokeModelWithRetry(ctx context.Context, request LLMRequest) (*LLMResponse, error) {
+	return c.InvokeModel(ctx, request)
+}
+
+func TestGuardedClient_ChargesActualUsage(t *testing.T) {
+	limiter, _ := newTestRateLimiter(RateLimits{TokensPerMinute: 1000})
+	client := &usageClient{usage: Usage{InputTokens: 700, OutputTokens: 100}}
+	guarded := NewGuardedClient(client, limiter, nil, testRetryPolicy)
+
+	// Estimated 100/4 + 50 = 75 tokens, actual usage is 800
+	if _, err := guarded.InvokeModel(context.Background(), LLMRequest{Prompt: string(make([]byte, 100)), MaxTokens: 50}); err != nil {
+		t.Fatalf("InvokeModel failed: %v", err)
+	}
+
+	if delay := limiter.reserve(300); delay == 0 {
+		t.Error("expected the actual usage to be charged against the token limit")
+	}
+}
+
+func TestGuardedClient_OpenCircuitCancelsReservation(t *testing.T) {
+	logger := zerolog.Nop()
+	limiter, _ := newTestRateLimiter(RateLimits{RequestsPerSecond: 1, TokensPerMinute: 1000})
+	breaker := NewCircuitBreaker("bedrock/sonnet", CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: time.Minute}, &logger)
+	breaker.Record(&ProviderError{StatusCode: 503, Transient: true, Err: errors.New("ServiceUnavailableException")})
+	guarded := NewGuardedClient(&usageClient{}, limiter, breaker, testRetryPolicy)
+
+	if _, err := guarded.InvokeModel(context.Background(), LLMRequest{Prompt: "p", MaxTokens: 900}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	// The request and its 900 tokens are available again
+	if delay := limiter.reserve(900); delay != 0 {
+		t.Errorf("expected the rejected call to give back its reservation, got a delay of %s", delay)
+	}
+}
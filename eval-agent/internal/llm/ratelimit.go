@@ -0,0 +1,173 @@
+package llm
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimits caps the calls to one model. A zero value disables the limit.
+type RateLimits struct {
+	RequestsPerSecond float64
+	TokensPerMinute   int
+}
+
+// Enabled reports whether any limit is set
+func (l RateLimits) Enabled() bool {
+	return l.RequestsPerSecond > 0 || l.TokensPerMinute > 0
+}
+
+// RateLimiter is a token bucket limiter for requests per second and tokens per
+// minute, shared by every caller of a model. Requests reserve their estimated
+// tokens up front and the estimate is corrected with the reported usage.
+type RateLimiter struct {
+	mu       sync.Mutex
+	requests *bucket
+	tokens   *bucket
+	now      func() time.Time
+}
+
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	return newRateLimiter(limits, time.Now)
+}
+
+func newRateLimiter(limits RateLimits, now func() time.Time) *RateLimiter {
+	l := &RateLimiter{now: now}
+	start := now()
+
+	if limits.RequestsPerSecond > 0 {
+		// Bursts up to one second of requests
+		l.requests = newBucket(math.Max(1, limits.RequestsPerSecond), limits.RequestsPerSecond, start)
+	}
+	if limits.TokensPerMinute > 0 {
+		l.tokens = newBucket(float64(limits.TokensPerMinute), float64(limits.TokensPerMinute)/60, start)
+	}
+
+	return l
+}
+
+// Wait blocks until a request of the given number of tokens is allowed or the
+// context is done
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	for {
+		delay := l.reserve(tokens)
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Adjust corrects the tokens reserved by a request once its actual usage is
+// known: a positive delta consumes more tokens, a negative one gives them back
+func (l *RateLimiter) Adjust(delta int) {
+	if l.tokens == nil || delta == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens.refill(l.now())
+	l.tokens.available = math.Min(l.tokens.available-float64(delta), l.tokens.capacity)
+}
+
+// Cancel gives back the request and the tokens of a reservation whose call was
+// not made
+func (l *RateLimiter) Cancel(tokens int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if l.requests != nil {
+		l.requests.refill(now)
+		l.requests.available = math.Min(l.requests.available+1, l.requests.capacity)
+	}
+	if l.tokens != nil {
+		l.tokens.refill(now)
+		l.tokens.available = math.Min(l.tokens.available+math.Min(float64(tokens), l.tokens.capacity), l.tokens.capacity)
+	}
+}
+
+// TryAcquire takes one request without waiting. It returns zero when the request
+// is allowed, otherwise how long to wait before trying again.
+func (l *RateLimiter) TryAcquire() time.Duration {
+	return l.reserve(0)
+}
+
+// reserve takes one request and the tokens if both are available, otherwise it
+// returns how long to wait before trying again
+func (l *RateLimiter) reserve(tokens int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var delay time.Duration
+	if l.requests != nil {
+		l.requests.refill(now)
+		delay = max(delay, l.requests.wait(1))
+	}
+	if l.tokens != nil {
+		l.tokens.refill(now)
+		delay = max(delay, l.tokens.wait(float64(tokens)))
+	}
+	if delay > 0 {
+		return delay
+	}
+
+	if l.requests != nil {
+		l.requests.available--
+	}
+	if l.tokens != nil {
+		l.tokens.available -= math.Min(float64(tokens), l.tokens.capacity)
+	}
+	return 0
+}
+
+// EstimateTokens estimates the tokens of a request before it is sent: about four
+// characters per prompt token plus the maximum output
+func EstimateTokens(request LLMRequest) int {
+	return len(request.Prompt)/4 + request.MaxTokens
+}
+
+type bucket struct {
+	capacity  float64
+	rate      float64 // Refill per second
+	available float64
+	last      time.Time
+}
+
+func newBucket(capacity float64, rate float64, now time.Time) *bucket {
+	return &bucket{
+		capacity:  capacity,
+		rate:      rate,
+		available: capacity,
+		last:      now,
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.available = math.Min(b.capacity, b.available+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// wait returns how long until n can be taken. Requests larger than the bucket
+// only wait for a full bucket, so they are not blocked forever.
+func (b *bucket) wait(n float64) time.Duration {
+	n = math.Min(n, b.capacity)
+	if b.available >= n {
+		return 0
+	}
+	return time.Duration((n - b.available) / b.rate * float64(time.Second))
+}
@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// newTestRateLimiter returns a limiter driven by the returned clock advance function
+func newTestRateLimiter(limits RateLimits) (*RateLimiter, func(time.Duration)) {
+	now := time.Unix(1700000000, 0)
+	limiter := newRateLimiter(limits, func() time.Time { return now })
+	return limiter, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestRateLimiter_Requests(t *testing.T) {
+	limiter, advance := newTestRateLimiter(RateLimits{RequestsPerSecond: 2})
+
+	if limiter.reserve(0) != 0 || limiter.reserve(0) != 0 {
+		t.Fatal("expected a burst of 2 requests to be allowed")
+	}
+	if delay := limiter.reserve(0); delay != 500*time.Millisecond {
+		t.Errorf("expected third request to wait 500ms, got %v", delay)
+	}
+
+	advance(500 * time.Millisecond)
+	if delay := limiter.reserve(0); delay != 0 {
+		t.Errorf("expected request to be allowed after refill, got %v", delay)
+	}
+}
+
+func TestRateLimiter_Tokens(t *testing.T) {
+	limiter, advance := newTestRateLimiter(RateLimits{TokensPerMinute: 600}) // 10 tokens/s
+
+	if delay := limiter.reserve(500); delay != 0 {
+		t.Fatalf("expected 500 tokens to be allowed, got %v", delay)
+	}
+	if delay := limiter.reserve(200); delay != 10*time.Second {
+		t.Errorf("expected 200 tokens to wait 10s for 100 missing tokens, got %v", delay)
+	}
+
+	// The response used fewer tokens than reserved
+	limiter.Adjust(-300)
+	if delay := limiter.reserve(200); delay != 0 {
+		t.Errorf("expected refunded tokens to be available, got %v", delay)
+	}
+
+	// A request larger than the bucket waits for a full bucket only
+	advance(time.Minute)
+	if delay := limiter.reserve(5000); delay != 0 {
+		t.Errorf("expected oversized request to pass with a full bucket, got %v", delay)
+	}
+}
+
+func TestRateLimiter_WaitCancelled(t *testing.T) {
+	limiter := NewRateLimiter(RateLimits{RequestsPerSecond: 0.001})
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatalf("expected first request to pass: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, 0); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded while waiting, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ProviderFactory creates a client for a model of the provider. An empty model ID
+// selects the provider's default model.
+type ProviderFactory func(modelID string) (LLMClient, error)
+
+// Registry resolves LLM clients by provider and model ID. Clients are created on
+// first use and shared by every judge using the same provider and model.
+type Registry struct {
+	defaultProvider string
+
+	mu        sync.Mutex
+	providers map[string]ProviderFactory
+	clients   map[string]LLMClient
+}
+
+// NewRegistry creates a registry. Models configured without a provider use defaultProvider.
+func NewRegistry(defaultProvider string) *Registry {
+	return &Registry{
+		defaultProvider: defaultProvider,
+		providers:       make(map[string]ProviderFactory),
+		clients:         make(map[string]LLMClient),
+	}
+}
+
+// RegisterProvider makes a provider available to judges under the given name
+func (r *Registry) RegisterProvider(name string, factory ProviderFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[name] = factory
+}
+
+// Register adds an already created client for a provider and model
+func (r *Registry) Register(provider string, modelID string, client LLMClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[clientKey(provider, modelID)] = client
+}
+
+// Client returns the client for the provider and model, creating it if needed
+func (r *Registry) Client(provider string, modelID string) (LLMClient, error) {
+	if provider == "" {
+		provider = r.defaultProvider
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := clientKey(provider, modelID)
+	if client, ok := r.clients[key]; ok {
+		return client, nil
+	}
+
+	factory, ok := r.providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown LLM provider: %s", provider)
+	}
+
+	client, err := factory(modelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s client for model %q: %w", provider, modelID, err)
+	}
+
+	r.clients[key] = client
+	return client, nil
+}
+
+// Default returns the client for the default provider and its default model
+func (r *Registry) Default() (LLMClient, error) {
+	return r.Client("", "")
+}
+
+func clientKey(provider string, modelID string) string {
+	return provider + "/" + modelID
+}
@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubClient struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (c *stubClient) InvokeModel(ctx context.Context, request LLMRequest) (*LLMResponse, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &LLMResponse{Content: c.name, ModelID: c.name}, nil
+}
+
+func (c *stubClient) InvokeModelWithRetry(ctx context.Context, request LLMRequest) (*LLMResponse, error) {
+	return c.InvokeModel(ctx, request)
+}
+
+func TestRegistry_Client(t *testing.T) {
+	registry := NewRegistry("bedrock")
+
+	created := map[string]int{}
+	registry.RegisterProvider("bedrock", func(modelID string) (LLMClient, error) {
+		if modelID == "" {
+			modelID = "haiku"
+		}
+		created[modelID]++
+		return &stubClient{name: modelID}, nil
+	})
+	registry.RegisterProvider("openai", func(modelID string) (LLMClient, error) {
+		return nil, errors.New("missing API key")
+	})
+
+	defaultClient, err := registry.Default()
+	if err != nil {
+		t.Fatalf("Default failed: %v", err)
+	}
+	sonnet, err := registry.Client("bedrock", "sonnet")
+	if err != nil {
+		t.Fatalf("Client failed: %v", err)
+	}
+	again, _ := registry.Client("bedrock", "sonnet")
+
+	if sonnet != again || created["sonnet"] != 1 {
+		t.Error("Expected clients to be created once and shared")
+	}
+	if defaultClient == sonnet || created["haiku"] != 1 {
+		t.Error("Expected the default client to use the provider default model")
+	}
+
+	if _, err := registry.Client("openai", "gpt-4o"); err == nil {
+		t.Error("Expected factory error to be returned")
+	}
+	if _, err := registry.Client("vertex", "gemini"); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+
+	registered := &stubClient{name: "registered"}
+	registry.Register("openai", "gpt-4o", registered)
+	if client, err := registry.Client("openai", "gpt-4o"); err != nil || client != registered {
+		t.Error("Expected registered client to be returned")
+	}
+}
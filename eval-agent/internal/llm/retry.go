@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures retries of transient errors with exponential backoff
+type RetryPolicy struct {
+	MaxRetries   int // Maximum number of attempts
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+// DefaultRetryPolicy is the policy used by the provider clients
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:   3,
+	InitialDelay: 100 * time.Millisecond,
+	MaxDelay:     12 * time.Second,
+}
+
+// Retry calls the function until it succeeds, fails with a non-transient error or
+// the attempts are exhausted. An open circuit is returned at once.
+func Retry[T any](ctx context.Context, policy RetryPolicy, call func() (T, error)) (T, error) {
+	var zero T
+	var lastErr error
+
+	for attempt := 0; attempt < policy.MaxRetries; attempt++ {
+		response, err := call()
+		if err == nil {
+			return response, nil
+		}
+
+		lastErr = err
+
+		if errors.Is(err, ErrCircuitOpen) {
+			return zero, err
+		}
+		if !IsTransientError(err) {
+			return zero, fmt.Errorf("non-retryable error: %w", err)
+		}
+		if attempt == policy.MaxRetries-1 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-time.After(Backoff(attempt, policy.InitialDelay, policy.MaxDelay)):
+		}
+	}
+
+	return zero, fmt.Errorf("max retries %d exceeded: %w", policy.MaxRetries, lastErr)
+}
+
+// Backoff returns the delay before the next attempt: the initial delay doubled per
+// attempt, capped at the maximum, with +/-20% jitter
+func Backoff(attempt int, initialDelay, maxDelay time.Duration) time.Duration {
+	backoff := float64(initialDelay) * math.Pow(2, float64(attempt))
+
+	if backoff > float64(maxDelay) {
+		backoff = float64(maxDelay)
+	}
+
+	jitter := backoff * 0.2 * (2*rand.Float64() - 1) // Random value between -20% and +20%
+	backoff += jitter
+
+	return time.Duration(backoff)
+}
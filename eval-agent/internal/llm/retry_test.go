@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = RetryPolicy{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func TestRetry(t *testing.T) {
+	throttled := &ProviderError{StatusCode: 429, Transient: true, Err: errors.New("ThrottlingException")}
+	invalid := &ProviderError{StatusCode: 400, Err: errors.New("ValidationException")}
+
+	tests := []struct {
+		name        string
+		errs        []error
+		expectCalls int
+		expectErr   string
+	}{
+		{name: "success", errs: []error{nil}, expectCalls: 1},
+		{name: "transient error is retried", errs: []error{throttled, throttled, nil}, expectCalls: 3},
+		{name: "non-transient error is returned", errs: []error{invalid}, expectCalls: 1, expectErr: "non-retryable error"},
+		{name: "attempts exhausted", errs: []error{throttled, throttled, throttled}, expectCalls: 3, expectErr: "max retries 3 exceeded"},
+		{name: "open circuit is returned at once", errs: []error{ErrCircuitOpen}, expectCalls: 1, expectErr: "circuit breaker open"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			_, err := Retry(context.Background(), testRetryPolicy, func() (*LLMResponse, error) {
+				err := tt.errs[calls]
+				calls++
+				if err != nil {
+					return nil, err
+				}
+				return &LLMResponse{Content: "ok"}, nil
+			})
+
+			if calls != tt.expectCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectCalls, calls)
+			}
+			if tt.expectErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.expectErr != "" && (err == nil || !strings.Contains(err.Error(), tt.expectErr)) {
+				t.Errorf("expected error containing %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	initial := 100 * time.Millisecond
+	max := 2 * time.Second
+
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond} {
+		got := Backoff(attempt, initial, max)
+		if got < want*8/10 || got > want*12/10 {
+			t.Errorf("attempt %d: expected %v +/-20%%, got %v", attempt, want, got)
+		}
+	}
+
+	if got := Backoff(10, initial, max); got > max*12/10 {
+		t.Errorf("expected backoff capped at %v, got %v", max, got)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"transient provider error", &ProviderError{StatusCode: 503, Transient: true, Err: errors.New("unavailable")}, true},
+		{"client provider error", &ProviderError{StatusCode: 400, Err: errors.New("bad request")}, false},
+		{"wrapped provider error", errors.Join(errors.New("judge"), &ProviderError{Transient: true, Err: errors.New("x")}), true},
+		{"open circuit", ErrCircuitOpen, true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"cancelled", context.Canceled, false},
+		{"untyped error", errors.New("ThrottlingException"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsTransientError(tt.err); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
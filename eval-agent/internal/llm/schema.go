@@ -0,0 +1,310 @@
+package llm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// OutputSchema describes the JSON object a request expects. Providers with native
+// structured output (Claude tool use, OpenAI json_schema) are constrained to it;
+// callers validate the content against it either way.
+type OutputSchema struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Schema      map[string]any `json:"schema"` // JSON Schema of the object
+}
+
+// ValidateJSON checks a decoded JSON value against a JSON Schema. It supports the
+// subset used for LLM outputs: type, properties, required, additionalProperties,
+// items, enum, minimum, maximum, minLength and maxLength. Errors on the value
+// itself name it "response".
+func ValidateJSON(schema map[string]any, value any) error {
+	return ValidateJSONAs(schema, value, "response")
+}
+
+// ValidateJSONAs checks a value like ValidateJSON, naming it subject in the errors
+// on the value itself, e.g. "answer: expected object, got array"
+func ValidateJSONAs(schema map[string]any, value any, subject string) error {
+	return validate(schema, value, subject, "")
+}
+
+// supportedKeywords are the schema keywords ValidateJSON enforces, plus
+// annotations that do not constrain the value
+var supportedKeywords = map[string]bool{
+	"type": true, "properties": true, "required": true, "additionalProperties": true,
+	"items": true, "enum": true, "minimum": true, "maximum": true, "minLength": true, "maxLength": true,
+	"$schema": true, "title": true, "description": true, "default": true, "examples": true,
+}
+
+var supportedTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true,
+}
+
+// CheckSchema reports a schema ValidateJSON cannot fully enforce, e.g. one using
+// pattern, oneOf or $ref, or an array of types. Values violating the unsupported
+// parts would otherwise pass validation.
+func CheckSchema(schema map[string]any) error {
+	return checkSchema(schema, "schema")
+}
+
+func checkSchema(schema map[string]any, path string) error {
+	// Sorted for deterministic error messages
+	keywords := make([]string, 0, len(schema))
+	for keyword := range schema {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		if !supportedKeywords[keyword] {
+			return fmt.Errorf("%s: unsupported keyword %s", path, keyword)
+		}
+	}
+
+	if value, ok := schema["type"]; ok {
+		typeName, isString := value.(string)
+		if !isString || !supportedTypes[typeName] {
+			return fmt.Errorf("%s.type: must be one of object, array, string, number, integer, boolean or null, got %v", path, value)
+		}
+	}
+	if value, ok := schema["enum"]; ok {
+		if _, isList := value.([]any); !isList {
+			return fmt.Errorf("%s.enum: must be a list", path)
+		}
+	}
+	for _, keyword := range []string{"minimum", "maximum", "minLength", "maxLength"} {
+		if value, ok := schema[keyword]; ok {
+			if _, isNumber := number(value); !isNumber {
+				return fmt.Errorf("%s.%s: must be a number", path, keyword)
+			}
+		}
+	}
+	if value, ok := schema["required"]; ok {
+		names := false
+		switch list := value.(type) {
+		case []string:
+			names = true
+		case []any:
+			names = len(stringList(list)) == len(list)
+		}
+		if !names {
+			return fmt.Errorf("%s.required: must be a list of names", path)
+		}
+	}
+	if value, ok := schema["additionalProperties"]; ok {
+		if _, isBool := value.(bool); !isBool {
+			return fmt.Errorf("%s.additionalProperties: only true or false is supported", path)
+		}
+	}
+	if value, ok := schema["items"]; ok {
+		items, isSchema := value.(map[string]any)
+		if !isSchema {
+			return fmt.Errorf("%s.items: must be a single schema", path)
+		}
+		if err := checkSchema(items, path+".items"); err != nil {
+			return err
+		}
+	}
+	if value, ok := schema["properties"]; ok {
+		properties, isMap := value.(map[string]any)
+		if !isMap {
+			return fmt.Errorf("%s.properties: must be a map", path)
+		}
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, isSchema := properties[name].(map[string]any)
+			if !isSchema {
+				return fmt.Errorf("%s.properties.%s: must be a schema", path, name)
+			}
+			if err := checkSchema(property, path+".properties."+name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validate(schema map[string]any, value any, subject string, path string) error {
+	if expected, ok := schema["type"].(string); ok && !hasType(value, expected) {
+		return fmt.Errorf("%s: expected %s, got %s", fieldName(subject, path), expected, typeName(value))
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, allowed := range enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", fieldName(subject, path), value, enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return validateObject(schema, v, subject, path)
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := validate(items, item, subject, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case float64:
+		minimum, hasMin := number(schema["minimum"])
+		maximum, hasMax := number(schema["maximum"])
+		if (hasMin && v < minimum) || (hasMax && v > maximum) {
+			return fmt.Errorf("%s: %v is out of range %s", fieldName(subject, path), v, rangeString(minimum, hasMin, maximum, hasMax))
+		}
+	case string:
+		if minLength, ok := number(schema["minLength"]); ok && float64(len(v)) < minLength {
+			return fmt.Errorf("%s: shorter than %v characters", fieldName(subject, path), minLength)
+		}
+		if maxLength, ok := number(schema["maxLength"]); ok && float64(len(v)) > maxLength {
+			return fmt.Errorf("%s: longer than %v characters", fieldName(subject, path), maxLength)
+		}
+	}
+
+	return nil
+}
+
+func validateObject(schema map[string]any, object map[string]any, subject string, path string) error {
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required field", fieldName(subject, join(path, name)))
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+
+	// Sorted for deterministic error messages
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := properties[name].(map[string]any)
+		if !ok {
+			if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+				return fmt.Errorf("%s: unexpected field", fieldName(subject, join(path, name)))
+			}
+			continue
+		}
+		if err := validate(property, object[name], subject, join(path, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hasType(value any, expected string) bool {
+	switch expected {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return true
+	}
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func number(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func stringList(value any) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []any:
+		names := make([]string, 0, len(list))
+		for _, item := range list {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	default:
+		return nil
+	}
+}
+
+func rangeString(minimum float64, hasMin bool, maximum float64, hasMax bool) string {
+	low, high := "-inf", "+inf"
+	if hasMin {
+		low = fmt.Sprint(minimum)
+	}
+	if hasMax {
+		high = fmt.Sprint(maximum)
+	}
+	return "[" + low + ", " + high + "]"
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func fieldName(subject string, path string) string {
+	if path == "" {
+		return subject
+	}
+	return strings.TrimPrefix(path, ".")
+}
@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateJSON(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"score":   map[string]any{"type": "number", "minimum": 0.0, "maximum": 1.0},
+			"reason":  map[string]any{"type": "string", "minLength": 1},
+			"verdict": map[string]any{"type": "string", "enum": []any{"pass", "fail"}},
+			"claims": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "object", "required": []any{"text"}},
+			},
+		},
+		"required":             []any{"score", "reason"},
+		"additionalProperties": false,
+	}
+
+	tests := []struct {
+		name      string
+		content   string
+		expectErr string
+	}{
+		{"valid", `{"score": 0.8, "reason": "ok"}`, ""},
+		{"valid with optional fields", `{"score": 1, "reason": "ok", "verdict": "pass", "claims": [{"text": "a"}]}`, ""},
+		{"not an object", `[1, 2]`, "response: expected object, got array"},
+		{"missing field", `{"score": 0.8}`, "reason: missing required field"},
+		{"wrong type", `{"score": "high", "reason": "ok"}`, "score: expected number, got string"},
+		{"out of range", `{"score": 1.5, "reason": "ok"}`, "score: 1.5 is out of range [0, 1]"},
+		{"too short", `{"score": 0.5, "reason": ""}`, "reason: shorter than 1 characters"},
+		{"not in enum", `{"score": 0.5, "reason": "ok", "verdict": "maybe"}`, "verdict: maybe is not one of [pass fail]"},
+		{"unexpected field", `{"score": 0.5, "reason": "ok", "extra": true}`, "extra: unexpected field"},
+		{"invalid item", `{"score": 0.5, "reason": "ok", "claims": [{"text": "a"}, {}]}`, "claims[1].text: missing required field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.content), &value); err != nil {
+				t.Fatalf("invalid test content: %v", err)
+			}
+
+			err := ValidateJSON(schema, value)
+			if tt.expectErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("expected error %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    map[string]any
+		expectErr string
+	}{
+		{"supported", map[string]any{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type":    "object",
+			"properties": map[string]any{
+				"score": map[string]any{"type": "number", "minimum": 0, "maximum": 1, "description": "the score"},
+				"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string", "enum": []any{"a", "b"}}},
+			},
+			"required":             []any{"score"},
+			"additionalProperties": false,
+		}, ""},
+		{"pattern", map[string]any{"type": "string", "pattern": "^a"}, "schema: unsupported keyword pattern"},
+		{"oneOf", map[string]any{"oneOf": []any{map[string]any{"type": "string"}}}, "unsupported keyword oneOf"},
+		{"ref in property", map[string]any{"properties": map[string]any{"a": map[string]any{"$ref": "#/defs/a"}}}, "schema.properties.a: unsupported keyword $ref"},
+		{"nested in items", map[string]any{"items": map[string]any{"type": "array", "minItems": 1}}, "schema.items: unsupported keyword minItems"},
+		{"type list", map[string]any{"type": []any{"string", "null"}}, "schema.type: must be one of"},
+		{"unknown type", map[string]any{"type": "date"}, "schema.type: must be one of"},
+		{"tuple items", map[string]any{"items": []any{map[string]any{"type": "string"}}}, "schema.items: must be a single schema"},
+		{"additionalProperties schema", map[string]any{"additionalProperties": map[string]any{"type": "string"}}, "only true or false"},
+		{"required not names", map[string]any{"required": []any{1}}, "schema.required: must be a list of names"},
+		{"minimum not a number", map[string]any{"minimum": "0"}, "schema.minimum: must be a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSchema(tt.schema)
+			if tt.expectErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
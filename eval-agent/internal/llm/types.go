@@ -1,12 +1,31 @@
 package llm
 
 type LLMRequest struct {
-	Prompt      string
-	MaxTokens   int
-	Temperature float64
+	Prompt      string  `json:"prompt"`
+	MaxTokens   int     `json:"max_tokens"`
+	Temperature float64 `json:"temperature"`
+	JSONOutput  bool    `json:"json_output,omitempty"` // The caller expects a JSON object; providers with a JSON mode enforce it
+
+	// Schema requests structured output: providers supporting it natively are
+	// constrained to the schema, the others fall back to JSONOutput
+	Schema *OutputSchema `json:"schema,omitempty"`
 }
 
 type LLMResponse struct {
-	Content    string
-	StopReason string
+	Content    string `json:"content"`
+	StopReason string `json:"stop_reason"`
+	ModelID    string `json:"model_id,omitempty"` // Model that produced the response
+	Usage      Usage  `json:"usage"`
+}
+
+// Usage is the number of tokens billed for a call
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// IsTruncated reports whether a response stopped at the max tokens limit
+// (Claude "max_tokens", OpenAI "length")
+func IsTruncated(stopReason string) bool {
+	return stopReason == "max_tokens" || stopReason == "length"
 }
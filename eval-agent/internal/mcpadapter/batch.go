@@ -0,0 +1,250 @@
+package mcpadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/batch"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// MaxBatchItems bounds the items of one batch tool call
+const MaxBatchItems = 500
+
+// Defaults of the batch tools, as for the batch command
+const (
+	defaultBatchWorkers        = 5
+	maxBatchWorkers            = 20
+	defaultValidationThreshold = 0.3
+)
+
+// BatchItem is one interaction of a batch tool call
+type BatchItem struct {
+	EventID         string                `json:"event_id" jsonschema:"unique event identifier within the batch"`
+	Query           string                `json:"user_query" jsonschema:"user's original query"`
+	Answer          string                `json:"answer" jsonschema:"agent response to evaluate"`
+	Context         string                `json:"context,omitempty" jsonschema:"optional context or retrieved documents"`
+	Contexts        []models.ContextChunk `json:"contexts,omitempty" jsonschema:"optional retrieved chunks in rank order, each with content and optional id, score, source and metadata"`
+	Reference       string                `json:"reference,omitempty" jsonschema:"optional reference (expected) answer"`
+	HumanAnnotation string                `json:"human_annotation,omitempty" jsonschema:"human verdict: pass, review or fail (required by validate_annotations)"`
+}
+
+// EvaluateBatchInput is the MCP tool input schema for batch evaluation.
+type EvaluateBatchInput struct {
+	Items     []BatchItem               `json:"items" jsonschema:"interactions to evaluate (at most 500)"`
+	Options   *models.EvaluationOptions `json:"options,omitempty" jsonschema:"optional overrides applied to every item, as for evaluate_response"`
+	Workers   int                       `json:"workers,omitempty" jsonschema:"concurrent evaluations (default: 5, at most 20)"`
+	BudgetUSD float64                   `json:"budget_usd,omitempty" jsonschema:"stop once the evaluations cost more than this amount in USD (default: unlimited)"`
+}
+
+// EvaluateBatchOutput is the MCP tool output of batch evaluation.
+type EvaluateBatchOutput struct {
+	Results        []models.EvaluationResult `json:"results" jsonschema:"per-item results in input order"`
+	Summary        batch.SummaryStats        `json:"summary" jsonschema:"verdict counts, average confidence and total usage"`
+	BudgetExceeded bool                      `json:"budget_exceeded,omitempty" jsonschema:"whether the budget stopped the run; the results are partial"`
+}
+
+// ValidateAnnotationsInput is the MCP tool input schema for validation against
+// human annotations.
+type ValidateAnnotationsInput struct {
+	Items     []BatchItem               `json:"items" jsonschema:"interactions with their human_annotation (at most 500)"`
+	Options   *models.EvaluationOptions `json:"options,omitempty" jsonschema:"optional overrides applied to every item, e.g. judges to validate a single judge"`
+	Workers   int                       `json:"workers,omitempty" jsonschema:"concurrent evaluations (default: 5, at most 20)"`
+	Threshold float64                   `json:"threshold,omitempty" jsonschema:"minimum Kendall's tau to pass (default: 0.3)"`
+}
+
+// ValidateAnnotationsOutput is the MCP tool output of validation.
+type ValidateAnnotationsOutput struct {
+	Validation    batch.ValidationResult `json:"validation" jsonschema:"agreement, Kendall's tau and confusion matrix against the human annotations"`
+	Disagreements []Disagreement         `json:"disagreements,omitempty" jsonschema:"items whose verdict differs from the human annotation"`
+}
+
+// Disagreement is an item whose verdict differs from its human annotation
+type Disagreement struct {
+	EventID         string         `json:"event_id"`
+	HumanAnnotation string         `json:"human_annotation"`
+	Verdict         models.Verdict `json:"verdict"`
+	Confidence      float64        `json:"confidence"`
+}
+
+// NewEvaluateBatchHandler returns a tool handler for batch evaluation.
+// Pass the returned function to mcp.AddTool.
+// A budget requires the price table, nil when none is loaded.
+func NewEvaluateBatchHandler(exec *executor.Executor, pricing *config.PricingConfig, logger *zerolog.Logger) func(context.Context, *mcp.CallToolRequest, EvaluateBatchInput) (*mcp.CallToolResult, EvaluateBatchOutput, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input EvaluateBatchInput) (*mcp.CallToolResult, EvaluateBatchOutput, error) {
+		return EvaluateBatch(ctx, exec, pricing, logger, req, input)
+	}
+}
+
+// EvaluateBatch evaluates the items with the batch processor and summarizes the results.
+func EvaluateBatch(ctx context.Context, exec *executor.Executor, pricing *config.PricingConfig, logger *zerolog.Logger, req *mcp.CallToolRequest, input EvaluateBatchInput) (*mcp.CallToolResult, EvaluateBatchOutput, error) {
+	records, err := batchRecords(input.Items, input.Options, false)
+	if err != nil {
+		return nil, EvaluateBatchOutput{}, err
+	}
+	// Without a price table every cost is 0 and the budget would never stop the run
+	if input.BudgetUSD > 0 && pricing == nil {
+		return nil, EvaluateBatchOutput{}, errors.New("budget_usd requires a price table, none is loaded")
+	}
+
+	processor := batch.NewProcessor(exec, workers(input.Workers), logger).WithBudget(input.BudgetUSD)
+	results, err := process(ctx, processor, records, newProgressNotifier(req))
+	if err != nil {
+		return nil, EvaluateBatchOutput{}, err
+	}
+
+	return nil, EvaluateBatchOutput{
+		Results:        results,
+		Summary:        batch.ComputeStats(results),
+		BudgetExceeded: processor.BudgetExceeded(),
+	}, nil
+}
+
+// NewValidateAnnotationsHandler returns a tool handler for validation against
+// human annotations. Pass the returned function to mcp.AddTool.
+func NewValidateAnnotationsHandler(exec *executor.Executor, logger *zerolog.Logger) func(context.Context, *mcp.CallToolRequest, ValidateAnnotationsInput) (*mcp.CallToolResult, ValidateAnnotationsOutput, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input ValidateAnnotationsInput) (*mcp.CallToolResult, ValidateAnnotationsOutput, error) {
+		return ValidateAnnotations(ctx, exec, logger, req, input)
+	}
+}
+
+// ValidateAnnotations evaluates the annotated items and correlates the verdicts
+// with the human annotations.
+func ValidateAnnotations(ctx context.Context, exec *executor.Executor, logger *zerolog.Logger, req *mcp.CallToolRequest, input ValidateAnnotationsInput) (*mcp.CallToolResult, ValidateAnnotationsOutput, error) {
+	records, err := batchRecords(input.Items, input.Options, true)
+	if err != nil {
+		return nil, ValidateAnnotationsOutput{}, err
+	}
+
+	threshold := input.Threshold
+	if threshold == 0.0 {
+		threshold = defaultValidationThreshold
+	}
+
+	processor := batch.NewProcessor(exec, workers(input.Workers), logger)
+	results, err := process(ctx, processor, records, newProgressNotifier(req))
+	if err != nil {
+		return nil, ValidateAnnotationsOutput{}, err
+	}
+
+	annotations := make(map[string]string, len(input.Items))
+	for _, item := range input.Items {
+		annotations[item.EventID] = item.HumanAnnotation
+	}
+
+	var output ValidateAnnotationsOutput
+	pairs := make([]batch.AnnotationPair, len(results))
+	for i, result := range results {
+		pairs[i] = batch.AnnotationPair{
+			EventID:         result.ID,
+			HumanAnnotation: annotations[result.ID],
+			LLMVerdict:      result.Verdict,
+			Confidence:      result.Confidence,
+		}
+		if pairs[i].HumanAnnotation != string(result.Verdict) {
+			output.Disagreements = append(output.Disagreements, Disagreement{
+				EventID:         result.ID,
+				HumanAnnotation: pairs[i].HumanAnnotation,
+				Verdict:         result.Verdict,
+				Confidence:      result.Confidence,
+			})
+		}
+	}
+
+	validation, err := batch.ValidateAnnotations(pairs, threshold)
+	if err != nil {
+		return nil, ValidateAnnotationsOutput{}, err
+	}
+	output.Validation = *validation
+	return nil, output, nil
+}
+
+// batchRecords validates the items and converts them to batch input records
+func batchRecords(items []BatchItem, options *models.EvaluationOptions, annotated bool) ([]batch.InputRecord, error) {
+	if len(items) == 0 {
+		return nil, errors.New("items is required")
+	}
+	if len(items) > MaxBatchItems {
+		return nil, fmt.Errorf("at most %d items per call, got %d", MaxBatchItems, len(items))
+	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(items))
+	records := make([]batch.InputRecord, len(items))
+	for i, item := range items {
+		switch {
+		case item.EventID == "":
+			return nil, fmt.Errorf("items[%d].event_id is required", i)
+		case seen[item.EventID]:
+			return nil, fmt.Errorf("items[%d].event_id %s is not unique", i, item.EventID)
+		case item.Query == "":
+			return nil, fmt.Errorf("items[%d].user_query is required", i)
+		case item.Answer == "":
+			return nil, fmt.Errorf("items[%d].answer is required", i)
+		case annotated && !validAnnotation(item.HumanAnnotation):
+			return nil, fmt.Errorf("items[%d].human_annotation must be pass, review or fail", i)
+		}
+		seen[item.EventID] = true
+
+		records[i] = batch.InputRecord{
+			LineNumber: i + 1,
+			Request: models.EvaluationRequest{
+				EventID: item.EventID,
+				Options: options,
+				Interaction: models.Interaction{
+					UserQuery: item.Query,
+					Context:   item.Context,
+					Contexts:  item.Contexts,
+					Answer:    item.Answer,
+					Reference: item.Reference,
+				},
+			},
+		}
+	}
+	return records, nil
+}
+
+func validAnnotation(annotation string) bool {
+	switch models.Verdict(annotation) {
+	case models.VerdictPass, models.VerdictReview, models.VerdictFail:
+		return true
+	}
+	return false
+}
+
+func workers(n int) int {
+	if n <= 0 {
+		return defaultBatchWorkers
+	}
+	return min(n, maxBatchWorkers)
+}
+
+// process runs the records through the processor and returns the results in
+// record order. Records skipped by the budget have no result. Progress is
+// reported per completed item. The options are the same for every record, so
+// the first record that cannot be evaluated, e.g. for an unknown judge, fails the call.
+func process(ctx context.Context, processor *batch.Processor, records []batch.InputRecord, progress *progressNotifier) ([]models.EvaluationResult, error) {
+	byID := make(map[string]models.EvaluationResult, len(records))
+	for result := range processor.Process(ctx, records) {
+		byID[result.ID] = result
+		progress.notify(ctx, len(byID), len(records), fmt.Sprintf("%d/%d items evaluated", len(byID), len(records)))
+	}
+	if failures := processor.Failures(); len(failures) > 0 {
+		return nil, failures[0].Err
+	}
+
+	results := make([]models.EvaluationResult, 0, len(byID))
+	for _, record := range records {
+		if result, ok := byID[record.Request.EventID]; ok {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
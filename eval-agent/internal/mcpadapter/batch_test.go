@@ -0,0 +1,152 @@
+package mcpadapter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/aggregator"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func newTestLogger() *zerolog.Logger {
+	logger := zerolog.Nop()
+	return &logger
+}
+
+type precheckStub struct{}
+
+func (precheckStub) Run(evalCtx models.EvaluationContext) []models.StageResult {
+	return []models.StageResult{{Name: "length", Score: 1.0}}
+}
+
+// answerJudge scores answers containing "good" high and the others low
+type answerJudge struct {
+	name string
+}
+
+func (j *answerJudge) Name() string { return j.name }
+
+func (j *answerJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContext) models.StageResult {
+	score := 0.1
+	if strings.Contains(evalCtx.Answer, "good") {
+		score = 0.9
+	}
+	return models.StageResult{Name: j.name + "-judge", Score: score}
+}
+
+// selectiveRunner serves its judges like the reloadable judges of a configuration
+type selectiveRunner struct {
+	*judge.JudgeRunner
+}
+
+func (r selectiveRunner) Select(names []string) (*judge.JudgeRunner, string, error) {
+	selected, err := r.JudgeRunner.Select(names)
+	return selected, "", err
+}
+
+func newTestExecutor() *executor.Executor {
+	logger := newTestLogger()
+	runner := judge.NewJudgeRunner([]judge.Judge{&answerJudge{name: "relevance"}}, logger)
+	agg := aggregator.NewAggregator(aggregator.Weights{PreChecks: 0.3, LLMJudge: 0.7}, logger)
+	return executor.NewExecutor(precheckStub{}, selectiveRunner{runner}, agg, 0.2, logger)
+}
+
+func TestEvaluateBatch(t *testing.T) {
+	input := EvaluateBatchInput{
+		Items: []BatchItem{
+			{EventID: "e1", Query: "q", Answer: "good answer"},
+			{EventID: "e2", Query: "q", Answer: "bad answer"},
+			{EventID: "e3", Query: "q", Answer: "another good answer"},
+		},
+		Workers: 3,
+	}
+
+	_, output, err := EvaluateBatch(context.Background(), newTestExecutor(), nil, newTestLogger(), nil, input)
+	if err != nil {
+		t.Fatalf("EvaluateBatch failed: %v", err)
+	}
+
+	wantVerdicts := []models.Verdict{models.VerdictPass, models.VerdictFail, models.VerdictPass}
+	if len(output.Results) != len(wantVerdicts) {
+		t.Fatalf("Expected %d results, got %d", len(wantVerdicts), len(output.Results))
+	}
+	for i, result := range output.Results {
+		if result.ID != input.Items[i].EventID || result.Verdict != wantVerdicts[i] {
+			t.Errorf("Result %d: expected %s %s, got %s %s", i, input.Items[i].EventID, wantVerdicts[i], result.ID, result.Verdict)
+		}
+	}
+	if output.Summary.Total != 3 || output.Summary.PassCount != 2 || output.Summary.FailCount != 1 {
+		t.Errorf("Unexpected summary %+v", output.Summary)
+	}
+	if output.BudgetExceeded {
+		t.Error("Expected no budget to be exceeded")
+	}
+}
+
+func TestEvaluateBatch_InvalidInput(t *testing.T) {
+	item := BatchItem{EventID: "e1", Query: "q", Answer: "a"}
+
+	tests := []struct {
+		name    string
+		input   EvaluateBatchInput
+		wantErr string
+	}{
+		{"no items", EvaluateBatchInput{}, "items is required"},
+		{"too many items", EvaluateBatchInput{Items: make([]BatchItem, MaxBatchItems+1)}, "at most"},
+		{"missing event id", EvaluateBatchInput{Items: []BatchItem{{Query: "q", Answer: "a"}}}, "event_id is required"},
+		{"duplicate event id", EvaluateBatchInput{Items: []BatchItem{item, item}}, "not unique"},
+		{"missing answer", EvaluateBatchInput{Items: []BatchItem{{EventID: "e1", Query: "q"}}}, "answer is required"},
+		{"invalid options", EvaluateBatchInput{Items: []BatchItem{item}, Options: &models.EvaluationOptions{Judges: []string{""}}}, "judges[0] is empty"},
+		{"budget without pricing", EvaluateBatchInput{Items: []BatchItem{item}, BudgetUSD: 1}, "requires a price table"},
+		{"unknown judge", EvaluateBatchInput{Items: []BatchItem{item}, Options: &models.EvaluationOptions{Judges: []string{"tone"}}}, "judge not found: tone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := EvaluateBatch(context.Background(), newTestExecutor(), nil, newTestLogger(), nil, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateAnnotations(t *testing.T) {
+	input := ValidateAnnotationsInput{
+		Items: []BatchItem{
+			{EventID: "e1", Query: "q", Answer: "good answer", HumanAnnotation: "pass"},
+			{EventID: "e2", Query: "q", Answer: "bad answer", HumanAnnotation: "fail"},
+			{EventID: "e3", Query: "q", Answer: "good answer", HumanAnnotation: "pass"},
+			{EventID: "e4", Query: "q", Answer: "bad answer", HumanAnnotation: "pass"},
+		},
+		Options: &models.EvaluationOptions{Judges: []string{"relevance"}, SkipPrechecks: true},
+	}
+
+	_, output, err := ValidateAnnotations(context.Background(), newTestExecutor(), newTestLogger(), nil, input)
+	if err != nil {
+		t.Fatalf("ValidateAnnotations failed: %v", err)
+	}
+
+	if output.Validation.TotalRecords != 4 || output.Validation.AgreementCount != 3 {
+		t.Errorf("Expected 3 of 4 agreements, got %+v", output.Validation)
+	}
+	if output.Validation.Threshold != defaultValidationThreshold {
+		t.Errorf("Expected the default threshold, got %v", output.Validation.Threshold)
+	}
+	if len(output.Disagreements) != 1 || output.Disagreements[0].EventID != "e4" || output.Disagreements[0].Verdict != models.VerdictFail {
+		t.Errorf("Expected e4 to disagree, got %+v", output.Disagreements)
+	}
+}
+
+func TestValidateAnnotations_RequiresAnnotations(t *testing.T) {
+	input := ValidateAnnotationsInput{Items: []BatchItem{{EventID: "e1", Query: "q", Answer: "a", HumanAnnotation: "maybe"}}}
+
+	_, _, err := ValidateAnnotations(context.Background(), newTestExecutor(), newTestLogger(), nil, input)
+	if err == nil || !strings.Contains(err.Error(), "human_annotation") {
+		t.Errorf("Expected a human_annotation error, got %v", err)
+	}
+}
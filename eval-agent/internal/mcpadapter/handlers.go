@@ -11,20 +11,25 @@ import (
 
 // EvaluateInput is the MCP tool input schema for full pipeline evaluation.
 type EvaluateInput struct {
-	EventID string `json:"event_id" jsonschema:"unique event identifier"`
-	Query   string `json:"user_query" jsonschema:"user's original query"`
-	Answer  string `json:"answer" jsonschema:"agent response to evaluate"`
-	Context string `json:"context,omitempty" jsonschema:"optional context or retrieved documents"`
+	EventID   string                    `json:"event_id" jsonschema:"unique event identifier"`
+	Query     string                    `json:"user_query" jsonschema:"user's original query"`
+	Answer    string                    `json:"answer" jsonschema:"agent response to evaluate"`
+	Context   string                    `json:"context,omitempty" jsonschema:"optional context or retrieved documents"`
+	Contexts  []models.ContextChunk     `json:"contexts,omitempty" jsonschema:"optional retrieved chunks in rank order, each with content and optional id, score, source and metadata"`
+	Reference string                    `json:"reference,omitempty" jsonschema:"optional reference (expected) answer"`
+	Options   *models.EvaluationOptions `json:"options,omitempty" jsonschema:"optional overrides: judges subset, verdict thresholds, aggregation weights, skip_prechecks and early_exit"`
 }
 
 // EvaluateSingleJudgeInput is the MCP tool input schema for single judge evaluation.
 type EvaluateSingleJudgeInput struct {
-	EventID   string  `json:"event_id" jsonschema:"unique event identifier"`
-	Query     string  `json:"user_query" jsonschema:"user's original query"`
-	Answer    string  `json:"answer" jsonschema:"agent response to evaluate"`
-	Context   string  `json:"context,omitempty" jsonschema:"optional context or retrieved documents"`
-	JudgeName string  `json:"judge_name" jsonschema:"judge name: relevance, faithfulness, coherence, completeness, or instruction"`
-	Threshold float64 `json:"threshold,omitempty" jsonschema:"pass/fail threshold (0.0-1.0, default: 0.7)"`
+	EventID   string                `json:"event_id" jsonschema:"unique event identifier"`
+	Query     string                `json:"user_query" jsonschema:"user's original query"`
+	Answer    string                `json:"answer" jsonschema:"agent response to evaluate"`
+	Context   string                `json:"context,omitempty" jsonschema:"optional context or retrieved documents"`
+	Contexts  []models.ContextChunk `json:"contexts,omitempty" jsonschema:"optional retrieved chunks in rank order, each with content and optional id, score, source and metadata"`
+	Reference string                `json:"reference,omitempty" jsonschema:"optional reference (expected) answer"`
+	JudgeName string                `json:"judge_name" jsonschema:"judge name, as listed by the eval://judges resource"`
+	Threshold float64               `json:"threshold,omitempty" jsonschema:"pass/fail threshold (0.0-1.0, default: 0.7)"`
 }
 
 // NewEvaluateHandler returns a tool handler that uses the given executor.
@@ -45,13 +50,20 @@ func EvaluateResponse(
 	evalCtx := models.EvaluationContext{
 		RequestID: input.EventID,
 		Query:     input.Query,
-		Context:   input.Context,
+		Context:   models.ContextText(input.Context, input.Contexts),
+		Contexts:  input.Contexts,
 		Answer:    input.Answer,
+		Reference: input.Reference,
 		CreatedAt: time.Now(),
 	}
 
-	result := exec.Execute(ctx, evalCtx)
-	return nil, result, nil
+	if err := input.Options.Validate(); err != nil {
+		return nil, models.EvaluationResult{}, err
+	}
+
+	ctx = newProgressNotifier(req).judgeProgress(ctx)
+	result, err := exec.ExecuteWithOptions(ctx, evalCtx, input.Options)
+	return nil, result, err
 }
 
 // NewEvaluateSingleJudgeHandler returns a tool handler for single judge evaluation.
@@ -72,8 +84,10 @@ func EvaluateSingleJudge(
 	evalCtx := models.EvaluationContext{
 		RequestID: input.EventID,
 		Query:     input.Query,
-		Context:   input.Context,
+		Context:   models.ContextText(input.Context, input.Contexts),
+		Contexts:  input.Contexts,
 		Answer:    input.Answer,
+		Reference: input.Reference,
 		CreatedAt: time.Now(),
 	}
 
@@ -83,6 +97,7 @@ func EvaluateSingleJudge(
 		threshold = 0.7
 	}
 
+	ctx = newProgressNotifier(req).unitProgress(ctx)
 	result, err := judgeExec.Execute(ctx, input.JudgeName, threshold, evalCtx)
 
 	return nil, result, err
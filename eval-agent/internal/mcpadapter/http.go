@@ -0,0 +1,145 @@
+package mcpadapter
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/rs/zerolog"
+)
+
+// HTTPPath is the path of the MCP endpoint of the HTTP transport
+const HTTPPath = "/mcp"
+
+// tokenLifetime is the expiration set on verified static tokens. Tokens do not
+// expire, the bearer middleware only requires an expiration in the future.
+const tokenLifetime = time.Hour
+
+// HTTPConfig configures the streamable HTTP transport
+type HTTPConfig struct {
+	// Tokens maps each accepted bearer token to the name of its client
+	Tokens map[string]string
+
+	// RequestsPerSecond limits the requests of each client, bursting up to one
+	// second of requests. Zero disables the limit.
+	RequestsPerSecond float64
+}
+
+// ParseTokens parses a comma separated list of client:token pairs, e.g.
+// "alice:s3cret,ci:t0ken"
+func ParseTokens(value string) (map[string]string, error) {
+	tokens := make(map[string]string)
+	clients := make(map[string]bool)
+	for i, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		// The entry is not quoted in errors, as it may be a token
+		client, token, ok := strings.Cut(pair, ":")
+		if !ok || client == "" || token == "" {
+			return nil, fmt.Errorf("invalid token entry %d: expected client:token", i+1)
+		}
+		if clients[client] {
+			return nil, fmt.Errorf("client %s has more than one token", client)
+		}
+		if _, ok := tokens[token]; ok {
+			return nil, fmt.Errorf("token of client %s is not unique", client)
+		}
+		clients[client] = true
+		tokens[token] = client
+	}
+	return tokens, nil
+}
+
+// NewHTTPHandler serves the server over streamable HTTP at HTTPPath. Requests
+// must carry one of the configured bearer tokens and are rate limited per client.
+func NewHTTPHandler(server *mcp.Server, cfg HTTPConfig, logger *zerolog.Logger) (http.Handler, error) {
+	if len(cfg.Tokens) == 0 {
+		return nil, fmt.Errorf("the HTTP transport requires at least one auth token")
+	}
+
+	var handler http.Handler = mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return server
+	}, nil)
+	handler = RateLimit(cfg.RequestsPerSecond, logger)(handler)
+	handler = auth.RequireBearerToken(NewTokenVerifier(cfg.Tokens), nil)(handler)
+
+	mux := http.NewServeMux()
+	mux.Handle(HTTPPath, handler)
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux, nil
+}
+
+// NewTokenVerifier accepts the given static tokens. The client name of the token
+// is the user ID of the token info, which also binds each session to its client.
+func NewTokenVerifier(tokens map[string]string) auth.TokenVerifier {
+	return func(ctx context.Context, token string, req *http.Request) (*auth.TokenInfo, error) {
+		// Compare every token in constant time, so that the response time does not
+		// tell how close a guess is
+		var client string
+		for known, name := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(known)) == 1 {
+				client = name
+			}
+		}
+		if client == "" {
+			return nil, fmt.Errorf("%w: unknown token", auth.ErrInvalidToken)
+		}
+		return &auth.TokenInfo{UserID: client, Expiration: time.Now().Add(tokenLifetime)}, nil
+	}
+}
+
+// RateLimit limits the requests of each authenticated client. Requests over the
+// limit are rejected with 429 Too Many Requests and a Retry-After header.
+func RateLimit(requestsPerSecond float64, logger *zerolog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if requestsPerSecond <= 0 {
+			return next
+		}
+
+		var mu sync.Mutex
+		limiters := make(map[string]*llm.RateLimiter)
+		limiter := func(client string) *llm.RateLimiter {
+			mu.Lock()
+			defer mu.Unlock()
+			l, ok := limiters[client]
+			if !ok {
+				l = llm.NewRateLimiter(llm.RateLimits{RequestsPerSecond: requestsPerSecond})
+				limiters[client] = l
+			}
+			return l
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := clientName(r)
+			if delay := limiter(client).TryAcquire(); delay > 0 {
+				logger.Warn().Str("client", client).Dur("retry_after", delay).Msg("MCP client rate limited")
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientName returns the client of an authenticated request, or its remote
+// address when it is not authenticated
+func clientName(r *http.Request) string {
+	if info := auth.TokenInfoFromContext(r.Context()); info != nil && info.UserID != "" {
+		return info.UserID
+	}
+	return r.RemoteAddr
+}
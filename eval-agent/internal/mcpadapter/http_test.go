@@ -0,0 +1,163 @@
+package mcpadapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestParseTokens(t *testing.T) {
+	tokens, err := ParseTokens(" alice:s3cret , ci:t0k:en ,")
+	if err != nil {
+		t.Fatalf("ParseTokens failed: %v", err)
+	}
+	if len(tokens) != 2 || tokens["s3cret"] != "alice" || tokens["t0k:en"] != "ci" {
+		t.Errorf("Unexpected tokens %v", tokens)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{"missing client", ":s3cret", "entry 1"},
+		{"missing token", "alice:s3cret,ci", "entry 2"},
+		{"duplicate client", "alice:s3cret,alice:other", "more than one token"},
+		{"duplicate token", "alice:s3cret,ci:s3cret", "not unique"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTokens(tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if err != nil && strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("Expected the error not to reveal the token: %v", err)
+			}
+		})
+	}
+}
+
+func TestTokenVerifier(t *testing.T) {
+	verify := NewTokenVerifier(map[string]string{"s3cret": "alice"})
+
+	info, err := verify(context.Background(), "s3cret", nil)
+	if err != nil {
+		t.Fatalf("Expected the token to be accepted: %v", err)
+	}
+	if info.UserID != "alice" || !info.Expiration.After(time.Now()) {
+		t.Errorf("Unexpected token info %+v", info)
+	}
+
+	if _, err := verify(context.Background(), "guess", nil); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestRateLimit_PerClient(t *testing.T) {
+	handler := RateLimit(1, newTestLogger())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	verify := NewTokenVerifier(map[string]string{"a": "alice", "b": "bob"})
+	handler = auth.RequireBearerToken(verify, nil)(handler)
+
+	call := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, HTTPPath, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := call("a"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the first request of alice to pass, got %d", rec.Code)
+	}
+	rec := call("a")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected the second request of alice to be limited, got %d (Retry-After %q)", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := call("b"); rec.Code != http.StatusOK {
+		t.Errorf("Expected bob to have a separate limit, got %d", rec.Code)
+	}
+}
+
+func TestNewHTTPHandler_RequiresTokens(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	if _, err := NewHTTPHandler(server, HTTPConfig{}, newTestLogger()); err == nil {
+		t.Error("Expected an error without tokens")
+	}
+}
+
+// bearerTransport authenticates the requests of the test client
+type bearerTransport struct {
+	token string
+}
+
+func (b bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestHTTPHandler_EvaluateWithProgress(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "evaluate_response"}, NewEvaluateHandler(newTestExecutor()))
+
+	handler, err := NewHTTPHandler(server, HTTPConfig{Tokens: map[string]string{"s3cret": "alice"}}, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewHTTPHandler failed: %v", err)
+	}
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	// Unauthenticated requests are rejected
+	resp, err := http.Post(httpServer.URL+HTTPPath, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", resp.StatusCode)
+	}
+
+	progress := make(chan *mcp.ProgressNotificationParams, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			progress <- req.Params
+		},
+	})
+	ctx := context.Background()
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL + HTTPPath,
+		HTTPClient: &http.Client{Transport: bearerTransport{token: "s3cret"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "eval-1"},
+		Name:      "evaluate_response",
+		Arguments: map[string]any{"event_id": "e1", "user_query": "q", "answer": "good answer"},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("CallTool failed: %v %+v", err, result)
+	}
+
+	select {
+	case p := <-progress:
+		if p.ProgressToken != "eval-1" || p.Progress != 1 || p.Total != 1 || !strings.Contains(p.Message, "relevance-judge") {
+			t.Errorf("Unexpected progress %+v", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a progress notification")
+	}
+}
@@ -0,0 +1,65 @@
+package mcpadapter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// progressNotifier sends progress notifications for a tool call, if the client
+// asked for them with a progress token
+type progressNotifier struct {
+	req   *mcp.CallToolRequest
+	token any
+}
+
+func newProgressNotifier(req *mcp.CallToolRequest) *progressNotifier {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return nil
+	}
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return nil
+	}
+	return &progressNotifier{req: req, token: token}
+}
+
+// notify reports the progress. A notification that cannot be delivered does
+// not fail the call, the client only misses an update.
+func (p *progressNotifier) notify(ctx context.Context, done int, total int, message string) {
+	if p == nil {
+		return
+	}
+	_ = p.req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: p.token,
+		Progress:      float64(done),
+		Total:         float64(total),
+		Message:       message,
+	})
+}
+
+// judgeProgress returns a context notifying the client each time a judge of the
+// evaluation completes, so that long evaluations do not look hung
+func (p *progressNotifier) judgeProgress(ctx context.Context) context.Context {
+	if p == nil {
+		return ctx
+	}
+	return judge.WithProgress(ctx, func(result models.StageResult, done int, total int) {
+		p.notify(ctx, done, total, fmt.Sprintf("%s completed (%d/%d judges)", result.Name, done, total))
+	})
+}
+
+// unitProgress returns a context notifying the client each time a unit of a
+// per-unit judge (chunk, statement or claim) is judged, so that a single judge
+// evaluating many units does not look hung
+func (p *progressNotifier) unitProgress(ctx context.Context) context.Context {
+	if p == nil {
+		return ctx
+	}
+	return judge.WithUnitProgress(ctx, func(judgeName string, unit string, done int, total int) {
+		p.notify(ctx, done, total, fmt.Sprintf("%s: %d/%d %ss judged", judgeName, done, total, unit))
+	})
+}
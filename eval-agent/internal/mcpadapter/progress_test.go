@@ -0,0 +1,94 @@
+package mcpadapter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+)
+
+// relevantLLMClient judges every prompt relevant
+type relevantLLMClient struct{}
+
+func (relevantLLMClient) InvokeModel(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	return &llm.LLMResponse{Content: `{"score": 1.0, "reason": "relevant"}`}, nil
+}
+
+func (c relevantLLMClient) InvokeModelWithRetry(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	return c.InvokeModel(ctx, request)
+}
+
+// judgeFactoryStub serves a single judge
+type judgeFactoryStub struct {
+	judge judge.Judge
+}
+
+func (f judgeFactoryStub) Get(judgeName string) (judge.Judge, error) {
+	return f.judge, nil
+}
+
+func TestEvaluateSingleJudge_ReportsUnitProgress(t *testing.T) {
+	chunkJudge, err := judge.NewLLMJudge(config.JudgeConfiguration{
+		Name:     "chunk-relevance",
+		PerChunk: true,
+		Prompt:   "{{.Chunk.Content}}",
+		Model:    &config.ModelConfig{MaxTokens: 256},
+	}, relevantLLMClient{}, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewLLMJudge failed: %v", err)
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	judgeExec := executor.NewJudgeExecutor(judgeFactoryStub{judge: chunkJudge}, newTestLogger())
+	mcp.AddTool(server, &mcp.Tool{Name: "evaluate_single_judge"}, NewEvaluateSingleJudgeHandler(judgeExec))
+
+	progress := make(chan *mcp.ProgressNotificationParams, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			progress <- req.Params
+		},
+	})
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("Server connect failed: %v", err)
+	}
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta: mcp.Meta{"progressToken": "judge-1"},
+		Name: "evaluate_single_judge",
+		Arguments: map[string]any{
+			"event_id":   "e1",
+			"judge_name": "chunk-relevance",
+			"user_query": "q",
+			"answer":     "a",
+			"contexts":   []map[string]any{{"id": "doc-1", "content": "first"}, {"id": "doc-2", "content": "second"}},
+		},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("CallTool failed: %v %+v", err, result)
+	}
+
+	var last *mcp.ProgressNotificationParams
+	for last == nil || last.Progress < 2 {
+		select {
+		case last = <-progress:
+			if last.ProgressToken != "judge-1" || last.Total != 2 || !strings.Contains(last.Message, "chunks judged") {
+				t.Fatalf("Unexpected progress %+v", last)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected a progress notification per chunk, last %+v", last)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package mcpadapter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DebugJudgePrompt is the MCP prompt guiding an agent through a judge prompt
+// debugging session, from validation to a verified prompt change.
+var DebugJudgePrompt = &mcp.Prompt{
+	Name:        "debug_judge_prompt",
+	Title:       "Debug a judge prompt",
+	Description: "Validate a judge against human annotations, find why it disagrees and verify a prompt fix",
+	Arguments: []*mcp.PromptArgument{
+		{Name: "judge_name", Description: "Judge to debug, as listed by the " + JudgesResourceURI + " resource", Required: true},
+		{Name: "threshold", Description: "Minimum Kendall's tau for the judge to pass (default: 0.3)"},
+	},
+}
+
+// ExplainVerdictPrompt is the MCP prompt guiding an agent through the analysis
+// of a single verdict that a human annotator disagrees with.
+var ExplainVerdictPrompt = &mcp.Prompt{
+	Name:        "explain_judge_verdict",
+	Title:       "Explain a judge verdict",
+	Description: "Find out why a judge scored one interaction differently from the human annotation",
+	Arguments: []*mcp.PromptArgument{
+		{Name: "judge_name", Description: "Judge that produced the verdict", Required: true},
+		{Name: "human_annotation", Description: "Expected verdict: pass, review or fail"},
+	},
+}
+
+// NewDebugJudgePromptHandler returns the handler of DebugJudgePrompt.
+// Pass the returned function to mcp.Server.AddPrompt.
+func NewDebugJudgePromptHandler() mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		judgeName, err := requiredArgument(req, "judge_name")
+		if err != nil {
+			return nil, err
+		}
+		threshold := defaultValidationThreshold
+		if value := req.Params.Arguments["threshold"]; value != "" {
+			if threshold, err = strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("threshold must be a number: %w", err)
+			}
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Debug the prompt of the eval-agent judge %q until it agrees with human judgment (Kendall's tau >= %g).\n\n", judgeName, threshold)
+		fmt.Fprintf(&b, "1. Read the %s resource. Note the judge's description, mode, requires_context, model and prompt_hash. Read %s for the default verdict thresholds.\n", JudgesResourceURI, ConfigResourceURI)
+		b.WriteString("2. Collect at least 20 interactions with a human_annotation (pass, review or fail), covering all three verdicts. Include context for judges that require it.\n")
+		fmt.Fprintf(&b, "3. Call validate_annotations with the items, threshold %g and options {\"judges\": [%q], \"skip_prechecks\": true}, so that the verdicts come from this judge alone.\n", threshold, judgeName)
+		b.WriteString("4. Read the confusion matrix and the disagreements. Verdicts that are consistently one step too high or too low with a good tau point at the thresholds, not the prompt: retry with options.thresholds before changing the prompt.\n")
+		fmt.Fprintf(&b, "5. Call evaluate_batch with the disagreeing items and the same options, and read the reason of each %s-judge stage. Group the disagreements by cause, e.g. a rubric that is too strict, a missing criterion, or context the judge ignores.\n", judgeName)
+		b.WriteString("6. Propose one prompt change per cause in the judge's prompt file under configs/. Explain which disagreements it should fix and which agreements it could break.\n")
+		fmt.Fprintf(&b, "7. After the change is applied and the configuration reloaded, check that the prompt_hash in %s changed, then repeat step 3. Keep the change only if tau improves; report tau and agreement before and after.\n", JudgesResourceURI)
+
+		return &mcp.GetPromptResult{
+			Description: fmt.Sprintf("Debug the %s judge prompt", judgeName),
+			Messages:    []*mcp.PromptMessage{{Role: "user", Content: &mcp.TextContent{Text: b.String()}}},
+		}, nil
+	}
+}
+
+// NewExplainVerdictPromptHandler returns the handler of ExplainVerdictPrompt.
+// Pass the returned function to mcp.Server.AddPrompt.
+func NewExplainVerdictPromptHandler() mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		judgeName, err := requiredArgument(req, "judge_name")
+		if err != nil {
+			return nil, err
+		}
+		expected := req.Params.Arguments["human_annotation"]
+		if expected != "" && !validAnnotation(expected) {
+			return nil, fmt.Errorf("human_annotation must be pass, review or fail")
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Explain why the eval-agent judge %q scored an interaction", judgeName)
+		if expected != "" {
+			fmt.Fprintf(&b, " differently from the human annotation %q", expected)
+		}
+		b.WriteString(". Ask me for the interaction (query, answer, context, reference) if it is not in the conversation yet.\n\n")
+		fmt.Fprintf(&b, "1. Read the %s resource for the judge's description, mode and requires_context. A judge that requires context scores poorly without it.\n", JudgesResourceURI)
+		fmt.Fprintf(&b, "2. Call evaluate_single_judge with judge_name %q and the interaction. Read the score, the reason and, for reasoning judges, the rationale.\n", judgeName)
+		b.WriteString("3. Check the per-unit details when present: chunk_scores, statements or claims show which chunk, reference statement or claim drove the score.\n")
+		b.WriteString("4. Decide whether the judge misread the interaction, applied its rubric correctly to a case the rubric does not cover, or whether the annotation itself is questionable. Quote the parts of the reason that support your conclusion.\n")
+		b.WriteString("5. If the prompt is at fault, suggest the smallest rubric change that would score this interaction as expected, and name similar interactions to re-check with evaluate_batch.\n")
+
+		return &mcp.GetPromptResult{
+			Description: fmt.Sprintf("Explain a %s judge verdict", judgeName),
+			Messages:    []*mcp.PromptMessage{{Role: "user", Content: &mcp.TextContent{Text: b.String()}}},
+		}, nil
+	}
+}
+
+func requiredArgument(req *mcp.GetPromptRequest, name string) (string, error) {
+	value := strings.TrimSpace(req.Params.Arguments[name])
+	if value == "" {
+		return "", fmt.Errorf("argument %s is required", name)
+	}
+	return value, nil
+}
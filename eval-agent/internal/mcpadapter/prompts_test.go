@@ -0,0 +1,52 @@
+package mcpadapter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestDebugJudgePrompt(t *testing.T) {
+	req := &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{
+		Name:      DebugJudgePrompt.Name,
+		Arguments: map[string]string{"judge_name": "faithfulness", "threshold": "0.5"},
+	}}
+
+	result, err := NewDebugJudgePromptHandler()(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Getting the prompt failed: %v", err)
+	}
+	if len(result.Messages) != 1 {
+		t.Fatalf("Expected one message, got %d", len(result.Messages))
+	}
+	text := result.Messages[0].Content.(*mcp.TextContent).Text
+	for _, want := range []string{`["faithfulness"]`, "validate_annotations", "evaluate_batch", "threshold 0.5", JudgesResourceURI} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected the prompt to contain %q", want)
+		}
+	}
+}
+
+func TestPrompts_InvalidArguments(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   mcp.PromptHandler
+		arguments map[string]string
+	}{
+		{"debug without judge", NewDebugJudgePromptHandler(), map[string]string{}},
+		{"debug with invalid threshold", NewDebugJudgePromptHandler(), map[string]string{"judge_name": "relevance", "threshold": "high"}},
+		{"explain without judge", NewExplainVerdictPromptHandler(), map[string]string{"human_annotation": "pass"}},
+		{"explain with invalid annotation", NewExplainVerdictPromptHandler(), map[string]string{"judge_name": "relevance", "human_annotation": "maybe"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Arguments: tt.arguments}}
+			if _, err := tt.handler(context.Background(), req); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package mcpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+)
+
+// URIs of the judge catalog and pipeline configuration resources
+const (
+	JudgesResourceURI = "eval://judges"
+	ConfigResourceURI = "eval://config"
+)
+
+// JudgeCatalog describes the judges of the active configuration
+type JudgeCatalog interface {
+	Catalog() judge.Catalog
+}
+
+// NewJudgesResourceHandler returns a resource handler serving the judge catalog
+// as JSON. Pass the returned function to mcp.Server.AddResource.
+func NewJudgesResourceHandler(catalog JudgeCatalog) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return jsonResource(req.Params.URI, catalog.Catalog())
+	}
+}
+
+// NewConfigResourceHandler returns a resource handler serving the aggregation and
+// precheck settings as JSON. Pass the returned function to mcp.Server.AddResource.
+func NewConfigResourceHandler(exec *executor.Executor) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return jsonResource(req.Params.URI, exec.Config())
+	}
+}
+
+func jsonResource(uri string, value any) (*mcp.ReadResourceResult, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource %s: %w", uri, err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:      uri,
+			MIMEType: "application/json",
+			Text:     string(data),
+		}},
+	}, nil
+}
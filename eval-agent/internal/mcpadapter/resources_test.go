@@ -0,0 +1,42 @@
+package mcpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+)
+
+type catalogStub struct {
+	catalog judge.Catalog
+}
+
+func (c *catalogStub) Catalog() judge.Catalog {
+	return c.catalog
+}
+
+func TestJudgesResource(t *testing.T) {
+	stub := &catalogStub{catalog: judge.Catalog{
+		Version: judge.ConfigVersion{Version: 2, Hash: "abc123"},
+		Judges:  []judge.JudgeInfo{{Name: "relevance", Type: "llm", Enabled: true}},
+	}}
+
+	req := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: JudgesResourceURI}}
+	result, err := NewJudgesResourceHandler(stub)(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Reading the resource failed: %v", err)
+	}
+	if len(result.Contents) != 1 || result.Contents[0].URI != JudgesResourceURI || result.Contents[0].MIMEType != "application/json" {
+		t.Fatalf("Expected one JSON content for %s, got %+v", JudgesResourceURI, result.Contents)
+	}
+
+	var catalog judge.Catalog
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &catalog); err != nil {
+		t.Fatalf("Expected the catalog as JSON: %v", err)
+	}
+	if catalog.Version.Hash != "abc123" || len(catalog.Judges) != 1 || catalog.Judges[0].Name != "relevance" {
+		t.Errorf("Unexpected catalog %+v", catalog)
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Chunk headers of numbered contexts at the start of a line, with an optional
+	// annotation, e.g. "[1] (relevance: 0.87)"
+	chunkHeader   = regexp.MustCompile(`(?m)^[ \t]*\[(\d+)\](?:[ \t]*\([^)\n]*\))?`)
+	chunkBoundary = regexp.MustCompile(`\n\s*\n`)
+)
+
+// ContextText returns the context string of an interaction: the given string, or
+// for requests with structured contexts only, the chunks rendered as numbered blocks
+// so that string based judges and templates keep working
+func ContextText(context string, chunks []ContextChunk) string {
+	if context != "" || len(chunks) == 0 {
+		return context
+	}
+
+	blocks := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		header := fmt.Sprintf("[%d]", i+1)
+		if chunk.Source != "" {
+			header += fmt.Sprintf(" (source: %s)", chunk.Source)
+		}
+		blocks = append(blocks, header+"\n"+strings.TrimSpace(chunk.Content))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// Chunks returns the retrieved chunks in rank order. Without structured contexts,
+// the context string is split at "[n]" chunk headers at line starts, as built by
+// kg-agent, or else on blank lines.
+func (c EvaluationContext) Chunks() []ContextChunk {
+	if len(c.Contexts) > 0 {
+		return c.Contexts
+	}
+
+	var chunks []ContextChunk
+	headers := chunkHeader.FindAllStringSubmatchIndex(c.Context, -1)
+	if len(headers) == 0 {
+		for _, content := range chunkBoundary.Split(c.Context, -1) {
+			if content = strings.TrimSpace(content); content != "" {
+				chunks = append(chunks, ContextChunk{Content: content})
+			}
+		}
+		return chunks
+	}
+
+	for i, header := range headers {
+		end := len(c.Context)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		if content := strings.TrimSpace(c.Context[header[1]:end]); content != "" {
+			chunks = append(chunks, ContextChunk{ID: c.Context[header[2]:header[3]], Content: content})
+		}
+	}
+	return chunks
+}
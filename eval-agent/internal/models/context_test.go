@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChunks_NumberedContext(t *testing.T) {
+	// Context as built by kg-agent: numbered chunks with a relevance header
+	evalCtx := EvaluationContext{Context: `[1] (relevance: 0.91)
+Kubernetes deployments support rolling updates.
+
+[2] (relevance: 0.84)
+The rollout undo command reverts a deployment.
+
+Earlier revisions are kept according to revisionHistoryLimit.
+`}
+
+	chunks := evalCtx.Chunks()
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 chunks, got %d: %+v", len(chunks), chunks)
+	}
+	if chunks[0].Content != "Kubernetes deployments support rolling updates." {
+		t.Errorf("Expected the header annotation to be dropped, got %q", chunks[0].Content)
+	}
+	if chunks[1].ID != "2" || !strings.Contains(chunks[1].Content, "revisionHistoryLimit") {
+		t.Errorf("Expected chunk 2 to span the blank line, got %+v", chunks[1])
+	}
+}
+
+func TestChunks_BlankLines(t *testing.T) {
+	chunks := EvaluationContext{Context: "First chunk.\n\n\nSecond chunk.\n"}.Chunks()
+	if len(chunks) != 2 || chunks[0].Content != "First chunk." || chunks[1].Content != "Second chunk." {
+		t.Errorf("Expected 2 blank line separated chunks, got %+v", chunks)
+	}
+
+	if chunks := (EvaluationContext{}).Chunks(); len(chunks) != 0 {
+		t.Errorf("Expected no chunks without context, got %+v", chunks)
+	}
+}
+
+func TestChunks_Structured(t *testing.T) {
+	contexts := []ContextChunk{{ID: "a", Content: "Alpha"}, {ID: "b", Content: "Beta"}}
+	chunks := EvaluationContext{Context: "ignored", Contexts: contexts}.Chunks()
+	if len(chunks) != 2 || chunks[0].ID != "a" {
+		t.Errorf("Expected the structured contexts, got %+v", chunks)
+	}
+}
+
+func TestContextText(t *testing.T) {
+	if got := ContextText("plain", []ContextChunk{{Content: "chunk"}}); got != "plain" {
+		t.Errorf("Expected the context string to win, got %q", got)
+	}
+
+	got := ContextText("", []ContextChunk{{Content: " Alpha ", Source: "docs/a.md"}, {Content: "Beta"}})
+	want := "[1] (source: docs/a.md)\nAlpha\n\n[2]\nBeta"
+	if got != want {
+		t.Errorf("ContextText() = %q, want %q", got, want)
+	}
+
+	// The rendered text splits back into the same chunks
+	if chunks := (EvaluationContext{Context: got}).Chunks(); len(chunks) != 2 || chunks[0].Content != "Alpha" || chunks[1].Content != "Beta" {
+		t.Errorf("Expected the rendered chunks back, got %+v", chunks)
+	}
+}
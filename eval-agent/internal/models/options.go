@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Validate checks the ranges of the overrides. Judge names are resolved by the
+// executor against the active judges configuration.
+func (o *EvaluationOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(o.Judges))
+	for i, name := range o.Judges {
+		if name == "" {
+			return fmt.Errorf("options.judges[%d] is empty", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("options.judges lists %s twice", name)
+		}
+		seen[name] = true
+	}
+
+	if t := o.Thresholds; t != nil {
+		if t.Review < 0 || t.Pass > 1 || t.Review > t.Pass {
+			return errors.New("options.thresholds must satisfy 0 <= review <= pass <= 1")
+		}
+	}
+
+	if w := o.Weights; w != nil {
+		if w.PreChecks < 0 || w.LLMJudge < 0 {
+			return errors.New("options.weights must not be negative")
+		}
+		if math.Abs(w.PreChecks+w.LLMJudge-1) > 1e-6 {
+			return errors.New("options.weights must sum to 1")
+		}
+		if o.SkipPrechecks {
+			return errors.New("options.weights cannot be set with skip_prechecks")
+		}
+	}
+
+	if o.SkipPrechecks && o.EarlyExit != nil && *o.EarlyExit {
+		return errors.New("options.early_exit requires the prechecks")
+	}
+	return nil
+}
+
+// EarlyExitEnabled reports whether a low precheck score or a veto ends the
+// evaluation before the judges run
+func (o *EvaluationOptions) EarlyExitEnabled() bool {
+	return o == nil || o.EarlyExit == nil || *o.EarlyExit
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvaluationOptions_Validate(t *testing.T) {
+	enabled := true
+
+	tests := []struct {
+		name    string
+		options *EvaluationOptions
+		wantErr string
+	}{
+		{"nil", nil, ""},
+		{"valid", &EvaluationOptions{
+			Judges:     []string{"relevance", "faithfulness"},
+			Thresholds: &VerdictThresholds{Pass: 0.9, Review: 0.6},
+			Weights:    &AggregationWeights{PreChecks: 0.2, LLMJudge: 0.8},
+		}, ""},
+		{"empty judge", &EvaluationOptions{Judges: []string{""}}, "judges[0] is empty"},
+		{"duplicate judge", &EvaluationOptions{Judges: []string{"relevance", "relevance"}}, "lists relevance twice"},
+		{"review above pass", &EvaluationOptions{Thresholds: &VerdictThresholds{Pass: 0.5, Review: 0.8}}, "review <= pass"},
+		{"pass above 1", &EvaluationOptions{Thresholds: &VerdictThresholds{Pass: 1.5, Review: 0.5}}, "review <= pass"},
+		{"negative weight", &EvaluationOptions{Weights: &AggregationWeights{PreChecks: -0.5, LLMJudge: 1.5}}, "negative"},
+		{"weights sum", &EvaluationOptions{Weights: &AggregationWeights{PreChecks: 0.5, LLMJudge: 0.7}}, "sum to 1"},
+		{"weights without prechecks", &EvaluationOptions{SkipPrechecks: true, Weights: &AggregationWeights{PreChecks: 0, LLMJudge: 1}}, "skip_prechecks"},
+		{"early exit without prechecks", &EvaluationOptions{SkipPrechecks: true, EarlyExit: &enabled}, "requires the prechecks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestEvaluationOptions_EarlyExitEnabled(t *testing.T) {
+	disabled := false
+
+	var unset *EvaluationOptions
+	if !unset.EarlyExitEnabled() || !(&EvaluationOptions{}).EarlyExitEnabled() {
+		t.Error("Expected early exit by default")
+	}
+	if (&EvaluationOptions{EarlyExit: &disabled}).EarlyExitEnabled() {
+		t.Error("Expected early exit to be disabled")
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+// AverageScore returns the weighted mean score of the stages. Stages missing from
+// weights count with weight 1; a nil map gives the plain mean.
+func AverageScore(stages []StageResult, weights map[string]float64) float64 {
+	total, sum := 0.0, 0.0
+	for _, stage := range stages {
+		weight, ok := weights[stage.Name]
+		if !ok {
+			weight = 1
+		}
+		total += weight
+		sum += weight * stage.Score
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// Vetoes returns the names of the stages vetoing the evaluation
+func Vetoes(stages []StageResult) []string {
+	var names []string
+	for _, stage := range stages {
+		if stage.Veto {
+			names = append(names, stage.Name)
+		}
+	}
+	return names
+}
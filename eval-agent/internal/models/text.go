@@ -0,0 +1,34 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SentenceBoundary matches the end of a sentence: closing punctuation followed by
+// whitespace (captured in group 1), or a line break
+var SentenceBoundary = regexp.MustCompile(`([.!?]+)\s+|\n+`)
+
+// SplitSentences splits text into trimmed, non-empty sentences, keeping their
+// closing punctuation
+func SplitSentences(text string) []string {
+	var sentences []string
+	add := func(sentence string) {
+		if sentence = strings.TrimSpace(sentence); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+
+	start := 0
+	for _, match := range SentenceBoundary.FindAllStringSubmatchIndex(text, -1) {
+		end := match[0]
+		if match[3] != -1 {
+			end = match[3] // After the punctuation
+		}
+		add(text[start:end])
+		start = match[1]
+	}
+	add(text[start:])
+
+	return sentences
+}
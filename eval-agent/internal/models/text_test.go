@@ -0,0 +1,14 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	got := SplitSentences("First one. Second one!  Third?\nFourth")
+	want := []string{"First one.", "Second one!", "Third?", "Fourth"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("SplitSentences = %q, want %q", got, want)
+	}
+}
@@ -26,9 +26,20 @@ type Agent struct {
 }
 
 type Interaction struct {
-	UserQuery string `json:"user_query"`
-	Context   string `json:"context"`
-	Answer    string `json:"answer"`
+	UserQuery string         `json:"user_query"`
+	Context   string         `json:"context"`
+	Contexts  []ContextChunk `json:"contexts,omitempty"` // Optional: retrieved chunks in rank order, instead of or with Context
+	Answer    string         `json:"answer"`
+	Reference string         `json:"reference,omitempty"` // Optional: expected answer
+}
+
+// ContextChunk is one retrieved document of a RAG interaction
+type ContextChunk struct {
+	ID       string         `json:"id,omitempty"`
+	Content  string         `json:"content"`
+	Score    float64        `json:"score,omitempty"`  // Retrieval score
+	Source   string         `json:"source,omitempty"` // e.g. document path or URL
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 // Input message
@@ -39,23 +50,151 @@ type EvaluationRequest struct {
 	Agent            Agent       `json:"agent"`
 	Interaction      Interaction `json:"interaction"`
 	HumanAnnotation  *string     `json:"human_annotation,omitempty"` // Optional: for validation mode
+	Options          *EvaluationOptions `json:"options,omitempty"` // Optional: per-request pipeline overrides
+}
+
+// EvaluationOptions overrides the pipeline configuration for one evaluation.
+// Unset fields keep the configured behavior.
+type EvaluationOptions struct {
+	Judges        []string            `json:"judges,omitempty" jsonschema:"names of the enabled judges to run, default: all"`
+	Thresholds    *VerdictThresholds  `json:"thresholds,omitempty" jsonschema:"confidence thresholds of the pass and review verdicts, default: 0.8 and 0.5"`
+	Weights       *AggregationWeights `json:"weights,omitempty" jsonschema:"weights of the precheck and judge averages in the confidence, summing to 1"`
+	SkipPrechecks bool                `json:"skip_prechecks,omitempty" jsonschema:"run the judges only; the confidence is the judge average"`
+	EarlyExit     *bool               `json:"early_exit,omitempty" jsonschema:"end the evaluation after the prechecks on a low score or a veto, default: true"`
+
+	// ExpectedRefusal marks queries the agent should refuse, so that refusals and
+	// other non-answers are not capped by the category verdicts
+	ExpectedRefusal bool `json:"expected_refusal,omitempty" jsonschema:"the query should be refused: the category verdict caps are not applied"`
+}
+
+// VerdictThresholds are the confidences above which an evaluation passes or is
+// sent to review
+type VerdictThresholds struct {
+	Pass   float64 `json:"pass" jsonschema:"confidence above which the verdict is pass"`
+	Review float64 `json:"review" jsonschema:"confidence above which the verdict is review"`
 }
 
+// DefaultVerdictThresholds are the verdict thresholds of evaluations that do not
+// set their own
+var DefaultVerdictThresholds = VerdictThresholds{Pass: 0.8, Review: 0.5}
+
 // Normalized internal object
 type EvaluationContext struct {
-	RequestID string    `json:"request_id" jsonschema:"required,description=Unique event identifier"`
-	Query     string    `json:"user_query" jsonschema:"required,description=User's original query"`
-	Context   string    `json:"context,omitempty" jsonschema:"description=Optional context or retrieved documents"`
-	Answer    string    `json:"answer" jsonschema:"required,description=Agent response to evaluate"`
-	CreatedAt time.Time `json:"created_at" jsonschema:"description=Time when the evaluation context was created"`
+	RequestID string         `json:"request_id" jsonschema:"required,description=Unique event identifier"`
+	Query     string         `json:"user_query" jsonschema:"required,description=User's original query"`
+	Context   string         `json:"context,omitempty" jsonschema:"description=Optional context or retrieved documents"`
+	Contexts  []ContextChunk `json:"contexts,omitempty" jsonschema:"description=Optional retrieved chunks in rank order"`
+	Answer    string         `json:"answer" jsonschema:"required,description=Agent response to evaluate"`
+	Reference string         `json:"reference,omitempty" jsonschema:"description=Optional reference (expected) answer"`
+	CreatedAt time.Time      `json:"created_at" jsonschema:"description=Time when the evaluation context was created"`
 }
 
 // One evaluator's output
 type StageResult struct {
-	Name     string        `json:"name"`
-	Score    float64       `json:"score"`
-	Reason   string        `json:"reason"`
-	Duration time.Duration `json:"duration_ns"`
+	Name        string            `json:"name"`
+	Score       float64           `json:"score"`
+	Reason      string            `json:"reason"`
+	Rationale   string            `json:"rationale,omitempty"`    // Full reasoning of reasoning-mode judges
+	Findings    []Finding         `json:"findings,omitempty"`     // Spans flagged in the answer
+	Veto        bool              `json:"veto,omitempty"`         // Fails the evaluation regardless of the confidence
+	Category    string            `json:"category,omitempty"`     // Classification of the answer, e.g. refusal
+	ChunkScores []ChunkScore      `json:"chunk_scores,omitempty"` // Set by per-chunk judges
+	Statements  []StatementScore  `json:"statements,omitempty"`   // Set by per-statement judges
+	Claims      []ClaimVerdict    `json:"claims,omitempty"`       // Set by per-claim judges
+	Duration    time.Duration     `json:"duration_ns"`
+	Fingerprint *JudgeFingerprint `json:"fingerprint,omitempty"` // Set by LLM judges
+	Usage       *TokenUsage       `json:"usage,omitempty"`       // Set by LLM judges
+}
+
+// Finding is a span of the answer flagged by a checker. Start and End are byte
+// offsets; the matched text itself is not reported.
+type Finding struct {
+	Type  string `json:"type"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// TokenUsage is the LLM token usage of a stage or evaluation and its cost.
+// CostUSD is zero for models missing from the price table.
+type TokenUsage struct {
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	Unpriced     bool    `json:"unpriced,omitempty"` // Some tokens have no price, CostUSD undercounts them
+}
+
+// JudgeFingerprint identifies the judge configuration that produced a stage result
+type JudgeFingerprint struct {
+	PromptHash   string  `json:"prompt_hash"`
+	Mode         string  `json:"mode,omitempty"`
+	PerChunk     bool    `json:"per_chunk,omitempty"`
+	PerStatement bool    `json:"per_statement,omitempty"`
+	PerClaim     bool    `json:"per_claim,omitempty"`
+	Aggregation  string  `json:"chunk_aggregation,omitempty"`
+	ModelID      string  `json:"model_id,omitempty"`
+	MaxTokens    int     `json:"max_tokens"`
+	Temperature  float64 `json:"temperature"`
+}
+
+// ChunkScore is the score a per-chunk judge gave one retrieved chunk
+type ChunkScore struct {
+	ID     string  `json:"id,omitempty"`
+	Rank   int     `json:"rank"` // 1-based position in the retrieved chunks
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// StatementScore is the score a per-statement judge gave one statement of the
+// reference answer
+type StatementScore struct {
+	Statement string  `json:"statement"`
+	Supported bool    `json:"supported"`
+	Score     float64 `json:"score"`
+	Reason    string  `json:"reason"`
+}
+
+// Claim verdicts of per-claim judges
+const (
+	ClaimSupported    = "supported"
+	ClaimContradicted = "contradicted"
+	ClaimNotFound     = "not_found"
+)
+
+// ClaimVerdict is the verdict a per-claim judge gave one atomic claim of the answer
+type ClaimVerdict struct {
+	Claim   string `json:"claim"`
+	Verdict string `json:"verdict"` // supported, contradicted or not_found
+	Reason  string `json:"reason"`
+}
+
+// PipelineInfo describes the pipeline configuration that produced an evaluation result.
+// Version is a fingerprint of the other fields and of the judges configuration, so
+// results with the same version are directly comparable.
+type PipelineInfo struct {
+	Version            string             `json:"version"`
+	Weights            AggregationWeights `json:"weights"`
+	Prechecks          []string           `json:"prechecks"`
+	PrecheckWeights    map[string]float64 `json:"precheck_weights,omitempty"`  // Per checker, omitted when all are equal
+	PrecheckParams     map[string]any     `json:"precheck_params,omitempty"`   // Per checker type
+	CategoryVerdicts   map[string]Verdict `json:"category_verdicts,omitempty"` // Verdict caps per stage category
+	EarlyExitThreshold float64            `json:"early_exit_threshold"`
+	EarlyExitDisabled  bool               `json:"early_exit_disabled,omitempty"`
+	SkipPrechecks      bool               `json:"skip_prechecks,omitempty"`
+	ExpectedRefusal    bool               `json:"expected_refusal,omitempty"` // Category verdict caps not applied
+	Judges             []string           `json:"judges,omitempty"`     // Judges selected by the request, omitted when all enabled judges ran
+	Thresholds         *VerdictThresholds `json:"thresholds,omitempty"` // Verdict thresholds set by the request
+}
+
+// PipelineConfig is the configured pipeline reported to clients
+type PipelineConfig struct {
+	Pipeline   *PipelineInfo     `json:"pipeline,omitempty"`
+	Thresholds VerdictThresholds `json:"thresholds"`            // Verdict thresholds of requests that do not set their own
+	ConfigHash string            `json:"config_hash,omitempty"` // Hash of the active judges configuration
+}
+
+type AggregationWeights struct {
+	PreChecks float64 `json:"prechecks" jsonschema:"weight of the precheck average"`
+	LLMJudge  float64 `json:"llm_judge" jsonschema:"weight of the judge average"`
 }
 
 // Final output emitted to Kafka
@@ -64,4 +203,7 @@ type EvaluationResult struct {
 	Stages     []StageResult `json:"stages"`
 	Confidence float64       `json:"confidence"`
 	Verdict    Verdict       `json:"verdict"`
+	ConfigHash string        `json:"config_hash,omitempty"` // Hash of the judges configuration used
+	Pipeline   *PipelineInfo `json:"pipeline,omitempty"`
+	Usage      *TokenUsage   `json:"usage,omitempty"` // Total over all stages
 }
@@ -0,0 +1,24 @@
+package models
+
+// Add accumulates another usage into u
+func (u *TokenUsage) Add(other TokenUsage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CostUSD += other.CostUSD
+	u.Unpriced = u.Unpriced || other.Unpriced
+}
+
+// TotalUsage sums the usage of the stages, or returns nil when no stage called an LLM
+func TotalUsage(stages []StageResult) *TokenUsage {
+	var total *TokenUsage
+	for _, stage := range stages {
+		if stage.Usage == nil {
+			continue
+		}
+		if total == nil {
+			total = &TokenUsage{}
+		}
+		total.Add(*stage.Usage)
+	}
+	return total
+}
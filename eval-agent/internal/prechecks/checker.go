@@ -5,5 +5,6 @@ import (
 )
 
 type Checker interface {
+	Name() string
 	Check(evaluationContext models.EvaluationContext) models.StageResult
 }
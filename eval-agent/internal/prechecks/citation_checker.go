@@ -0,0 +1,210 @@
+package prechecks
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// DefaultMinCitationSupport is the share of a sentence's keywords that a cited
+// chunk must contain for the citation to count as supported
+const DefaultMinCitationSupport = 0.3
+
+// Ranges like [1-20] are not expanded beyond this many chunks
+const maxCitationRange = 50
+
+// Finding types reported by CitationChecker
+const (
+	FindingInvalidCitation     = "invalid_citation"     // Marker refers to a chunk that does not exist
+	FindingUnsupportedCitation = "unsupported_citation" // Cited chunk does not contain the sentence's keywords
+	FindingUncitedSentence     = "uncited_sentence"
+)
+
+// [1], [1, 3], [2-4]
+var citationMarker = regexp.MustCompile(`\[(\d+(?:\s*[-–]\s*\d+)?(?:\s*[,;]\s*\d+(?:\s*[-–]\s*\d+)?)*)\]`)
+
+// CitationChecker checks the [n] citation markers of an answer against the context
+// chunks, [n] referring to the chunk of rank n: markers must refer to existing
+// chunks, the cited chunk must contain the sentence's keywords, and every sentence
+// should carry a citation. The score is the share of cited sentences times the
+// share of valid, supported citations; offending spans are reported as findings.
+type CitationChecker struct {
+	MinSupport float64
+}
+
+func NewCitationChecker(minSupport float64) *CitationChecker {
+	if minSupport <= 0 {
+		minSupport = DefaultMinCitationSupport
+	}
+	return &CitationChecker{MinSupport: minSupport}
+}
+
+func (c *CitationChecker) Name() string {
+	return "citation-checker"
+}
+
+// citation is one chunk number referenced by a marker, with the marker's span
+type citation struct {
+	number int
+	start  int
+	end    int
+}
+
+// citedSentence is an answer sentence with its span and citations
+type citedSentence struct {
+	text      string
+	start     int
+	end       int
+	citations []citation
+}
+
+func (c *CitationChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
+	result := models.StageResult{Name: c.Name()}
+	now := time.Now()
+
+	chunks := evaluationContext.Chunks()
+	if len(chunks) == 0 {
+		result.Reason = "Context required but not provided"
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	sentences := citedSentences(evaluationContext.Answer)
+	if len(sentences) == 0 {
+		result.Reason = "Empty Answer"
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	chunkTokens := make(map[int]map[string]bool, len(chunks))
+	for i, chunk := range chunks {
+		chunkTokens[i+1] = extractUniqueTokens(tokenize(chunk.Content))
+	}
+
+	cited, total, invalid, unsupported := 0, 0, 0, 0
+	for _, sentence := range sentences {
+		if len(sentence.citations) == 0 {
+			result.Findings = append(result.Findings, models.Finding{Type: FindingUncitedSentence, Start: sentence.start, End: sentence.end})
+			continue
+		}
+		cited++
+
+		sentenceTokens := extractUniqueTokens(tokenize(citationMarker.ReplaceAllString(sentence.text, " ")))
+		for _, cite := range sentence.citations {
+			total++
+			tokens, ok := chunkTokens[cite.number]
+			if !ok {
+				invalid++
+				result.Findings = append(result.Findings, models.Finding{Type: FindingInvalidCitation, Start: cite.start, End: cite.end})
+				continue
+			}
+			if support(sentenceTokens, tokens) < c.MinSupport {
+				unsupported++
+				result.Findings = append(result.Findings, models.Finding{Type: FindingUnsupportedCitation, Start: cite.start, End: cite.end})
+			}
+		}
+	}
+
+	if total == 0 {
+		result.Reason = fmt.Sprintf("No citations in the answer (%d sentences, %d context chunks)", len(sentences), len(chunks))
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	coverage := float64(cited) / float64(len(sentences))
+	validity := float64(total-invalid-unsupported) / float64(total)
+	result.Score = coverage * validity
+	result.Reason = fmt.Sprintf("%d of %d sentences cited; %d of %d citations valid and supported", cited, len(sentences), total-invalid-unsupported, total)
+	if invalid > 0 || unsupported > 0 {
+		result.Reason += fmt.Sprintf(" (%d to missing chunks, %d unsupported)", invalid, unsupported)
+	}
+
+	result.Duration = time.Since(now)
+	return result
+}
+
+// support returns the share of the sentence tokens found in the chunk tokens; a
+// sentence without keywords is fully supported
+func support(sentenceTokens map[string]bool, chunkTokens map[string]bool) float64 {
+	if len(sentenceTokens) == 0 {
+		return 1.0
+	}
+	found := 0
+	for token := range sentenceTokens {
+		if chunkTokens[token] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(sentenceTokens))
+}
+
+// citedSentences splits the answer into sentences with their citations. Markers
+// at the start of a sentence ("... end. [2] Next") belong to the previous one.
+func citedSentences(answer string) []citedSentence {
+	var sentences []citedSentence
+
+	add := func(start int, end int) {
+		text := answer[start:end]
+		trimmed := strings.TrimLeft(text, " \t\n")
+		start += len(text) - len(trimmed)
+		text = strings.TrimSpace(trimmed)
+		if text == "" {
+			return
+		}
+
+		sentence := citedSentence{text: text, start: start, end: start + len(text)}
+		for _, marker := range citationMarker.FindAllStringSubmatchIndex(text, -1) {
+			numbers := parseCitationNumbers(text[marker[2]:marker[3]])
+			leading := len(sentences) > 0 && strings.TrimSpace(citationMarker.ReplaceAllString(text[:marker[0]], "")) == ""
+			for _, number := range numbers {
+				cite := citation{number: number, start: start + marker[0], end: start + marker[1]}
+				if leading {
+					previous := &sentences[len(sentences)-1]
+					previous.citations = append(previous.citations, cite)
+				} else {
+					sentence.citations = append(sentence.citations, cite)
+				}
+			}
+		}
+
+		// Drop sentences made of markers only
+		if strings.TrimSpace(citationMarker.ReplaceAllString(text, "")) == "" {
+			return
+		}
+		sentences = append(sentences, sentence)
+	}
+
+	start := 0
+	for _, match := range models.SentenceBoundary.FindAllStringSubmatchIndex(answer, -1) {
+		end := match[0]
+		if match[3] != -1 {
+			end = match[3] // After the punctuation
+		}
+		add(start, end)
+		start = match[1]
+	}
+	add(start, len(answer))
+
+	return sentences
+}
+
+// parseCitationNumbers parses the inside of a marker: "1", "1, 3" or "2-4"
+func parseCitationNumbers(marker string) []int {
+	var numbers []int
+	for _, part := range strings.FieldsFunc(marker, func(r rune) bool { return r == ',' || r == ';' }) {
+		bounds := strings.FieldsFunc(part, func(r rune) bool { return r == '-' || r == '–' })
+		first, _ := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		last := first
+		if len(bounds) == 2 {
+			last, _ = strconv.Atoi(strings.TrimSpace(bounds[1]))
+		}
+		for n := first; n <= last && n-first < maxCitationRange; n++ {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers
+}
@@ -0,0 +1,115 @@
+package prechecks
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// Context as built by kg-agent: numbered chunks with a relevance header
+const numberedContext = `[1] (relevance: 0.91)
+Kubernetes deployments support rolling updates with maxSurge and maxUnavailable.
+
+[2] (relevance: 0.84)
+The rollout undo command reverts a deployment to its previous revision.
+
+Earlier revisions are kept according to revisionHistoryLimit.
+`
+
+func TestCitedSentences(t *testing.T) {
+	answer := "Rolling updates use maxSurge [1]. Undo reverts a rollout. [2] Limits apply [1, 3-4]."
+	sentences := citedSentences(answer)
+	if len(sentences) != 3 {
+		t.Fatalf("Expected 3 sentences, got %d: %+v", len(sentences), sentences)
+	}
+
+	numbers := func(s citedSentence) []int {
+		var n []int
+		for _, cite := range s.citations {
+			n = append(n, cite.number)
+		}
+		return n
+	}
+	if got := numbers(sentences[1]); len(got) != 1 || got[0] != 2 {
+		t.Errorf("Expected the leading [2] to cite the previous sentence, got %v", got)
+	}
+	if got := numbers(sentences[2]); len(got) != 3 || got[1] != 3 || got[2] != 4 {
+		t.Errorf("Expected citations 1, 3 and 4, got %v", got)
+	}
+	if cite := sentences[0].citations[0]; answer[cite.start:cite.end] != "[1]" {
+		t.Errorf("Expected the marker span, got %q", answer[cite.start:cite.end])
+	}
+}
+
+func TestCitationChecker_Check(t *testing.T) {
+	checker := NewCitationChecker(0)
+
+	tests := []struct {
+		name     string
+		answer   string
+		score    float64
+		findings []string
+	}{
+		{
+			"all cited and supported",
+			"Deployments support rolling updates with maxSurge [1]. The rollout undo command reverts to the previous revision [2].",
+			1.0, nil,
+		},
+		{
+			"uncited sentence",
+			"Deployments support rolling updates with maxSurge [1]. Always back up etcd first.",
+			0.5, []string{FindingUncitedSentence},
+		},
+		{
+			"citation to a missing chunk",
+			"Deployments support rolling updates [1][3].",
+			0.5, []string{FindingInvalidCitation},
+		},
+		{
+			"unsupported citation",
+			"Helm charts pin image digests for reproducible releases [2].",
+			0.0, []string{FindingUnsupportedCitation},
+		},
+		{"no citations", "Deployments support rolling updates.", 0.0, []string{FindingUncitedSentence}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checker.Check(models.EvaluationContext{Context: numberedContext, Answer: tt.answer})
+			if math.Abs(result.Score-tt.score) > 1e-9 {
+				t.Errorf("Expected score %f, got %f (%s)", tt.score, result.Score, result.Reason)
+			}
+			var types []string
+			for _, finding := range result.Findings {
+				types = append(types, finding.Type)
+			}
+			if strings.Join(types, ",") != strings.Join(tt.findings, ",") {
+				t.Errorf("Expected findings %v, got %v", tt.findings, types)
+			}
+		})
+	}
+}
+
+func TestCitationChecker_NoContext(t *testing.T) {
+	result := NewCitationChecker(0).Check(models.EvaluationContext{Answer: "Deployments roll [1]."})
+	if result.Score != 0 || result.Reason != "Context required but not provided" {
+		t.Errorf("Expected missing context, got %f %q", result.Score, result.Reason)
+	}
+}
+
+func TestCitationChecker_StructuredContexts(t *testing.T) {
+	evalCtx := models.EvaluationContext{
+		Contexts: []models.ContextChunk{
+			{ID: "doc-7", Content: "Deployments support rolling updates with maxSurge."},
+			{ID: "doc-2", Content: "The rollout undo command reverts a deployment."},
+		},
+		Answer: "Rollout undo reverts a deployment [2].",
+	}
+
+	result := NewCitationChecker(0).Check(evalCtx)
+	if result.Score != 1.0 {
+		t.Errorf("Expected [2] to cite the chunk of rank 2, got %f (%s)", result.Score, result.Reason)
+	}
+}
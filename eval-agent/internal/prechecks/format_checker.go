@@ -17,10 +17,14 @@ func NewFormatChecker() *FormatChecker {
 
 var repeatedPunctuation = regexp.MustCompile(`[!?.]{3,}`)
 
+func (c *FormatChecker) Name() string {
+	return "format-checker"
+}
+
 func (c *FormatChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
 
 	result := models.StageResult{
-		Name:     "format-checker",
+		Name:     c.Name(),
 		Score:    0.0,
 		Reason:   "",
 		Duration: 0,
@@ -7,25 +7,43 @@ import (
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 )
 
+// Default answer/query length ratios of LengthChecker
+const (
+	DefaultMinLengthRatio = 0.5
+	DefaultMaxLengthRatio = 10.0
+)
+
+// LengthChecker uses the default ratios for unset (zero) ratios
 type LengthChecker struct {
+	MinRatio float64
+	MaxRatio float64
 }
 
 func NewLengthChecker() *LengthChecker {
 	return &LengthChecker{}
 }
 
+func (c *LengthChecker) Name() string {
+	return "length-checker"
+}
+
 // LengthChecker scores an answer based on its length relative to the query.
 // It computes the character ratio between answer and query, penalizing answers
 // that are too short (score 0.0) or excessively long (score 0.5).
 func (c *LengthChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
-	const minRatio = 0.5
-	const maxRatio = 10.0
+	minRatio, maxRatio := c.MinRatio, c.MaxRatio
+	if minRatio == 0 {
+		minRatio = DefaultMinLengthRatio
+	}
+	if maxRatio == 0 {
+		maxRatio = DefaultMaxLengthRatio
+	}
 
 	answerLength := len(evaluationContext.Answer)
 	queryLength := len(evaluationContext.Query)
 
 	result := models.StageResult{
-		Name:     "length-checker",
+		Name:     c.Name(),
 		Score:    0.0,
 		Reason:   "",
 		Duration: 0,
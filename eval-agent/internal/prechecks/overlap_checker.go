@@ -8,12 +8,19 @@ import (
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 )
 
+// DefaultMinOverlapThreshold is used when MinOverlapThreshold is not set
+const DefaultMinOverlapThreshold = 0.1
+
 type OverlapChecker struct {
 	MinOverlapThreshold float64
 }
 
 func NewOverlapChecker() *OverlapChecker {
-	return &OverlapChecker{}
+	return &OverlapChecker{MinOverlapThreshold: DefaultMinOverlapThreshold}
+}
+
+func (c *OverlapChecker) Name() string {
+	return "overlap-checker"
 }
 
 // OverlapChecker scores an answer based on keyword overlap with the query.
@@ -21,13 +28,13 @@ func NewOverlapChecker() *OverlapChecker {
 // and returns a low score if the answer doesn't share enough terms with the query.
 func (c *OverlapChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
 
-	if c.MinOverlapThreshold == 0.0 {
-		// set default value
-		c.MinOverlapThreshold = 0.1
+	threshold := c.MinOverlapThreshold
+	if threshold == 0.0 {
+		threshold = DefaultMinOverlapThreshold
 	}
 
 	result := models.StageResult{
-		Name:     "overlap-checker",
+		Name:     c.Name(),
 		Score:    0.0,
 		Reason:   "",
 		Duration: 0,
@@ -60,7 +67,7 @@ func (c *OverlapChecker) Check(evaluationContext models.EvaluationContext) model
 	}
 
 	score := float64(count) / float64(len(uniqueQueryTokens))
-	if score < c.MinOverlapThreshold {
+	if score < threshold {
 		result.Reason = fmt.Sprintf("Low keyword overlap: %.0f%% of query terms found in answer", score*100)
 		result.Score = score
 	} else {
@@ -93,6 +100,12 @@ var stopWords = map[string]bool{
 }
 
 func (c *OverlapChecker) stringTokenizer(s string) []string {
+	return tokenize(s)
+}
+
+// tokenize lowercases the text and returns its words without punctuation, stop
+// words and single characters
+func tokenize(s string) []string {
 	s = strings.ToLower(s)
 	s = removePunctuation(s)
 
@@ -103,7 +116,6 @@ func (c *OverlapChecker) stringTokenizer(s string) []string {
 		}
 	}
 	return tokens
-
 }
 
 func removePunctuation(s string) string {
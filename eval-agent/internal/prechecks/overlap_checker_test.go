@@ -75,3 +75,15 @@ func TestOverlapChecker(t *testing.T) {
 		})
 	}
 }
+
+func TestOverlapChecker_DoesNotMutateThreshold(t *testing.T) {
+	checker := &OverlapChecker{}
+
+	result := checker.Check(models.EvaluationContext{Query: "alpha beta gamma delta epsilon stuff more words here now", Answer: "alpha"})
+	if !strings.Contains(result.Reason, "good overlap") {
+		t.Errorf("Expected default threshold to apply, got %q", result.Reason)
+	}
+	if checker.MinOverlapThreshold != 0 {
+		t.Errorf("Expected threshold to stay unset, got %f", checker.MinOverlapThreshold)
+	}
+}
@@ -0,0 +1,308 @@
+package prechecks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// Non-answer categories reported by RefusalChecker, in order of precedence
+const (
+	CategoryRefusal    = "refusal"    // Declines to answer
+	CategoryDeflection = "deflection" // Sends the user elsewhere instead of answering
+	CategoryApology    = "apology"    // Canned apology without content
+	CategoryHedging    = "hedging"    // Only hedges, no answer
+)
+
+var refusalCategories = []string{CategoryRefusal, CategoryDeflection, CategoryApology, CategoryHedging}
+
+// Defaults of RefusalChecker
+const (
+	// Refusals are looked for in the opening of the answer only
+	refusalOpeningChars = 300
+	// Deflections, apologies and hedges only count in short answers made of
+	// nothing else; in longer answers they accompany actual content
+	DefaultRefusalMaxWords = 40
+)
+
+var defaultRefusalScores = map[string]float64{
+	CategoryRefusal:    0.0,
+	CategoryDeflection: 0.2,
+	CategoryApology:    0.0,
+	CategoryHedging:    0.3,
+}
+
+// refusalPhrases is the built-in phrase library by language and category.
+// Phrases are matched case-insensitively on word boundaries, with typographic
+// apostrophes normalized.
+var refusalPhrases = map[string]map[string][]string{
+	"en": {
+		CategoryRefusal: {
+			"i can't help with", "i cannot help with", "i can't assist with", "i cannot assist with",
+			"i'm unable to", "i am unable to", "i'm not able to", "i am not able to",
+			"i can't provide", "i cannot provide", "i won't be able to", "i will not be able to",
+			"i can't answer", "i cannot answer", "i'm not allowed to", "i am not allowed to",
+			"as an ai language model", "i must decline", "i have to decline",
+		},
+		CategoryDeflection: {
+			"consult the documentation", "refer to the documentation", "check the documentation",
+			"see the official documentation", "contact support", "contact customer support",
+			"contact your administrator", "consult a professional", "ask your administrator",
+			"search online for", "i recommend searching",
+		},
+		CategoryApology: {
+			"i'm sorry", "i am sorry", "i apologize", "sorry for the inconvenience",
+			"apologies for the confusion", "sorry for the confusion",
+		},
+		CategoryHedging: {
+			"it depends", "i'm not sure", "i am not sure", "i don't know", "i do not know",
+			"hard to say", "difficult to say", "there are many factors", "i'm not certain",
+		},
+	},
+	"es": {
+		CategoryRefusal:    {"no puedo ayudar", "no puedo ayudarte", "no puedo proporcionar", "no puedo responder", "no me es posible"},
+		CategoryDeflection: {"consulte la documentación", "consulta la documentación", "contacte con soporte", "contacta con soporte"},
+		CategoryApology:    {"lo siento", "disculpe las molestias", "pido disculpas"},
+		CategoryHedging:    {"depende", "no estoy seguro", "no estoy segura", "no lo sé"},
+	},
+	"fr": {
+		CategoryRefusal:    {"je ne peux pas vous aider", "je ne peux pas répondre", "je ne peux pas fournir", "je ne suis pas en mesure"},
+		CategoryDeflection: {"consultez la documentation", "contactez le support", "contactez votre administrateur"},
+		CategoryApology:    {"je suis désolé", "je suis désolée", "désolé pour", "toutes mes excuses"},
+		CategoryHedging:    {"ça dépend", "cela dépend", "je ne suis pas sûr", "je ne sais pas"},
+	},
+	"de": {
+		CategoryRefusal:    {"ich kann ihnen nicht helfen", "ich kann dabei nicht helfen", "ich kann keine", "ich bin nicht in der lage"},
+		CategoryDeflection: {"lesen sie die dokumentation", "konsultieren sie die dokumentation", "wenden sie sich an den support", "wenden sie sich an ihren administrator"},
+		CategoryApology:    {"es tut mir leid", "entschuldigung", "ich entschuldige mich"},
+		CategoryHedging:    {"es kommt darauf an", "ich bin mir nicht sicher", "ich weiß es nicht"},
+	},
+	"it": {
+		CategoryRefusal:    {"non posso aiutarti", "non posso aiutarla", "non posso fornire", "non sono in grado di"},
+		CategoryDeflection: {"consulta la documentazione", "consulti la documentazione", "contatta il supporto"},
+		CategoryApology:    {"mi dispiace", "mi scuso", "chiedo scusa"},
+		CategoryHedging:    {"dipende", "non sono sicuro", "non sono sicura", "non lo so"},
+	},
+	"pt": {
+		CategoryRefusal:    {"não posso ajudar", "não posso fornecer", "não posso responder", "não consigo ajudar"},
+		CategoryDeflection: {"consulte a documentação", "entre em contato com o suporte"},
+		CategoryApology:    {"sinto muito", "peço desculpas", "desculpe pelo"},
+		CategoryHedging:    {"depende", "não tenho certeza", "não sei"},
+	},
+}
+
+// RefusalChecker detects refusals, deflections, canned apologies and hedging-only
+// answers with a phrase and pattern library. The detected category is reported in
+// the stage result, so the aggregator can cap the verdict of non-answers.
+type RefusalChecker struct {
+	phrases  map[string][]string
+	patterns map[string][]*regexp.Regexp
+	scores   map[string]float64
+	maxWords int
+}
+
+// RefusalOptions configures a RefusalChecker. Empty Languages enables all
+// built-in languages; Phrases and Patterns add to the library by category.
+type RefusalOptions struct {
+	Languages []string
+	Phrases   map[string][]string
+	Patterns  map[string][]string
+	Scores    map[string]float64 // Score per category (defaults: refusal and apology 0, deflection 0.2, hedging 0.3)
+	MaxWords  int                // Longest answer counted as deflection, apology or hedging
+}
+
+func NewRefusalChecker(opts RefusalOptions) (*RefusalChecker, error) {
+	checker := &RefusalChecker{
+		phrases:  make(map[string][]string),
+		patterns: make(map[string][]*regexp.Regexp),
+		scores:   make(map[string]float64),
+		maxWords: opts.MaxWords,
+	}
+	if checker.maxWords <= 0 {
+		checker.maxWords = DefaultRefusalMaxWords
+	}
+
+	languages := opts.Languages
+	if len(languages) == 0 {
+		languages = []string{"en", "es", "fr", "de", "it", "pt"}
+	}
+	for _, language := range languages {
+		library, ok := refusalPhrases[language]
+		if !ok {
+			return nil, fmt.Errorf("unsupported language: %s", language)
+		}
+		for category, phrases := range library {
+			checker.phrases[category] = append(checker.phrases[category], phrases...)
+		}
+	}
+
+	for category, phrases := range opts.Phrases {
+		if !isRefusalCategory(category) {
+			return nil, fmt.Errorf("unknown category: %s", category)
+		}
+		for _, phrase := range phrases {
+			checker.phrases[category] = append(checker.phrases[category], normalizeRefusalText(phrase))
+		}
+	}
+
+	for category, patterns := range opts.Patterns {
+		if !isRefusalCategory(category) {
+			return nil, fmt.Errorf("unknown category: %s", category)
+		}
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(`(?i)` + pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s pattern %q: %w", category, pattern, err)
+			}
+			checker.patterns[category] = append(checker.patterns[category], re)
+		}
+	}
+
+	for category, score := range defaultRefusalScores {
+		checker.scores[category] = score
+	}
+	for category, score := range opts.Scores {
+		if !isRefusalCategory(category) {
+			return nil, fmt.Errorf("unknown category: %s", category)
+		}
+		if score < 0 || score > 1 {
+			return nil, fmt.Errorf("score of %s must be in [0, 1], got %f", category, score)
+		}
+		checker.scores[category] = score
+	}
+
+	return checker, nil
+}
+
+func (c *RefusalChecker) Name() string {
+	return "refusal-checker"
+}
+
+func (c *RefusalChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
+	result := models.StageResult{Name: c.Name()}
+	now := time.Now()
+
+	category, match := c.Classify(evaluationContext.Answer)
+	if category == "" {
+		result.Score = 1.0
+		result.Reason = "No refusal or non-answer detected"
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	result.Score = c.scores[category]
+	result.Category = category
+	result.Reason = fmt.Sprintf("Answer classified as %s: %q", category, match)
+	result.Duration = time.Since(now)
+	return result
+}
+
+// Classify returns the non-answer category of the answer and the matched phrase,
+// or an empty category for an actual answer
+func (c *RefusalChecker) Classify(answer string) (string, string) {
+	text := normalizeRefusalText(answer)
+	if text == "" {
+		return "", ""
+	}
+
+	opening := text
+	if len(opening) > refusalOpeningChars {
+		opening = opening[:refusalOpeningChars]
+	}
+	if match := c.match(CategoryRefusal, opening); match != "" {
+		return CategoryRefusal, match
+	}
+	if len(strings.Fields(text)) > c.maxWords {
+		return "", ""
+	}
+
+	// Deflections, apologies and hedges make a non-answer only when every sentence
+	// is one, so that "Run go mod tidy. For details, consult the documentation."
+	// still counts as an answer. The category of highest precedence is reported.
+	best, bestMatch := len(refusalCategories), ""
+	for _, sentence := range models.SplitSentences(text) {
+		rank, match := c.classifySentence(sentence)
+		if match == "" {
+			return "", ""
+		}
+		if rank < best {
+			best, bestMatch = rank, match
+		}
+	}
+	if bestMatch == "" {
+		return "", ""
+	}
+	return refusalCategories[best], bestMatch
+}
+
+// classifySentence returns the precedence of the first deflection, apology or
+// hedging category matching the sentence and the match, or an empty match
+func (c *RefusalChecker) classifySentence(sentence string) (int, string) {
+	for rank, category := range refusalCategories {
+		if category == CategoryRefusal {
+			continue
+		}
+		if match := c.match(category, sentence); match != "" {
+			return rank, match
+		}
+	}
+	return 0, ""
+}
+
+// match returns the first phrase or pattern match of the category in the text
+func (c *RefusalChecker) match(category string, text string) string {
+	for _, phrase := range c.phrases[category] {
+		if containsPhrase(text, phrase) {
+			return phrase
+		}
+	}
+	for _, pattern := range c.patterns[category] {
+		if match := pattern.FindString(text); match != "" {
+			return match
+		}
+	}
+	return ""
+}
+
+// containsPhrase reports whether the phrase occurs in the text as whole words,
+// so that "depende" does not match "dependency"
+func containsPhrase(text string, phrase string) bool {
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], phrase)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(phrase)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !unicode.IsLetter(before)) && (end == len(text) || !unicode.IsLetter(after)) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+func isRefusalCategory(category string) bool {
+	for _, known := range refusalCategories {
+		if category == known {
+			return true
+		}
+	}
+	return false
+}
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// normalizeRefusalText lowercases the text, normalizes typographic apostrophes
+// and collapses whitespace
+func normalizeRefusalText(text string) string {
+	text = strings.ToLower(strings.TrimSpace(text))
+	text = strings.NewReplacer("’", "'", "‘", "'", "`", "'").Replace(text)
+	return whitespace.ReplaceAllString(text, " ")
+}
@@ -0,0 +1,124 @@
+package prechecks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func TestRefusalChecker_Classify(t *testing.T) {
+	checker, err := NewRefusalChecker(RefusalOptions{})
+	if err != nil {
+		t.Fatalf("NewRefusalChecker failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		answer   string
+		category string
+	}{
+		{"refusal", "I can't help with that request.", CategoryRefusal},
+		{"typographic apostrophe", "I’m unable to share internal pricing.", CategoryRefusal},
+		{"apology before refusal", "I'm sorry, but I cannot provide that information.", CategoryRefusal},
+		{"deflection", "Please consult the documentation for details.", CategoryDeflection},
+		{"apology", "I apologize for the inconvenience.", CategoryApology},
+		{"hedging", "It depends on many things, hard to say.", CategoryHedging},
+		{"spanish", "Lo siento, no puedo ayudarte con eso.", CategoryRefusal},
+		{"french", "Consultez la documentation.", CategoryDeflection},
+		{"german", "Es kommt darauf an.", CategoryHedging},
+		{"answer", "Use kubectl rollout undo deployment/web to roll back to the previous revision.", ""},
+		{"empty", "", ""},
+		{"phrase within a word", "Add the dependency to go.mod.", ""},
+		{"deflection after an answer", "Run `go mod tidy`. For details, consult the documentation.", ""},
+		{"hedge after an answer", "Set replicas to 3. I'm not sure it fits every cluster, though.", ""},
+		{"only non-answers", "I'm sorry, I don't know. Please contact support.", CategoryDeflection},
+		{
+			"hedge in a long answer",
+			"It depends on the workload. For CPU bound services, set the limit to twice the request and " +
+				"watch throttling metrics; for memory bound services, set request and limit to the same value " +
+				"so the pod gets the guaranteed QoS class and is evicted last under node pressure.",
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category, _ := checker.Classify(tt.answer)
+			if category != tt.category {
+				t.Errorf("Classify(%q) = %q, want %q", tt.answer, category, tt.category)
+			}
+		})
+	}
+}
+
+func TestRefusalChecker_Check(t *testing.T) {
+	checker, _ := NewRefusalChecker(RefusalOptions{Languages: []string{"en"}})
+
+	result := checker.Check(models.EvaluationContext{Answer: "Please contact support."})
+	if result.Category != CategoryDeflection || result.Score != 0.2 {
+		t.Errorf("Expected deflection with score 0.2, got %q %f", result.Category, result.Score)
+	}
+
+	result = checker.Check(models.EvaluationContext{Answer: "The default port is 8080."})
+	if result.Category != "" || result.Score != 1.0 {
+		t.Errorf("Expected no category with score 1.0, got %q %f", result.Category, result.Score)
+	}
+
+	// Spanish is not enabled
+	if category, _ := checker.Classify("No puedo ayudarte con eso."); category != "" {
+		t.Errorf("Expected no category for a disabled language, got %q", category)
+	}
+}
+
+func TestRefusalChecker_Params(t *testing.T) {
+	logger := zerolog.Nop()
+	cfg := &config.PrechecksConfig{Prechecks: []config.PrecheckConfiguration{{
+		Type:    "refusal",
+		Enabled: true,
+		Params: map[string]any{
+			"languages": []any{"en"},
+			"phrases":   map[string]any{"deflection": []any{"Open a Ticket"}},
+			"patterns":  map[string]any{"refusal": []any{`\bcannot (?:share|disclose)\b`}},
+			"scores":    map[string]any{"deflection": 0.5},
+		},
+	}}}
+
+	checkers, _, err := Build(cfg, Dependencies{Logger: &logger})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	result := checkers[0].Check(models.EvaluationContext{Answer: "Please open a ticket."})
+	if result.Category != CategoryDeflection || result.Score != 0.5 {
+		t.Errorf("Expected configured deflection with score 0.5, got %q %f", result.Category, result.Score)
+	}
+	result = checkers[0].Check(models.EvaluationContext{Answer: "We cannot disclose customer names."})
+	if result.Category != CategoryRefusal {
+		t.Errorf("Expected pattern refusal, got %q", result.Category)
+	}
+}
+
+func TestNewRefusalChecker_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    RefusalOptions
+		wantErr string
+	}{
+		{"unknown language", RefusalOptions{Languages: []string{"xx"}}, "unsupported language"},
+		{"unknown category", RefusalOptions{Phrases: map[string][]string{"rudeness": {"no"}}}, "unknown category"},
+		{"invalid pattern", RefusalOptions{Patterns: map[string][]string{"refusal": {"("}}}, "invalid refusal pattern"},
+		{"score out of range", RefusalOptions{Scores: map[string]float64{"hedging": 2}}, "must be in [0, 1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRefusalChecker(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %q error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package prechecks
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/registry"
+	"github.com/rs/zerolog"
+)
+
+// Params are the checker specific parameters of a precheck configuration
+type Params = config.Params
+
+// Dependencies are the shared clients available to checker factories
+type Dependencies = registry.Dependencies
+
+// Factory creates a checker from its configured parameters
+type Factory func(params Params, deps Dependencies) (Checker, error)
+
+// Registry maps checker type names to factories
+type Registry struct {
+	*registry.Registry[Factory]
+}
+
+func NewRegistry() *Registry {
+	return &Registry{registry.New[Factory]()}
+}
+
+// Build creates the enabled checkers of the configuration, in order, and returns
+// their weights keyed by checker name
+func (r *Registry) Build(cfg *config.PrechecksConfig, deps Dependencies) ([]Checker, map[string]float64, error) {
+	var checkers []Checker
+	weights := make(map[string]float64)
+
+	for _, precheck := range cfg.Enabled() {
+		factory, ok := r.Lookup(precheck.Type)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown precheck type: %s", precheck.Type)
+		}
+
+		checker, err := factory(Params(precheck.Params), deps)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create precheck %s: %w", precheck.Type, err)
+		}
+
+		checkers = append(checkers, checker)
+		weights[checker.Name()] = precheck.Weight
+	}
+
+	return checkers, weights, nil
+}
+
+// defaultRegistry holds the built-in checkers and those added with Register
+var defaultRegistry = newBuiltinRegistry()
+
+// Register adds a checker type to the default registry, typically from an init function
+func Register(typeName string, factory Factory) {
+	defaultRegistry.Register(typeName, factory)
+}
+
+// Build creates the checkers of the configuration from the default registry
+func Build(cfg *config.PrechecksConfig, deps Dependencies) ([]Checker, map[string]float64, error) {
+	return defaultRegistry.Build(cfg, deps)
+}
+
+// Types returns the checker types of the default registry
+func Types() []string {
+	return defaultRegistry.Types()
+}
+
+func newBuiltinRegistry() *Registry {
+	r := NewRegistry()
+
+	r.Register("length", func(params Params, deps Dependencies) (Checker, error) {
+		if err := params.Only("min_ratio", "max_ratio"); err != nil {
+			return nil, err
+		}
+		minRatio, err := params.Float("min_ratio", DefaultMinLengthRatio)
+		if err != nil {
+			return nil, err
+		}
+		maxRatio, err := params.Float("max_ratio", DefaultMaxLengthRatio)
+		if err != nil {
+			return nil, err
+		}
+		if minRatio <= 0 || maxRatio <= minRatio {
+			return nil, fmt.Errorf("invalid length ratios: min %f, max %f", minRatio, maxRatio)
+		}
+		return &LengthChecker{MinRatio: minRatio, MaxRatio: maxRatio}, nil
+	})
+
+	r.Register("overlap", func(params Params, deps Dependencies) (Checker, error) {
+		if err := params.Only("min_overlap"); err != nil {
+			return nil, err
+		}
+		threshold, err := thresholdParam(params, "min_overlap", DefaultMinOverlapThreshold)
+		if err != nil {
+			return nil, err
+		}
+		return &OverlapChecker{MinOverlapThreshold: threshold}, nil
+	})
+
+	r.Register("format", func(params Params, deps Dependencies) (Checker, error) {
+		if err := params.Only(); err != nil {
+			return nil, err
+		}
+		return NewFormatChecker(), nil
+	})
+
+	r.Register("citation", func(params Params, deps Dependencies) (Checker, error) {
+		if err := params.Only("min_support"); err != nil {
+			return nil, err
+		}
+		minSupport, err := thresholdParam(params, "min_support", DefaultMinCitationSupport)
+		if err != nil {
+			return nil, err
+		}
+		return NewCitationChecker(minSupport), nil
+	})
+
+	r.Register("pii-leakage", newPIICheckerFromParams)
+	r.Register("refusal", newRefusalCheckerFromParams)
+
+	r.Register("semantic-relevance", semanticFactory(DefaultRelevanceThreshold, func(embedder embedding.Client, threshold float64, logger *zerolog.Logger) Checker {
+		return NewSemanticRelevanceChecker(embedder, threshold, logger)
+	}))
+	r.Register("semantic-grounding", semanticFactory(DefaultGroundingThreshold, func(embedder embedding.Client, threshold float64, logger *zerolog.Logger) Checker {
+		return NewGroundingChecker(embedder, threshold, logger)
+	}))
+	r.Register("reference-similarity", semanticFactory(DefaultReferenceThreshold, func(embedder embedding.Client, threshold float64, logger *zerolog.Logger) Checker {
+		return NewReferenceSimilarityChecker(embedder, threshold, logger)
+	}))
+
+	return r
+}
+
+// semanticFactory returns the factory of an embedding checker with a threshold param
+func semanticFactory(def float64, create func(embedding.Client, float64, *zerolog.Logger) Checker) Factory {
+	return func(params Params, deps Dependencies) (Checker, error) {
+		if err := params.Only("threshold"); err != nil {
+			return nil, err
+		}
+		if deps.Embedder == nil {
+			return nil, fmt.Errorf("requires an embedding provider (EMBEDDING_PROVIDER)")
+		}
+		threshold, err := thresholdParam(params, "threshold", def)
+		if err != nil {
+			return nil, err
+		}
+		return create(deps.Embedder, threshold, deps.Logger), nil
+	}
+}
+
+// thresholdParam reads a parameter that must lie in (0, 1]
+func thresholdParam(params Params, key string, def float64) (float64, error) {
+	value, err := params.Float(key, def)
+	if err != nil {
+		return 0, err
+	}
+	if value <= 0 || value > 1 {
+		return 0, fmt.Errorf("param %s must be in (0, 1], got %f", key, value)
+	}
+	return value, nil
+}
+
+// newPIICheckerFromParams creates a PIIChecker from the packs, allowlist,
+// allow_patterns, rules ([{type, pattern}]) and veto params
+func newPIICheckerFromParams(params Params, deps Dependencies) (Checker, error) {
+	if err := params.Only("packs", "allowlist", "allow_patterns", "rules", "veto"); err != nil {
+		return nil, err
+	}
+
+	var opts PIIOptions
+	var err error
+	if opts.Packs, err = params.Strings("packs"); err != nil {
+		return nil, err
+	}
+	if opts.Allowlist, err = params.Strings("allowlist"); err != nil {
+		return nil, err
+	}
+	if opts.AllowPatterns, err = params.Strings("allow_patterns"); err != nil {
+		return nil, err
+	}
+	if opts.Veto, err = params.Bool("veto", false); err != nil {
+		return nil, err
+	}
+
+	if value, ok := params["rules"]; ok {
+		items, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("param rules must be a list, got %T", value)
+		}
+		for i, item := range items {
+			rule, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("param rules[%d] must be a map with type and pattern", i)
+			}
+			ruleType, _ := rule["type"].(string)
+			pattern, _ := rule["pattern"].(string)
+			if ruleType == "" || pattern == "" {
+				return nil, fmt.Errorf("param rules[%d] requires type and pattern", i)
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("param rules[%d] has an invalid pattern: %w", i, err)
+			}
+			opts.Rules = append(opts.Rules, PIIRule{Type: ruleType, Pattern: re})
+		}
+	}
+
+	return NewPIIChecker(opts)
+}
+
+// newRefusalCheckerFromParams creates a RefusalChecker from the languages,
+// phrases and patterns (category to list), scores (category to score) and
+// max_words params
+func newRefusalCheckerFromParams(params Params, deps Dependencies) (Checker, error) {
+	if err := params.Only("languages", "phrases", "patterns", "scores", "max_words"); err != nil {
+		return nil, err
+	}
+
+	var opts RefusalOptions
+	var err error
+	if opts.Languages, err = params.Strings("languages"); err != nil {
+		return nil, err
+	}
+	if opts.Phrases, err = categoryStrings(params, "phrases"); err != nil {
+		return nil, err
+	}
+	if opts.Patterns, err = categoryStrings(params, "patterns"); err != nil {
+		return nil, err
+	}
+	maxWords, err := params.Float("max_words", DefaultRefusalMaxWords)
+	if err != nil {
+		return nil, err
+	}
+	opts.MaxWords = int(maxWords)
+
+	if value, ok := params["scores"]; ok {
+		scores, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("param scores must be a map of category to score, got %T", value)
+		}
+		opts.Scores = make(map[string]float64)
+		for category := range scores {
+			if opts.Scores[category], err = Params(scores).Float(category, 0); err != nil {
+				return nil, fmt.Errorf("param scores: %w", err)
+			}
+		}
+	}
+
+	return NewRefusalChecker(opts)
+}
+
+// categoryStrings reads a map of category to list of strings parameter
+func categoryStrings(p Params, key string) (map[string][]string, error) {
+	value, ok := p[key]
+	if !ok {
+		return nil, nil
+	}
+
+	categories, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("param %s must be a map of category to list, got %T", key, value)
+	}
+
+	values := make(map[string][]string)
+	for category := range categories {
+		items, err := Params(categories).Strings(category)
+		if err != nil {
+			return nil, fmt.Errorf("param %s: %w", key, err)
+		}
+		values[category] = items
+	}
+	return values, nil
+}
@@ -0,0 +1,97 @@
+package prechecks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// constantChecker scores every evaluation the same
+type constantChecker struct {
+	score float64
+}
+
+func (c *constantChecker) Name() string {
+	return "constant"
+}
+
+func (c *constantChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
+	return models.StageResult{Name: c.Name(), Score: c.score}
+}
+
+func TestRegistry_Build(t *testing.T) {
+	logger := zerolog.Nop()
+
+	registry := newBuiltinRegistry()
+	registry.Register("constant", func(params Params, deps Dependencies) (Checker, error) {
+		score, err := params.Float("score", 1.0)
+		if err != nil {
+			return nil, err
+		}
+		return &constantChecker{score: score}, nil
+	})
+
+	cfg := &config.PrechecksConfig{Prechecks: []config.PrecheckConfiguration{
+		{Type: "length", Enabled: true, Weight: 1, Params: map[string]any{"min_ratio": 1, "max_ratio": 4.5}},
+		{Type: "format", Enabled: false, Weight: 1},
+		{Type: "constant", Enabled: true, Weight: 2, Params: map[string]any{"score": 0.25}},
+	}}
+
+	checkers, weights, err := registry.Build(cfg, Dependencies{Logger: &logger})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if len(checkers) != 2 || checkers[0].Name() != "length-checker" || checkers[1].Name() != "constant" {
+		t.Fatalf("Expected length and constant checkers, got %v", checkers)
+	}
+	length := checkers[0].(*LengthChecker)
+	if length.MinRatio != 1 || length.MaxRatio != 4.5 {
+		t.Errorf("Expected configured ratios, got %+v", length)
+	}
+	if checkers[1].Check(models.EvaluationContext{}).Score != 0.25 {
+		t.Error("Expected constant checker built with its params")
+	}
+	if weights["length-checker"] != 1 || weights["constant"] != 2 {
+		t.Errorf("Expected weights by checker name, got %v", weights)
+	}
+}
+
+func TestRegistry_BuildErrors(t *testing.T) {
+	logger := zerolog.Nop()
+
+	tests := []struct {
+		name     string
+		precheck config.PrecheckConfiguration
+		wantErr  string
+	}{
+		{"unknown type", config.PrecheckConfiguration{Type: "magic"}, "unknown precheck type: magic"},
+		{"unknown param", config.PrecheckConfiguration{Type: "format", Params: map[string]any{"strict": true}}, "unknown param strict"},
+		{"non-numeric param", config.PrecheckConfiguration{Type: "overlap", Params: map[string]any{"min_overlap": "high"}}, "must be a number"},
+		{"threshold out of range", config.PrecheckConfiguration{Type: "overlap", Params: map[string]any{"min_overlap": 1.5}}, "must be in (0, 1]"},
+		{"invalid ratios", config.PrecheckConfiguration{Type: "length", Params: map[string]any{"min_ratio": 2, "max_ratio": 1}}, "invalid length ratios"},
+		{"no embedder", config.PrecheckConfiguration{Type: "semantic-grounding"}, "requires an embedding provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.precheck.Enabled = true
+			cfg := &config.PrechecksConfig{Prechecks: []config.PrecheckConfiguration{tt.precheck}}
+
+			_, _, err := Build(cfg, Dependencies{Logger: &logger})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %q error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTypes(t *testing.T) {
+	want := "citation,format,length,overlap,pii-leakage,reference-similarity,refusal,semantic-grounding,semantic-relevance"
+	if got := strings.Join(Types(), ","); got != want {
+		t.Errorf("Types() = %s, want %s", got, want)
+	}
+}
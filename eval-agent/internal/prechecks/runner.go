@@ -16,6 +16,15 @@ func NewStageRunner(checkers []Checker) *StageRunner {
 	}
 }
 
+// Names returns the names of the configured checkers
+func (r *StageRunner) Names() []string {
+	names := make([]string, len(r.Checkers))
+	for i, checker := range r.Checkers {
+		names[i] = checker.Name()
+	}
+	return names
+}
+
 func (r *StageRunner) Run(evaluationContext models.EvaluationContext) []models.StageResult {
 	results := make(chan models.StageResult, len(r.Checkers))
 	var wg sync.WaitGroup
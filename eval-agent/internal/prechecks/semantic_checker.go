@@ -0,0 +1,224 @@
+package prechecks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// embeddingTimeout bounds the embedding calls of one check
+const embeddingTimeout = 10 * time.Second
+
+// Default similarity thresholds of the semantic checkers
+const (
+	DefaultRelevanceThreshold = 0.4
+	DefaultGroundingThreshold = 0.5
+	DefaultReferenceThreshold = 0.6
+)
+
+// SemanticRelevanceChecker scores an answer by the cosine similarity of its
+// embedding to the query embedding. Unlike OverlapChecker it does not penalize
+// paraphrased answers.
+type SemanticRelevanceChecker struct {
+	embedder  embedding.Client
+	threshold float64
+	logger    *zerolog.Logger
+}
+
+func NewSemanticRelevanceChecker(embedder embedding.Client, threshold float64, logger *zerolog.Logger) *SemanticRelevanceChecker {
+	if threshold <= 0 {
+		threshold = DefaultRelevanceThreshold
+	}
+	return &SemanticRelevanceChecker{embedder: embedder, threshold: threshold, logger: logger}
+}
+
+func (c *SemanticRelevanceChecker) Name() string {
+	return "semantic-relevance"
+}
+
+func (c *SemanticRelevanceChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
+	result := models.StageResult{Name: c.Name()}
+	now := time.Now()
+
+	if strings.TrimSpace(evaluationContext.Query) == "" {
+		result.Reason = "Empty Query"
+		result.Duration = time.Since(now)
+		return result
+	}
+	if strings.TrimSpace(evaluationContext.Answer) == "" {
+		result.Reason = "Empty Answer"
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	vectors, err := embed(c.embedder, []string{evaluationContext.Query, evaluationContext.Answer})
+	if err != nil {
+		c.logger.Error().Err(err).Str("checker", c.Name()).Msg("embedding failed")
+		result.Reason = "Failed to compute embeddings"
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	similarity := embedding.CosineSimilarity(vectors[0], vectors[1])
+	result.Score = clampScore(similarity)
+	if similarity < c.threshold {
+		result.Reason = fmt.Sprintf("Low semantic relevance: similarity %.2f below %.2f", similarity, c.threshold)
+	} else {
+		result.Reason = fmt.Sprintf("Answer is semantically relevant to the query (similarity %.2f)", similarity)
+	}
+
+	result.Duration = time.Since(now)
+	return result
+}
+
+// GroundingChecker scores how well each answer sentence is supported by the
+// context: a sentence's support is its highest similarity to any context chunk,
+// and the score is the mean support over all sentences.
+type GroundingChecker struct {
+	embedder  embedding.Client
+	threshold float64
+	logger    *zerolog.Logger
+}
+
+func NewGroundingChecker(embedder embedding.Client, threshold float64, logger *zerolog.Logger) *GroundingChecker {
+	if threshold <= 0 {
+		threshold = DefaultGroundingThreshold
+	}
+	return &GroundingChecker{embedder: embedder, threshold: threshold, logger: logger}
+}
+
+func (c *GroundingChecker) Name() string {
+	return "semantic-grounding"
+}
+
+func (c *GroundingChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
+	result := models.StageResult{Name: c.Name()}
+	now := time.Now()
+
+	var chunks []string
+	for _, chunk := range evaluationContext.Chunks() {
+		chunks = append(chunks, chunk.Content)
+	}
+	if len(chunks) == 0 {
+		result.Reason = "Context required but not provided"
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	sentences := models.SplitSentences(evaluationContext.Answer)
+	if len(sentences) == 0 {
+		result.Reason = "Empty Answer"
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	vectors, err := embed(c.embedder, append(append([]string{}, sentences...), chunks...))
+	if err != nil {
+		c.logger.Error().Err(err).Str("checker", c.Name()).Msg("embedding failed")
+		result.Reason = "Failed to compute embeddings"
+		result.Duration = time.Since(now)
+		return result
+	}
+	sentenceVectors, chunkVectors := vectors[:len(sentences)], vectors[len(sentences):]
+
+	total, ungrounded := 0.0, 0
+	for _, sentenceVector := range sentenceVectors {
+		support := 0.0
+		for _, chunkVector := range chunkVectors {
+			support = max(support, embedding.CosineSimilarity(sentenceVector, chunkVector))
+		}
+		if support < c.threshold {
+			ungrounded++
+		}
+		total += support
+	}
+
+	result.Score = clampScore(total / float64(len(sentences)))
+	if ungrounded > 0 {
+		result.Reason = fmt.Sprintf("%d of %d answer sentences are not grounded in the context (similarity below %.2f)", ungrounded, len(sentences), c.threshold)
+	} else {
+		result.Reason = fmt.Sprintf("All %d answer sentences are grounded in the context", len(sentences))
+	}
+
+	result.Duration = time.Since(now)
+	return result
+}
+
+// ReferenceSimilarityChecker scores an answer by the cosine similarity of its
+// embedding to the reference answer embedding
+type ReferenceSimilarityChecker struct {
+	embedder  embedding.Client
+	threshold float64
+	logger    *zerolog.Logger
+}
+
+func NewReferenceSimilarityChecker(embedder embedding.Client, threshold float64, logger *zerolog.Logger) *ReferenceSimilarityChecker {
+	if threshold <= 0 {
+		threshold = DefaultReferenceThreshold
+	}
+	return &ReferenceSimilarityChecker{embedder: embedder, threshold: threshold, logger: logger}
+}
+
+func (c *ReferenceSimilarityChecker) Name() string {
+	return "reference-similarity"
+}
+
+func (c *ReferenceSimilarityChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
+	result := models.StageResult{Name: c.Name()}
+	now := time.Now()
+
+	if strings.TrimSpace(evaluationContext.Reference) == "" {
+		result.Reason = "Reference answer required but not provided"
+		result.Duration = time.Since(now)
+		return result
+	}
+	if strings.TrimSpace(evaluationContext.Answer) == "" {
+		result.Reason = "Empty Answer"
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	vectors, err := embed(c.embedder, []string{evaluationContext.Reference, evaluationContext.Answer})
+	if err != nil {
+		c.logger.Error().Err(err).Str("checker", c.Name()).Msg("embedding failed")
+		result.Reason = "Failed to compute embeddings"
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	similarity := embedding.CosineSimilarity(vectors[0], vectors[1])
+	result.Score = clampScore(similarity)
+	if similarity < c.threshold {
+		result.Reason = fmt.Sprintf("Answer differs from the reference: similarity %.2f below %.2f", similarity, c.threshold)
+	} else {
+		result.Reason = fmt.Sprintf("Answer matches the reference (similarity %.2f)", similarity)
+	}
+
+	result.Duration = time.Since(now)
+	return result
+}
+
+// embed computes the embeddings of the texts within embeddingTimeout
+func embed(embedder embedding.Client, texts []string) ([][]float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), embeddingTimeout)
+	defer cancel()
+
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(vectors))
+	}
+	return vectors, nil
+}
+
+// clampScore maps a cosine similarity to a score in [0, 1]
+func clampScore(similarity float64) float64 {
+	return min(max(similarity, 0), 1)
+}
@@ -0,0 +1,130 @@
+package prechecks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// stubEmbedder embeds texts as fixed vectors keyed by text
+type stubEmbedder struct {
+	vectors map[string][]float64
+	err     error
+}
+
+func (s *stubEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = s.vectors[text]
+	}
+	return vectors, nil
+}
+
+func TestSemanticRelevanceChecker(t *testing.T) {
+	logger := zerolog.Nop()
+	embedder := &stubEmbedder{vectors: map[string][]float64{
+		"What is Go?":                      {1, 0},
+		"Go is a language made at Google.": {0.8, 0.6},
+		"Bananas are rich in potassium.":   {0, 1},
+	}}
+	checker := NewSemanticRelevanceChecker(embedder, 0, &logger)
+
+	tests := []struct {
+		name   string
+		query  string
+		answer string
+		score  float64
+		reason string
+	}{
+		{"relevant", "What is Go?", "Go is a language made at Google.", 0.8, "semantically relevant"},
+		{"unrelated", "What is Go?", "Bananas are rich in potassium.", 0.0, "Low semantic relevance"},
+		{"empty query", "", "anything", 0.0, "Empty Query"},
+		{"empty answer", "What is Go?", " ", 0.0, "Empty Answer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checker.Check(models.EvaluationContext{Query: tt.query, Answer: tt.answer})
+			if diff := result.Score - tt.score; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("Score: %f, want %f", result.Score, tt.score)
+			}
+			if !strings.Contains(result.Reason, tt.reason) {
+				t.Errorf("Reason: %q, want substring %q", result.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestSemanticRelevanceChecker_EmbeddingError(t *testing.T) {
+	logger := zerolog.Nop()
+	checker := NewSemanticRelevanceChecker(&stubEmbedder{err: errors.New("throttled")}, 0, &logger)
+
+	result := checker.Check(models.EvaluationContext{Query: "q", Answer: "a"})
+	if result.Score != 0 || result.Reason != "Failed to compute embeddings" {
+		t.Errorf("Expected embedding failure, got %f %q", result.Score, result.Reason)
+	}
+}
+
+func TestGroundingChecker(t *testing.T) {
+	logger := zerolog.Nop()
+	embedder := &stubEmbedder{vectors: map[string][]float64{
+		"Go was created at Google.":  {1, 0, 0},
+		"It has goroutines.":         {0, 1, 0},
+		"It was released in 1995.":   {0, 0, 1},
+		"Go was designed at Google.": {1, 0, 0},
+		"Go supports goroutines.":    {0, 1, 0},
+	}}
+	checker := NewGroundingChecker(embedder, 0, &logger)
+	context := "Go was designed at Google.\n\nGo supports goroutines."
+
+	result := checker.Check(models.EvaluationContext{
+		Context: context,
+		Answer:  "Go was created at Google. It has goroutines.",
+	})
+	if result.Score != 1.0 || result.Reason != "All 2 answer sentences are grounded in the context" {
+		t.Errorf("Expected fully grounded answer, got %f %q", result.Score, result.Reason)
+	}
+
+	// Each sentence is scored against its closest chunk
+	result = checker.Check(models.EvaluationContext{
+		Context: context,
+		Answer:  "Go was created at Google. It has goroutines. It was released in 1995.",
+	})
+	if diff := result.Score - 2.0/3.0; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected score 2/3, got %f", result.Score)
+	}
+	if !strings.Contains(result.Reason, "1 of 3 answer sentences are not grounded") {
+		t.Errorf("Expected ungrounded sentence count, got %q", result.Reason)
+	}
+
+	result = checker.Check(models.EvaluationContext{Answer: "Go was created at Google."})
+	if result.Score != 0 || result.Reason != "Context required but not provided" {
+		t.Errorf("Expected missing context, got %f %q", result.Score, result.Reason)
+	}
+}
+
+func TestReferenceSimilarityChecker(t *testing.T) {
+	logger := zerolog.Nop()
+	checker := NewReferenceSimilarityChecker(embedding.NewLocalClient(0), 0, &logger)
+
+	result := checker.Check(models.EvaluationContext{
+		Answer:    "Paris is the capital of France.",
+		Reference: "The capital of France is Paris.",
+	})
+	if result.Score < DefaultReferenceThreshold || !strings.Contains(result.Reason, "matches the reference") {
+		t.Errorf("Expected paraphrase to match the reference, got %f %q", result.Score, result.Reason)
+	}
+
+	result = checker.Check(models.EvaluationContext{Answer: "Paris"})
+	if result.Score != 0 || result.Reason != "Reference answer required but not provided" {
+		t.Errorf("Expected missing reference, got %f %q", result.Score, result.Reason)
+	}
+}
@@ -0,0 +1,56 @@
+// Package registry maps configurable type names to the factories that build
+// them, for the prechecks and code judges configured from YAML.
+package registry
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
+	"github.com/rs/zerolog"
+)
+
+// Dependencies are the shared clients available to factories
+type Dependencies struct {
+	Embedder embedding.Client // nil without an embedding provider
+	Logger   *zerolog.Logger
+}
+
+// Registry maps type names to factories of type F. It is safe for concurrent use.
+type Registry[F any] struct {
+	mu        sync.RWMutex
+	factories map[string]F
+}
+
+func New[F any]() *Registry[F] {
+	return &Registry[F]{factories: make(map[string]F)}
+}
+
+// Register makes a type available to the configuration. A second registration
+// of the same type replaces the first.
+func (r *Registry[F]) Register(typeName string, factory F) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[typeName] = factory
+}
+
+// Lookup returns the factory of a type
+func (r *Registry[F]) Lookup(typeName string) (F, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	factory, ok := r.factories[typeName]
+	return factory, ok
+}
+
+// Types returns the registered type names, sorted
+func (r *Registry[F]) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.factories))
+	for typeName := range r.factories {
+		types = append(types, typeName)
+	}
+	sort.Strings(types)
+	return types
+}
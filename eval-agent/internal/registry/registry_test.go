@@ -0,0 +1,24 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := New[func() string]()
+	r.Register("b", func() string { return "b" })
+	r.Register("a", func() string { return "a" })
+	r.Register("b", func() string { return "b2" })
+
+	if types := r.Types(); !reflect.DeepEqual(types, []string{"a", "b"}) {
+		t.Errorf("Expected sorted types [a b], got %v", types)
+	}
+	factory, ok := r.Lookup("b")
+	if !ok || factory() != "b2" {
+		t.Error("Expected the second registration to replace the first")
+	}
+	if _, ok := r.Lookup("c"); ok {
+		t.Error("Expected an unknown type not to be found")
+	}
+}
@@ -0,0 +1,166 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+	"github.com/rs/zerolog"
+)
+
+// JudgesReloader rebuilds the judges from the judges configuration (JUDGES_CONFIG_PATH)
+// and swaps them into the running pipeline. A configuration that fails to load,
+// validate or build is rejected and the active one keeps serving.
+type JudgesReloader struct {
+	judges *judge.ReloadableJudges
+	pool   *judge.JudgePool
+	logger *zerolog.Logger
+
+	mu        sync.Mutex
+	lastError string
+}
+
+// NewJudgesReloader loads the initial judges configuration
+func NewJudgesReloader(pool *judge.JudgePool, logger *zerolog.Logger) (*JudgesReloader, error) {
+	set, err := buildJudgeSet(pool, 1, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info().
+		Str("config_hash", set.Version.Hash).
+		Msg("judges configuration loaded")
+
+	return &JudgesReloader{
+		judges: judge.NewReloadableJudges(set),
+		pool:   pool,
+		logger: logger,
+	}, nil
+}
+
+// Judges returns the reloadable judges, to be used as judge runner and factory
+func (r *JudgesReloader) Judges() *judge.ReloadableJudges {
+	return r.judges
+}
+
+// Active returns the version of the configuration currently serving evaluations
+func (r *JudgesReloader) Active() judge.ConfigVersion {
+	return r.judges.Active().Version
+}
+
+// Reload loads the configuration again and activates it if it changed. On error
+// the active configuration is kept and returned together with the error.
+func (r *JudgesReloader) Reload() (judge.ConfigVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	active := r.judges.Active()
+
+	cfg, err := config.LoadJudgesConfig()
+	if err != nil {
+		return active.Version, r.reject(fmt.Errorf("failed to load judges config: %w", err))
+	}
+
+	hash, err := cfg.Hash()
+	if err != nil {
+		return active.Version, r.reject(err)
+	}
+	r.lastError = ""
+
+	if hash == active.Version.Hash {
+		return active.Version, nil
+	}
+
+	judges, err := r.pool.BuildFromConfig(cfg)
+	if err != nil {
+		return active.Version, r.reject(fmt.Errorf("failed to build judges from config: %w", err))
+	}
+
+	set, err := judge.NewJudgeSet(judges, cfg, active.Version.Version+1, r.logger)
+	if err != nil {
+		return active.Version, r.reject(err)
+	}
+
+	r.judges.Swap(set)
+
+	r.logger.Info().
+		Int("version", set.Version.Version).
+		Str("previous_hash", active.Version.Hash).
+		Str("config_hash", set.Version.Hash).
+		Msg("judges configuration reloaded")
+
+	return set.Version, nil
+}
+
+// reject logs a failed reload, once per distinct error so that polling a broken
+// configuration does not flood the logs
+func (r *JudgesReloader) reject(err error) error {
+	if err.Error() != r.lastError {
+		r.logger.Error().
+			Err(err).
+			Str("config_hash", r.judges.Active().Version.Hash).
+			Msg("judges configuration rejected, keeping active configuration")
+	}
+	r.lastError = err.Error()
+	return err
+}
+
+// WatchSignals reloads the configuration on SIGHUP until the context is done
+func (r *JudgesReloader) WatchSignals(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				r.logger.Info().Msg("SIGHUP received, reloading judges configuration")
+				_, _ = r.Reload()
+			}
+		}
+	}()
+}
+
+// WatchFiles polls the configuration, prompt, partial and example files every
+// interval and reloads when their content changes. A zero interval disables polling.
+func (r *JudgesReloader) WatchFiles(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, _ = r.Reload()
+			}
+		}
+	}()
+}
+
+func buildJudgeSet(pool *judge.JudgePool, version int, logger *zerolog.Logger) (*judge.JudgeSet, error) {
+	cfg, err := config.LoadJudgesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load judges config: %w", err)
+	}
+
+	judges, err := pool.BuildFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build judges from config: %w", err)
+	}
+
+	return judge.NewJudgeSet(judges, cfg, version, logger)
+}
@@ -0,0 +1,82 @@
+package setup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/rs/zerolog"
+)
+
+type stubLLMClient struct{}
+
+func (c *stubLLMClient) InvokeModel(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	return &llm.LLMResponse{Content: `{"score": 1.0, "reason": "ok"}`}, nil
+}
+
+func (c *stubLLMClient) InvokeModelWithRetry(ctx context.Context, request llm.LLMRequest) (*llm.LLMResponse, error) {
+	return c.InvokeModel(ctx, request)
+}
+
+func writeJudgesConfig(t *testing.T, path string, prompt string) {
+	t.Helper()
+	content := "judges:\n" +
+		"  default_model:\n" +
+		"    max_tokens: 256\n" +
+		"  evaluators:\n" +
+		"    - name: relevance\n" +
+		"      enabled: true\n" +
+		"      prompt: \"" + prompt + "\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+func TestJudgesReloader_Reload(t *testing.T) {
+	logger := zerolog.Nop()
+	configPath := filepath.Join(t.TempDir(), "judges.yaml")
+	t.Setenv("JUDGES_CONFIG_PATH", configPath)
+
+	writeJudgesConfig(t, configPath, "v1 {{.Answer}}")
+
+	reloader, err := NewJudgesReloader(judge.NewJudgePool(&stubLLMClient{}, &logger), &logger)
+	if err != nil {
+		t.Fatalf("NewJudgesReloader failed: %v", err)
+	}
+	initial := reloader.Active()
+	if initial.Version != 1 || initial.Hash == "" {
+		t.Fatalf("Expected version 1 with a hash, got %+v", initial)
+	}
+
+	// Unchanged configuration keeps the active version
+	version, err := reloader.Reload()
+	if err != nil || version != initial {
+		t.Errorf("Expected unchanged version %+v, got %+v (err %v)", initial, version, err)
+	}
+
+	// Invalid configuration is rejected and the active one keeps serving
+	writeJudgesConfig(t, configPath, "v2 {{.Answer")
+	version, err = reloader.Reload()
+	if err == nil {
+		t.Fatal("Expected invalid template to be rejected")
+	}
+	if version != initial || reloader.Active() != initial {
+		t.Errorf("Expected active version to stay %+v, got %+v", initial, reloader.Active())
+	}
+
+	// Valid change is activated
+	writeJudgesConfig(t, configPath, "v2 {{.Answer}}")
+	version, err = reloader.Reload()
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if version.Version != 2 || version.Hash == initial.Hash {
+		t.Errorf("Expected version 2 with a new hash, got %+v", version)
+	}
+	if reloader.Judges().ConfigHash() != version.Hash {
+		t.Error("Expected judges to serve the reloaded configuration")
+	}
+}
@@ -2,101 +2,304 @@ package setup
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/aggregator"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm/bedrock"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm/gpt"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/prechecks"
 	"github.com/rs/zerolog"
 )
 
 type Config struct {
-	AWSRegion          string
-	ClaudeModelID      string
-	OpenAIKey          string
-	OpenAIModelID      string
-	DefaultProvider    string
-	PrecheckWeight     float64
-	LLMJudgeWeight     float64
-	EarlyExitThreshold float64
+	AWSRegion           string
+	ClaudeModelID       string
+	OpenAIKey           string
+	OpenAIModelID       string
+	LocalBaseURL        string // OpenAI-compatible endpoint, e.g. http://localhost:11434/v1
+	LocalAPIKey         string
+	LocalModelID        string
+	LocalJSONMode       bool
+	CassettePath        string // Record/replay LLM responses to this file (empty = disabled)
+	CassetteMode        llm.CassetteMode
+	PricingPath         string                    // Token price table (empty or missing file = costs not reported)
+	RateLimits          map[string]llm.RateLimits // Per provider, applied to each of its models
+	CircuitBreaker      llm.CircuitBreakerSettings
+	EmbeddingProvider   string // bedrock, openai or local (empty = no embedding client)
+	EmbeddingModelID    string // Empty = provider default
+	EmbeddingDimensions int    // 0 = model default
+	PrechecksPath       string // Precheck stage configuration (empty or missing file = default prechecks)
+	DefaultProvider     string
+	PrecheckWeight      float64
+	LLMJudgeWeight      float64
+	EarlyExitThreshold  float64
+	ConfigWatchInterval time.Duration // Poll interval for judges config changes (0 = SIGHUP/admin reload only)
 }
 
 type Dependencies struct {
 	Executor      *executor.Executor
 	JudgeExecutor *executor.JudgeExecutor
+	Reloader      *JudgesReloader
+	JudgePool     *judge.JudgePool
+	LLMClient     llm.LLMClient        // Default provider and model
+	JudgesConfig  *config.JudgesConfig // Configuration loaded at startup
+	Prechecks     *config.PrechecksConfig
+	Pricing       *config.PricingConfig // Nil when no price table is loaded
 	Logger        *zerolog.Logger
 }
 
 func LoadConfig() *Config {
 	return &Config{
-		AWSRegion:          getEnv("AWS_REGION", "us-east-1"),
-		ClaudeModelID:      getEnv("CLAUDE_MODEL_ID", ""),
-		OpenAIKey:          getEnv("OPEN_AI_KEY", ""),
-		OpenAIModelID:      getEnv("OPEN_AI_MODEL_ID", ""),
-		DefaultProvider:    getEnv("DEFAULT_LLM_PROVIDER", "bedrock"),
-		PrecheckWeight:     getEnvFloat("PRECHECK_WEIGHT", 0.3),
-		LLMJudgeWeight:     getEnvFloat("LLM_JUDGE_WEIGHT", 0.7),
-		EarlyExitThreshold: getEnvFloat("EARLY_EXIT_THRESHOLD", 0.2),
+		AWSRegion:           getEnv("AWS_REGION", "us-east-1"),
+		ClaudeModelID:       getEnv("CLAUDE_MODEL_ID", ""),
+		OpenAIKey:           getEnv("OPEN_AI_KEY", ""),
+		OpenAIModelID:       getEnv("OPEN_AI_MODEL_ID", ""),
+		LocalBaseURL:        getEnv("LOCAL_LLM_BASE_URL", ""),
+		LocalAPIKey:         getEnv("LOCAL_LLM_API_KEY", ""),
+		LocalModelID:        getEnv("LOCAL_LLM_MODEL_ID", ""),
+		LocalJSONMode:       getEnv("LOCAL_LLM_JSON_MODE", "true") == "true",
+		CassettePath:        getEnv("LLM_CASSETTE", ""),
+		CassetteMode:        llm.CassetteMode(getEnv("LLM_CASSETTE_MODE", string(llm.CassetteCache))),
+		PricingPath:         getEnv("PRICING_CONFIG_PATH", "configs/pricing.yaml"),
+		EmbeddingProvider:   getEnv(config.EmbeddingProviderEnv, ""),
+		EmbeddingModelID:    getEnv("EMBEDDING_MODEL_ID", ""),
+		EmbeddingDimensions: int(getEnvFloat("EMBEDDING_DIMENSIONS", 0)),
+		PrechecksPath:       getEnv("PRECHECKS_CONFIG_PATH", "configs/prechecks.yaml"),
+		DefaultProvider:     getEnv("DEFAULT_LLM_PROVIDER", "bedrock"),
+		PrecheckWeight:      getEnvFloat("PRECHECK_WEIGHT", 0.3),
+		LLMJudgeWeight:      getEnvFloat("LLM_JUDGE_WEIGHT", 0.7),
+		EarlyExitThreshold:  getEnvFloat("EARLY_EXIT_THRESHOLD", 0.2),
+		ConfigWatchInterval: getEnvDuration("JUDGES_CONFIG_WATCH_INTERVAL", 0),
+		RateLimits: map[string]llm.RateLimits{
+			"bedrock": rateLimitsFromEnv("BEDROCK"),
+			"openai":  rateLimitsFromEnv("OPENAI"),
+			"local":   rateLimitsFromEnv("LOCAL_LLM"),
+		},
+		CircuitBreaker: llm.CircuitBreakerSettings{
+			FailureThreshold: int(getEnvFloat("LLM_CIRCUIT_FAILURE_THRESHOLD", 5)),
+			OpenTimeout:      getEnvDuration("LLM_CIRCUIT_OPEN_TIMEOUT", 30*time.Second),
+		},
 	}
 }
 
-func Wire(ctx context.Context, cfg *Config, logger *zerolog.Logger) (*Dependencies, error) {
-	llmClient, err := createLLMClient(ctx, cfg.DefaultProvider, cfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Bedrock client: %w", err)
+// rateLimitsFromEnv reads <PREFIX>_REQUESTS_PER_SECOND and <PREFIX>_TOKENS_PER_MINUTE
+func rateLimitsFromEnv(prefix string) llm.RateLimits {
+	return llm.RateLimits{
+		RequestsPerSecond: getEnvFloat(prefix+"_REQUESTS_PER_SECOND", 0),
+		TokensPerMinute:   int(getEnvFloat(prefix+"_TOKENS_PER_MINUTE", 0)),
 	}
+}
 
-	// PreChecks
-	stageRunner := prechecks.NewStageRunner([]prechecks.Checker{
-		&prechecks.LengthChecker{},
-		&prechecks.OverlapChecker{MinOverlapThreshold: 0.3},
-		&prechecks.FormatChecker{},
-	})
+func Wire(ctx context.Context, cfg *Config, logger *zerolog.Logger) (*Dependencies, error) {
+	// Judges select their provider and model through the registry, the default
+	// client serves judges without a model override
+	var cassette *llm.Cassette
+	if cfg.CassettePath != "" {
+		if _, err := llm.ParseCassetteMode(string(cfg.CassetteMode)); err != nil {
+			return nil, err
+		}
+		var err error
+		cassette, err = llm.OpenCassette(cfg.CassettePath)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info().
+			Str("cassette", cfg.CassettePath).
+			Str("mode", string(cfg.CassetteMode)).
+			Int("entries", cassette.Len()).
+			Msg("LLM cassette enabled")
+	}
 
-	// Load judges configuration from YAML
-	judgesConfig, err := config.LoadJudgesConfig()
+	registry := newLLMRegistry(ctx, cfg, cassette, logger)
+	llmClient, err := registry.Default()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load judges config: %w", err)
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
 	}
 
-	// Create judge pool and build judges from config
-	judgePool := judge.NewJudgePool(llmClient, logger)
-	judges, err := judgePool.BuildFromConfig(judgesConfig)
+	pricing, err := loadPricing(cfg.PricingPath, logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build judges from config: %w", err)
+		return nil, err
 	}
 
-	// Create judge runner with config-driven judges
-	judgeRunner := judge.NewJudgeRunner(judges, logger)
+	embedder, err := newEmbeddingClient(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding client: %w", err)
+	}
 
-	// Judge factory for single judge execution (reuses same judges)
-	judgeFactory := judge.NewJudgeFactory(judges, logger)
+	prechecksConfig, err := loadPrechecks(cfg.PrechecksPath, logger)
+	if err != nil {
+		return nil, err
+	}
 
-	// Aggregator
-	agg := aggregator.NewAggregator(aggregator.Weights{
-		PreChecks: cfg.PrecheckWeight,
-		LLMJudge:  cfg.LLMJudgeWeight,
-	}, logger)
+	// Load judges configuration from YAML and build the judges. Both executors
+	// share the reloadable judges, so a reload applies to the full pipeline and
+	// to single judge execution at once.
+	judgePool := judge.NewJudgePool(llmClient, logger).
+		WithRegistry(registry).
+		WithPricing(pricing).
+		WithEmbeddingClient(embedder)
+	reloader, err := NewJudgesReloader(judgePool, logger)
+	if err != nil {
+		return nil, err
+	}
+	judges := reloader.Judges()
 
 	// Executors
-	agentExec := executor.NewExecutor(stageRunner, judgeRunner, agg, cfg.EarlyExitThreshold, logger)
-	judgeExec := executor.NewJudgeExecutor(judgeFactory, logger)
+	agentExec, err := newExecutor(cfg, judges, prechecksConfig, embedder, logger)
+	if err != nil {
+		return nil, err
+	}
+	judgeExec := executor.NewJudgeExecutor(judges, logger)
 
 	return &Dependencies{
 		Executor:      agentExec,
 		JudgeExecutor: judgeExec,
+		Reloader:      reloader,
+		JudgePool:     judgePool,
+		LLMClient:     llmClient,
+		JudgesConfig:  judges.Active().Config,
+		Prechecks:     prechecksConfig,
+		Pricing:       pricing,
 		Logger:        logger,
 	}, nil
 
 }
 
+// loadPricing loads the token price table. A missing file disables cost reporting
+// (token counts are still reported), any other error is returned.
+func loadPricing(path string, logger *zerolog.Logger) (*config.PricingConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	pricing, err := config.LoadPricing(path)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Warn().Str("file", path).Msg("pricing file not found, judge costs will not be reported")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info().
+		Str("file", path).
+		Int("models", len(pricing.Models)).
+		Msg("pricing loaded")
+	return pricing, nil
+}
+
+// loadPrechecks loads the precheck stage configuration. A missing file selects the
+// default prechecks, any other error is returned.
+func loadPrechecks(path string, logger *zerolog.Logger) (*config.PrechecksConfig, error) {
+	if path == "" {
+		return config.DefaultPrechecks(), nil
+	}
+
+	prechecksConfig, err := config.LoadPrechecks(path)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Warn().Str("file", path).Msg("prechecks file not found, using the default prechecks")
+		return config.DefaultPrechecks(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info().
+		Str("file", path).
+		Int("prechecks", len(prechecksConfig.Enabled())).
+		Msg("prechecks loaded")
+	return prechecksConfig, nil
+}
+
+// NewExecutor builds a full evaluation pipeline for the given judges configuration.
+// It is used to evaluate alternative judge configurations (e.g. prompt variants)
+// with the already wired judge pool.
+func NewExecutor(cfg *Config, judgePool *judge.JudgePool, judgesConfig *config.JudgesConfig, logger *zerolog.Logger) (*executor.Executor, error) {
+	prechecksConfig, err := loadPrechecks(cfg.PrechecksPath, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	judges, err := judgePool.BuildFromConfig(judgesConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build judges from config: %w", err)
+	}
+
+	set, err := judge.NewJudgeSet(judges, judgesConfig, 1, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return newExecutor(cfg, judge.NewReloadableJudges(set), prechecksConfig, judgePool.EmbeddingClient(), logger)
+}
+
+func newExecutor(cfg *Config, judgeRunner executor.JudgeRunner, prechecksConfig *config.PrechecksConfig, embedder embedding.Client, logger *zerolog.Logger) (*executor.Executor, error) {
+	// PreChecks
+	checkers, checkerWeights, err := prechecks.Build(prechecksConfig, prechecks.Dependencies{Embedder: embedder, Logger: logger})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build prechecks: %w", err)
+	}
+	stageRunner := prechecks.NewStageRunner(checkers)
+
+	categoryVerdicts := make(map[string]models.Verdict)
+	for category, verdict := range prechecksConfig.CategoryVerdicts {
+		categoryVerdicts[category] = models.Verdict(verdict)
+	}
+
+	// Aggregator
+	agg := aggregator.NewAggregator(aggregator.Weights{
+		PreChecks:      cfg.PrecheckWeight,
+		LLMJudge:       cfg.LLMJudgeWeight,
+		CheckerWeights: checkerWeights,
+	}, logger).WithCategoryVerdicts(categoryVerdicts)
+
+	return executor.NewExecutor(stageRunner, judgeRunner, agg, cfg.EarlyExitThreshold, logger).
+		WithPipeline(models.AggregationWeights{
+			PreChecks: cfg.PrecheckWeight,
+			LLMJudge:  cfg.LLMJudgeWeight,
+		}, stageRunner.Names()).
+		WithPrecheckConfig(checkerWeights, precheckParams(prechecksConfig)).
+		WithCategoryVerdicts(categoryVerdicts), nil
+}
+
+// precheckParams returns the parameters of the enabled prechecks by type
+func precheckParams(prechecksConfig *config.PrechecksConfig) map[string]any {
+	params := make(map[string]any)
+	for _, precheck := range prechecksConfig.Enabled() {
+		if len(precheck.Params) > 0 {
+			params[precheck.Type] = precheck.Params
+		}
+	}
+	return params
+}
+
+// newEmbeddingClient creates the client of the configured embedding provider, or
+// nil if none is configured
+func newEmbeddingClient(ctx context.Context, cfg *Config) (embedding.Client, error) {
+	switch cfg.EmbeddingProvider {
+	case "":
+		return nil, nil
+	case "bedrock":
+		return bedrock.NewEmbeddingClient(ctx, cfg.AWSRegion, cfg.EmbeddingModelID, cfg.EmbeddingDimensions)
+	case "openai":
+		return gpt.NewEmbeddingClient(cfg.OpenAIKey, cfg.EmbeddingModelID, cfg.EmbeddingDimensions)
+	case "local":
+		return embedding.NewLocalClient(cfg.EmbeddingDimensions), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider: %s", cfg.EmbeddingProvider)
+	}
+}
+
 func getEnv(key string, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -116,13 +319,86 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 	return value
 }
 
-func createLLMClient(ctx context.Context, provider string, cfg *Config) (llm.LLMClient, error) {
-	switch provider {
-	case "bedrock":
-		return bedrock.NewClient(ctx, cfg.AWSRegion, cfg.ClaudeModelID)
-	case "openai":
-		return gpt.NewClient(cfg.OpenAIKey, cfg.OpenAIModelID)
-	default:
-		return bedrock.NewClient(ctx, cfg.AWSRegion, cfg.ClaudeModelID)
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		value = defaultValue
 	}
+
+	return value
+}
+
+// newLLMRegistry registers the supported providers. A model ID left empty in the
+// judges config selects the provider model from the environment. Each model gets
+// one client shared by all judges and workers, guarded by the provider's rate
+// limits and a circuit breaker. With a cassette, every client records and/or
+// replays its responses; in replay mode no provider client is created, so no
+// credentials are needed.
+func newLLMRegistry(ctx context.Context, cfg *Config, cassette *llm.Cassette, logger *zerolog.Logger) *llm.Registry {
+	defaultProvider := cfg.DefaultProvider
+	if defaultProvider != "openai" && defaultProvider != "local" {
+		defaultProvider = "bedrock"
+	}
+
+	registry := llm.NewRegistry(defaultProvider)
+
+	register := func(provider string, defaultModel string, create func(modelID string) (llm.LLMClient, error)) {
+		registry.RegisterProvider(provider, func(modelID string) (llm.LLMClient, error) {
+			if modelID == "" {
+				modelID = defaultModel
+			}
+			scope := provider + "/" + modelID
+			if cassette != nil && cfg.CassetteMode == llm.CassetteReplay {
+				return llm.NewCassetteClient(nil, cassette, cfg.CassetteMode, scope), nil
+			}
+
+			client, err := create(modelID)
+			if err != nil {
+				return nil, err
+			}
+			client = guard(client, scope, cfg.RateLimits[provider], cfg.CircuitBreaker, logger)
+
+			if cassette == nil {
+				return client, nil
+			}
+			return llm.NewCassetteClient(client, cassette, cfg.CassetteMode, scope), nil
+		})
+	}
+
+	register("bedrock", cfg.ClaudeModelID, func(modelID string) (llm.LLMClient, error) {
+		return bedrock.NewClient(ctx, cfg.AWSRegion, modelID)
+	})
+
+	register("openai", cfg.OpenAIModelID, func(modelID string) (llm.LLMClient, error) {
+		return gpt.NewClient(cfg.OpenAIKey, modelID)
+	})
+
+	register("local", cfg.LocalModelID, func(modelID string) (llm.LLMClient, error) {
+		return gpt.NewCompatibleClient(cfg.LocalBaseURL, cfg.LocalAPIKey, modelID, cfg.LocalJSONMode)
+	})
+
+	return registry
+}
+
+// guard wraps a model client with its rate limiter and circuit breaker. Cassette
+// hits are served before the guard, so they never count against the limits.
+func guard(client llm.LLMClient, name string, limits llm.RateLimits, breaker llm.CircuitBreakerSettings, logger *zerolog.Logger) llm.LLMClient {
+	var limiter *llm.RateLimiter
+	if limits.Enabled() {
+		limiter = llm.NewRateLimiter(limits)
+	}
+
+	var circuitBreaker *llm.CircuitBreaker
+	if breaker.FailureThreshold > 0 {
+		circuitBreaker = llm.NewCircuitBreaker(name, breaker, logger)
+	}
+
+	logger.Info().
+		Str("model", name).
+		Float64("requests_per_second", limits.RequestsPerSecond).
+		Int("tokens_per_minute", limits.TokensPerMinute).
+		Int("circuit_failure_threshold", breaker.FailureThreshold).
+		Msg("LLM client created")
+
+	return llm.NewGuardedClient(client, limiter, circuitBreaker, llm.DefaultRetryPolicy)
 }
@@ -0,0 +1,189 @@
+package setup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// TestWire_ReplayCassette runs the full pipeline hermetically: judge responses are
+// served from a cassette and no provider client (or credentials) is needed
+func TestWire_ReplayCassette(t *testing.T) {
+	logger := zerolog.Nop()
+	dir := t.TempDir()
+
+	configPath := filepath.Join(dir, "judges.yaml")
+	writeJudgesConfig(t, configPath, "Score: {{.Answer}}")
+	t.Setenv("JUDGES_CONFIG_PATH", configPath)
+
+	cassettePath := filepath.Join(dir, "judges.cassette.jsonl")
+	cassette, err := llm.OpenCassette(cassettePath)
+	if err != nil {
+		t.Fatalf("OpenCassette failed: %v", err)
+	}
+	recorder := llm.NewCassetteClient(&stubLLMClient{}, cassette, llm.CassetteRecord, "bedrock/test-model")
+	_, err = recorder.InvokeModelWithRetry(context.Background(), llm.LLMRequest{
+		Prompt:     "Score: Go is a programming language created at Google.",
+		MaxTokens:  256,
+		JSONOutput: true,
+		Schema:     judge.ResponseSchema,
+	})
+	if err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	cassette.Close()
+
+	cfg := &Config{
+		DefaultProvider:    "bedrock",
+		ClaudeModelID:      "test-model",
+		PrecheckWeight:     0.3,
+		LLMJudgeWeight:     0.7,
+		EarlyExitThreshold: 0.2,
+		CassettePath:       cassettePath,
+		CassetteMode:       llm.CassetteReplay,
+	}
+
+	deps, err := Wire(context.Background(), cfg, &logger)
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+
+	evalCtx := models.EvaluationContext{
+		RequestID: "replay-001",
+		Query:     "What is the Go programming language?",
+		Answer:    "Go is a programming language created at Google.",
+		CreatedAt: time.Now(),
+	}
+
+	result := deps.Executor.Execute(context.Background(), evalCtx)
+	judgeStage := findStage(result.Stages, "relevance-judge")
+	if judgeStage == nil || judgeStage.Score != 1.0 {
+		t.Fatalf("expected replayed judge score 1.0, got %+v", result.Stages)
+	}
+
+	// A request missing from the cassette fails instead of calling the provider
+	evalCtx.Answer = "Go is a statically typed language."
+	result = deps.Executor.Execute(context.Background(), evalCtx)
+	judgeStage = findStage(result.Stages, "relevance-judge")
+	if judgeStage == nil || judgeStage.Score != 0.0 || !strings.Contains(judgeStage.Reason, "Failed to call LLM") {
+		t.Errorf("expected cassette miss to fail the judge, got %+v", judgeStage)
+	}
+}
+
+func TestWire_InvalidCassetteMode(t *testing.T) {
+	logger := zerolog.Nop()
+	cfg := &Config{
+		CassettePath: filepath.Join(t.TempDir(), "cassette.jsonl"),
+		CassetteMode: "rewind",
+	}
+
+	if _, err := Wire(context.Background(), cfg, &logger); err == nil {
+		t.Error("expected error for invalid cassette mode")
+	}
+	if _, err := os.Stat(cfg.CassettePath); !os.IsNotExist(err) {
+		t.Error("expected cassette not to be created for an invalid mode")
+	}
+}
+
+func findStage(stages []models.StageResult, name string) *models.StageResult {
+	for i := range stages {
+		if stages[i].Name == name {
+			return &stages[i]
+		}
+	}
+	return nil
+}
+
+// staticJudgeRunner returns the same judge results for every evaluation
+type staticJudgeRunner struct {
+	results []models.StageResult
+}
+
+func (r *staticJudgeRunner) Run(ctx context.Context, evalCtx models.EvaluationContext) []models.StageResult {
+	return r.results
+}
+
+func TestNewExecutor_PrechecksConfig(t *testing.T) {
+	logger := zerolog.Nop()
+	cfg := &Config{PrecheckWeight: 0.3, LLMJudgeWeight: 0.7, EarlyExitThreshold: 0.2}
+
+	prechecksConfig := &config.PrechecksConfig{Prechecks: []config.PrecheckConfiguration{
+		{Type: "format", Enabled: true, Weight: 1},
+		{Type: "overlap", Enabled: false, Weight: 1},
+		{Type: "semantic-relevance", Enabled: true, Weight: 3, Params: map[string]any{"threshold": 0.2}},
+	}}
+
+	embedder, err := newEmbeddingClient(context.Background(), &Config{EmbeddingProvider: "local"})
+	if err != nil {
+		t.Fatalf("newEmbeddingClient failed: %v", err)
+	}
+
+	runner := &staticJudgeRunner{results: []models.StageResult{{Name: "relevance-judge", Score: 1.0}}}
+	exec, err := newExecutor(cfg, runner, prechecksConfig, embedder, &logger)
+	if err != nil {
+		t.Fatalf("newExecutor failed: %v", err)
+	}
+
+	result := exec.Execute(context.Background(), models.EvaluationContext{
+		Query:  "What is the capital of France?",
+		Answer: "The capital of France is Paris.",
+	})
+
+	if strings.Join(result.Pipeline.Prechecks, ",") != "format-checker,semantic-relevance" {
+		t.Errorf("Expected enabled prechecks only, got %v", result.Pipeline.Prechecks)
+	}
+	if result.Pipeline.PrecheckWeights["semantic-relevance"] != 3 {
+		t.Errorf("Expected precheck weights in pipeline, got %v", result.Pipeline.PrecheckWeights)
+	}
+	if findStage(result.Stages, "semantic-relevance") == nil {
+		t.Errorf("Expected semantic relevance stage, got %+v", result.Stages)
+	}
+}
+
+func TestNewExecutor_PrechecksErrors(t *testing.T) {
+	logger := zerolog.Nop()
+	runner := &staticJudgeRunner{}
+
+	tests := []struct {
+		name      string
+		prechecks []config.PrecheckConfiguration
+	}{
+		{"unknown type", []config.PrecheckConfiguration{{Type: "magic", Enabled: true}}},
+		{"missing embedding provider", []config.PrecheckConfiguration{{Type: "semantic-relevance", Enabled: true}}},
+		{"unknown param", []config.PrecheckConfiguration{{Type: "overlap", Enabled: true, Params: map[string]any{"min_overlp": 0.3}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newExecutor(&Config{}, runner, &config.PrechecksConfig{Prechecks: tt.prechecks}, nil, &logger)
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if _, err := newEmbeddingClient(context.Background(), &Config{EmbeddingProvider: "cohere"}); err == nil {
+		t.Error("expected error for unknown embedding provider")
+	}
+}
+
+func TestLoadPrechecks_MissingFile(t *testing.T) {
+	logger := zerolog.Nop()
+
+	prechecksConfig, err := loadPrechecks(filepath.Join(t.TempDir(), "prechecks.yaml"), &logger)
+	if err != nil {
+		t.Fatalf("loadPrechecks failed: %v", err)
+	}
+	if len(prechecksConfig.Enabled()) != 3 {
+		t.Errorf("Expected the 3 default prechecks, got %+v", prechecksConfig.Prechecks)
+	}
+}
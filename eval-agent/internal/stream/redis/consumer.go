@@ -104,8 +104,19 @@ func (c *Consumer) process(ctx context.Context, msg redis.XMessage) {
 		return
 	}
 
+	if err := evalRequest.Options.Validate(); err != nil {
+		c.logger.Error().Err(err).Str("id", msg.ID).Msg("Invalid evaluation options")
+		c.ack(ctx, msg.ID)
+		return
+	}
+
 	evalCtx := normalize(evalRequest)
-	result := c.executor.Execute(ctx, evalCtx)
+	result, err := c.executor.ExecuteWithOptions(ctx, evalCtx, evalRequest.Options)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", msg.ID).Msg("Evaluation failed")
+		c.ack(ctx, msg.ID)
+		return
+	}
 
 	c.logger.Info().
 		Str("id", msg.ID).
@@ -127,8 +138,10 @@ func normalize(req models.EvaluationRequest) models.EvaluationContext {
 	return models.EvaluationContext{
 		RequestID: req.EventID,
 		Query:     req.Interaction.UserQuery,
-		Context:   req.Interaction.Context,
+		Context:   models.ContextText(req.Interaction.Context, req.Interaction.Contexts),
+		Contexts:  req.Interaction.Contexts,
 		Answer:    req.Interaction.Answer,
+		Reference: req.Interaction.Reference,
 		CreatedAt: time.Now(),
 	}
 }